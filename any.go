@@ -0,0 +1,206 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/json-iterator/go"
+	"github.com/qri-io/jsonpointer"
+)
+
+// ValidateAny performs schema validation against a jsoniter.Any, as
+// obtained from jsoniter.Get, giving the same errors as ValidateBytes on
+// the equivalent json. Objects and arrays are walked through the Any for
+// as long as the schema only uses "type", "properties", "required" and a
+// single "items" schema on them, so properties the schema doesn't describe
+// are never decoded. Other values are decoded when validation reaches
+// them, along with everything they hold. Schemas using keywords that
+// this package doesn't define are validated against the fully decoded
+// value, as those keywords can look at the parent of a value
+func (rs *RootSchema) ValidateAny(any jsoniter.Any) ([]ValError, error) {
+	errs := []ValError{}
+	if any.ValueType() == jsoniter.InvalidValue {
+		return errs, fmt.Errorf("error reading value: %v", any.LastError())
+	}
+	vc := newValidationContext(nil)
+	if !standardKeywordsOnly(&rs.Schema, map[*Schema]bool{}) {
+		doc := any.GetInterface()
+		if err := any.LastError(); err != nil {
+			return errs, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
+		}
+		vc.Root = doc
+		rs.ValidateContext(vc, "/", doc, &errs)
+		return errs, nil
+	}
+	if err := validateAny(vc, &rs.Schema, "/", any, &errs); err != nil {
+		return errs, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
+	}
+	return errs, nil
+}
+
+// validateAny validates the value an Any holds against s, walking objects
+// and arrays through the Any where the schema allows it
+func validateAny(vc *ValidationContext, s *Schema, propPath string, any jsoniter.Any, errs *[]ValError) error {
+	if vc.expired() {
+		return nil
+	}
+	if !walksAny(s, any) {
+		data := any.GetInterface()
+		if err := any.LastError(); err != nil {
+			return err
+		}
+		s.ValidateContext(vc, propPath, data, errs)
+		return nil
+	}
+	if s.ID != "" {
+		defer vc.leaveSchema(vc.enterID(s.ID))
+	}
+	if s.Ref != "" {
+		start := len(*errs)
+		prev := vc.enterRef(s.Ref)
+		err := validateAny(vc, s.ref.(*Schema), propPath, any, errs)
+		vc.locateErrors(*errs, start)
+		vc.leaveSchema(prev)
+		return err
+	}
+
+	jp, err := jsonpointer.Parse(propPath)
+	if err != nil {
+		AddError(errs, propPath, nil, "invalid property path")
+		return nil
+	}
+	for key, v := range s.Validators {
+		start := len(*errs)
+		prev := vc.enterSchema(key)
+		switch v := v.(type) {
+		case *Properties:
+			err = validateAnyProperties(vc, *v, jp, any, errs)
+		case *Items:
+			if any.ValueType() != jsoniter.ArrayValue {
+				break
+			}
+			for i := 0; i < any.Size() && err == nil; i++ {
+				d, _ := jp.Descendant(strconv.Itoa(i))
+				err = validateAny(vc, v.Schemas[0], d.String(), any.Get(i), errs)
+			}
+		}
+		for i := start; i < len(*errs); i++ {
+			if (*errs)[i].Keyword == "" {
+				(*errs)[i].Keyword = key
+			}
+		}
+		vc.locateErrors(*errs, start)
+		vc.leaveSchema(prev)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAnyProperties validates the properties of the object an Any
+// holds against their schemas, reading only those properties
+func validateAnyProperties(vc *ValidationContext, p Properties, jp jsonpointer.Pointer, any jsoniter.Any, errs *[]ValError) error {
+	for name, sch := range p {
+		val := any.Get(name)
+		if sch == nil || val.ValueType() == jsoniter.InvalidValue {
+			continue
+		}
+		d, _ := jp.Descendant(name)
+		prev := vc.enterSchema(name)
+		err := validateAny(vc, sch, d.String(), val, errs)
+		vc.leaveSchema(prev)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walksAny reports whether validateAny can validate the value an Any holds
+// against s without decoding it: the value is an object or array of a type
+// s allows, s uses no keywords but the ones validateAny walks, and every
+// required property is present. Any failing keyword is left to the decoded
+// value, so errors hold the same values as those of ValidateBytes
+func walksAny(s *Schema, any jsoniter.Any) bool {
+	if s.Ref != "" {
+		target, ok := s.ref.(*Schema)
+		return ok && !s.refSiblings && walksAny(target, any)
+	}
+
+	var sample interface{}
+	switch any.ValueType() {
+	case jsoniter.ObjectValue:
+		sample = map[string]interface{}{}
+	case jsoniter.ArrayValue:
+		sample = []interface{}{}
+	default:
+		return false
+	}
+	for key, v := range s.Validators {
+		switch v := v.(type) {
+		case *Type:
+			if !v.allows(sample) {
+				return false
+			}
+		case *Properties:
+		case *Required:
+			if any.ValueType() != jsoniter.ObjectValue {
+				continue
+			}
+			for _, name := range *v {
+				if any.Get(name).ValueType() == jsoniter.InvalidValue {
+					return false
+				}
+			}
+		case *Items:
+			if !v.single {
+				return false
+			}
+		default:
+			return false
+		}
+		if !standardKeywords[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// standardKeywords are the keywords of DefaultValidators as this package
+// defines them. Validating without options, none look at the parent of
+// a value
+var standardKeywords = func() map[string]bool {
+	keys := map[string]bool{}
+	for key := range DefaultValidators {
+		keys[key] = true
+	}
+	return keys
+}()
+
+// standardKeywordsOnly reports whether s and every schema it holds or
+// references use only standard keywords
+func standardKeywordsOnly(s *Schema, seen map[*Schema]bool) bool {
+	if seen[s] {
+		return true
+	}
+	seen[s] = true
+	standard := true
+	walkJSON(s, func(elem JSONPather) error {
+		sch, ok := elem.(*Schema)
+		if !ok || !standard {
+			return nil
+		}
+		for key := range sch.Validators {
+			if !standardKeywords[key] {
+				standard = false
+			}
+		}
+		if sch.Ref != "" {
+			target, ok := sch.ref.(*Schema)
+			standard = ok && standardKeywordsOnly(target, seen)
+		}
+		return nil
+	})
+	return standard
+}
@@ -0,0 +1,241 @@
+package jsonschema
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/jsonpointer"
+)
+
+// RemoteRefs lists every reference in the schema that points outside of
+// the schema document, in sorted order. References resolved by an "$id"
+// declared within the document are not considered remote
+func (rs *RootSchema) RemoteRefs() []string {
+	ids := collectIDs(&rs.Schema)
+	found := map[string]bool{}
+	walkJSON(&rs.Schema, func(elem JSONPather) error {
+		if sch, ok := elem.(*Schema); ok {
+			if isExternalRef(sch.Ref, ids) {
+				found[sch.Ref] = true
+			}
+		}
+		return nil
+	})
+
+	refs := make([]string, 0, len(found))
+	for ref := range found {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// isExternalRef reports whether ref points to a different document
+func isExternalRef(ref string, ids map[string]*Schema) bool {
+	if ref == "" || ids[ref] != nil {
+		return false
+	}
+	doc, _ := splitRef(ref)
+	return doc != ""
+}
+
+// Bundle produces a single self-contained schema with no external
+// references. Every externally referenced schema is inlined as a "$defs"
+// entry and references to it are rewritten to point at that entry.
+// Subschemas referenced from within inlined schemas are inlined the same
+// way. External references must already be resolved, either by ParseFile
+// or FetchRemoteReferences.
+//
+// "$defs" entry names are taken from the last token of the reference
+// fragment, or the referenced document name when there is no fragment.
+// Names that collide with an existing definition get a numeric suffix,
+// assigned in sorted order of the schema keys they're encountered at
+func (rs *RootSchema) Bundle() (*RootSchema, error) {
+	b := &bundler{
+		ids:   collectIDs(&rs.Schema),
+		names: map[Validator]string{},
+		used:  map[string]bool{},
+		defs:  map[string]interface{}{},
+	}
+	for name := range rs.Defs {
+		b.used[name] = true
+	}
+
+	doc, err := toGeneric(rs.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.rewrite(doc, &rs.Schema, nil, false); err != nil {
+		return nil, err
+	}
+
+	for len(b.queue) > 0 {
+		target := b.queue[0]
+		b.queue = b.queue[1:]
+
+		def, err := toGeneric(target)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.rewrite(def, target, nil, true); err != nil {
+			return nil, err
+		}
+		b.defs[b.names[target]] = def
+	}
+
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		// boolean schemas can't contain references
+		return rs, nil
+	}
+	if len(b.defs) > 0 {
+		defs, _ := obj["$defs"].(map[string]interface{})
+		if defs == nil {
+			defs = map[string]interface{}{}
+		}
+		for name, def := range b.defs {
+			defs[name] = def
+		}
+		obj["$defs"] = defs
+	}
+	if rs.SchemaURI != "" {
+		obj["$schema"] = rs.SchemaURI
+	}
+
+	data, err := DefaultEncoder.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	bundled := &RootSchema{}
+	if err := DefaultDecoder.Unmarshal(data, bundled); err != nil {
+		return nil, fmt.Errorf("error parsing bundled schema: %s", err.Error())
+	}
+	return bundled, nil
+}
+
+type bundler struct {
+	ids   map[string]*Schema
+	names map[Validator]string
+	used  map[string]bool
+	defs  map[string]interface{}
+	queue []Validator
+}
+
+// rewrite walks the generic JSON form of the schema base, rewriting
+// references in place. Within the root document only external references
+// are rewritten. Inlined schemas are lifted out of their document, so all
+// their references are rewritten
+func (b *bundler) rewrite(node interface{}, base Validator, ptr jsonpointer.Pointer, inlined bool) error {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			if sch := schemaAt(base, ptr); sch != nil && sch.Ref == ref {
+				if inlined || isExternalRef(ref, b.ids) {
+					if sch.ref == nil {
+						return fmt.Errorf("unresolved reference: %s", ref)
+					}
+					v["$ref"] = "#" + jsonpointer.Pointer{"$defs", b.name(sch.ref, ref)}.String()
+				}
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := b.rewrite(v[key], base, append(ptr[:len(ptr):len(ptr)], key), inlined); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, elem := range v {
+			if err := b.rewrite(elem, base, append(ptr[:len(ptr):len(ptr)], strconv.Itoa(i)), inlined); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// name gives the "$defs" entry name for target, queuing it for inlining
+// the first time it's seen
+func (b *bundler) name(target Validator, ref string) string {
+	if name, ok := b.names[target]; ok {
+		return name
+	}
+
+	base := refDefName(ref)
+	name := base
+	for i := 2; b.used[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	b.used[name] = true
+	b.names[target] = name
+	b.queue = append(b.queue, target)
+	return name
+}
+
+// refDefName derives a definition name from a reference
+func refDefName(ref string) string {
+	doc, fragment := splitRef(ref)
+	if ptr, err := jsonpointer.Parse(fragment); err == nil && len(ptr) > 0 && ptr[len(ptr)-1] != "" {
+		return ptr[len(ptr)-1]
+	}
+	if name := strings.TrimSuffix(path.Base(doc), path.Ext(doc)); name != "" && name != "." && name != "/" {
+		return name
+	}
+	if doc != "" {
+		return doc
+	}
+	return "root"
+}
+
+// schemaAt evaluates ptr against base, returning the schema found there
+// or nil if ptr doesn't land on a schema
+func schemaAt(base Validator, ptr jsonpointer.Pointer) *Schema {
+	var res interface{} = base
+	for _, token := range ptr {
+		if it, ok := res.(*Items); ok && it.single {
+			res = it.Schemas[0]
+		}
+		adr, ok := res.(JSONPather)
+		if !ok {
+			return nil
+		}
+		res = adr.JSONProp(token)
+	}
+
+	switch v := res.(type) {
+	case *Schema:
+		return v
+	case *RootSchema:
+		return &v.Schema
+	case *Items:
+		if v.single {
+			return v.Schemas[0]
+		}
+	case *AdditionalItems:
+		return v.Schema
+	case *AdditionalProperties:
+		return v.Schema
+	case *If:
+		return &v.Schema
+	}
+	return nil
+}
+
+// toGeneric converts a value to its generic decoded JSON form
+func toGeneric(v interface{}) (interface{}, error) {
+	data, err := DefaultEncoder.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	err = DefaultDecoder.Unmarshal(data, &doc)
+	return doc, err
+}
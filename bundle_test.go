@@ -0,0 +1,85 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	rs, err := ParseFile("testdata/files/person.json")
+	if err != nil {
+		t.Fatalf("unexpected error parsing file: %s", err.Error())
+	}
+	if len(rs.RemoteRefs()) != 2 {
+		t.Errorf("expected 2 remote refs before bundling, got: %v", rs.RemoteRefs())
+	}
+
+	bundled, err := rs.Bundle()
+	if err != nil {
+		t.Fatalf("unexpected error bundling: %s", err.Error())
+	}
+	if refs := bundled.RemoteRefs(); len(refs) != 0 {
+		t.Errorf("expected bundled schema to have no remote refs, got: %v", refs)
+	}
+	for _, name := range []string{"name", "address"} {
+		if bundled.Defs[name] == nil {
+			t.Errorf("expected bundled schema to define $defs/%s", name)
+		}
+	}
+
+	inputs := []string{
+		`{"name": "Alice", "address": {"city": "Berlin"}}`,
+		`{"name": ""}`,
+		`{"name": "Alice", "address": {}}`,
+		`{"name": "Alice", "address": {"city": 5}}`,
+	}
+	for i, input := range inputs {
+		expect, err := rs.ValidateBytes([]byte(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := bundled.ValidateBytes([]byte(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(expect) != len(got) {
+			t.Errorf("case %d: error length mismatch. expected: %v, got: %v", i, expect, got)
+		}
+	}
+}
+
+func TestBundleNameCollisions(t *testing.T) {
+	rs, err := ParseFile("testdata/files/nicknames.json")
+	if err != nil {
+		t.Fatalf("unexpected error parsing file: %s", err.Error())
+	}
+
+	for i := 0; i < 5; i++ {
+		bundled, err := rs.Bundle()
+		if err != nil {
+			t.Fatalf("unexpected error bundling: %s", err.Error())
+		}
+
+		props := bundled.Validators["properties"].(*Properties)
+		if (*props)["first"].Ref != "#/$defs/name" {
+			t.Errorf("expected first to reference #/$defs/name, got: %s", (*props)["first"].Ref)
+		}
+		if (*props)["short"].Ref != "#/$defs/name_2" {
+			t.Errorf("expected short to reference #/$defs/name_2, got: %s", (*props)["short"].Ref)
+		}
+
+		errs, err := bundled.ValidateBytes([]byte(`{"first": "", "short": "toolong"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != 2 {
+			t.Errorf("expected 2 errors, got: %v", errs)
+		}
+	}
+}
+
+func TestBundleUnresolved(t *testing.T) {
+	rs := Must(`{ "$ref": "http://example.com/missing.json" }`)
+	if _, err := rs.Bundle(); err == nil {
+		t.Errorf("expected error bundling schema with unresolved references")
+	}
+}
@@ -0,0 +1,24 @@
+package jsonschema
+
+import (
+	"github.com/json-iterator/go"
+)
+
+// Decoder decodes JSON bytes into a go value. Custom decoders must call
+// the UnmarshalJSON method of values that define one, as encoding/json does
+type Decoder interface {
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Encoder encodes a go value as JSON bytes. Custom encoders must call the
+// MarshalJSON method of values that define one, as encoding/json does
+type Encoder interface {
+	Marshal(v interface{}) ([]byte, error)
+}
+
+// DefaultDecoder is used to decode schemas and the instances they
+// validate. Swap it out to control decoding, eg: to use encoding/json
+var DefaultDecoder Decoder = jsoniter.ConfigDefault
+
+// DefaultEncoder is used to encode schemas and values
+var DefaultEncoder Encoder = jsoniter.ConfigDefault
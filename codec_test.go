@@ -0,0 +1,50 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type countingCodec struct {
+	decoded, encoded int
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.decoded++
+	return json.Unmarshal(data, v)
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.encoded++
+	return json.Marshal(v)
+}
+
+func TestCustomCodec(t *testing.T) {
+	codec := &countingCodec{}
+	prevDec, prevEnc := DefaultDecoder, DefaultEncoder
+	DefaultDecoder, DefaultEncoder = codec, codec
+	defer func() {
+		DefaultDecoder, DefaultEncoder = prevDec, prevEnc
+	}()
+
+	rs := &RootSchema{}
+	if err := DefaultDecoder.Unmarshal([]byte(`{ "type": "object", "properties": { "a": { "minimum": 2 } } }`), rs); err != nil {
+		t.Fatal(err)
+	}
+	errs, err := rs.ValidateBytes([]byte(`{"a": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected 1 error, got: %v", errs)
+	}
+	if _, err := json.Marshal(rs); err != nil {
+		t.Errorf("error marshaling schema: %s", err)
+	}
+	if _, err := DefaultEncoder.Marshal(rs); err != nil {
+		t.Errorf("error encoding schema: %s", err)
+	}
+	if codec.decoded < 2 || codec.encoded < 1 {
+		t.Errorf("expected custom codec to be used. decoded: %d, encoded: %d", codec.decoded, codec.encoded)
+	}
+}
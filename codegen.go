@@ -0,0 +1,424 @@
+package jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// codegen tracks the named types emitted while generating code from a
+// schema. The root, each definition, and each nested object schema get a
+// type of their own, declared in the order they're first encountered
+type codegen struct {
+	root  *RootSchema
+	names map[*Schema]string
+	used  map[string]bool
+	queue []*Schema
+	// expanding holds the reference targets whose types are being worked
+	// out in place, to stop at cycles
+	expanding map[*Schema]bool
+	// enumValues holds the proto enum value names declared so far, which
+	// share the package scope
+	enumValues map[string]bool
+}
+
+func newCodegen(rs *RootSchema) *codegen {
+	g := &codegen{
+		root:       rs,
+		names:      map[*Schema]string{},
+		used:       map[string]bool{},
+		expanding:  map[*Schema]bool{},
+		enumValues: map[string]bool{},
+	}
+
+	g.register(&rs.Schema, TypeName(&rs.Schema, "Root"))
+	for _, defs := range []Definitions{rs.Definitions, rs.Defs} {
+		keys := make([]string, 0, len(defs))
+		for key := range defs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if defs[key] != nil {
+				g.register(defs[key], key)
+			}
+		}
+	}
+	return g
+}
+
+// register assigns sch a type name derived from name, queuing it for
+// declaration. Already-registered schemas keep their name
+func (g *codegen) register(sch *Schema, name string) string {
+	if n, ok := g.names[sch]; ok {
+		return n
+	}
+	base := typeName(name)
+	name = base
+	for i := 2; g.used[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	g.used[name] = true
+	g.names[sch] = name
+	g.queue = append(g.queue, sch)
+	return name
+}
+
+// refName gives the type name for the target of a reference, registering
+// targets that don't have one yet. ok is false for unresolved references
+func (g *codegen) refName(sch *Schema) (name string, ok bool) {
+	doc, fragment := splitRef(sch.Ref)
+	if doc == "" && (fragment == "" || fragment == "/") {
+		return g.names[&g.root.Schema], true
+	}
+	switch target := sch.ref.(type) {
+	case *Schema:
+		return g.register(target, refDefName(sch.Ref)), true
+	case *RootSchema:
+		return g.register(&target.Schema, refDefName(sch.Ref)), true
+	}
+	return "", false
+}
+
+// nullableTypes splits the declared types of sch into the non-null types
+// and whether "null" is allowed. Untyped schemas with an enum take their
+// type from the enum values when they're all the same type
+func nullableTypes(sch *Schema) (types []string, nullable bool) {
+	declared := sch.Types()
+	if len(declared) == 0 {
+		if vals := enumValues(sch); len(vals) > 0 {
+			seen := map[string]bool{}
+			for _, v := range vals {
+				t := DataType(v)
+				if t == "integer" {
+					t = "number"
+				}
+				if !seen[t] {
+					seen[t] = true
+					declared = append(declared, t)
+				}
+			}
+		} else if _, ok := sch.Validators["properties"]; ok {
+			declared = []string{"object"}
+		}
+	}
+
+	for _, t := range declared {
+		if t == "null" {
+			nullable = true
+			continue
+		}
+		types = append(types, t)
+	}
+	return types, nullable
+}
+
+// enumValues decodes the values of a schema's "enum" keyword
+func enumValues(sch *Schema) []interface{} {
+	enum, ok := sch.Validators["enum"].(*Enum)
+	if !ok {
+		return nil
+	}
+	vals := make([]interface{}, 0, len(*enum))
+	for _, c := range *enum {
+		var v interface{}
+		if err := DefaultDecoder.Unmarshal(c, &v); err == nil {
+			vals = append(vals, v)
+		}
+	}
+	return vals
+}
+
+// sortedProperties lists the property names of an object schema in sorted
+// order, along with the set of required property names
+func sortedProperties(sch *Schema) (props *Properties, keys []string, required map[string]bool) {
+	required = map[string]bool{}
+	if req, ok := sch.Validators["required"].(*Required); ok {
+		for _, key := range *req {
+			required[key] = true
+		}
+	}
+	props, ok := sch.Validators["properties"].(*Properties)
+	if !ok {
+		return &Properties{}, nil, required
+	}
+	for key := range *props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return props, keys, required
+}
+
+// initialisms are written in upper case within generated identifiers
+var initialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "id": true, "ip": true, "json": true,
+	"uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// TypeName gives the identifier generated code names the type of s by:
+// its title, or fallback when it has none, eg: a definition name. Names
+// are converted to exported identifiers valid in both go and TypeScript,
+// eg: "first name", "first-name", and "first_name" all become "FirstName",
+// and names starting with a digit are prefixed with "T". Distinct schemas
+// with the same name are told apart by numeric suffixes when generating
+// code, eg: "Item" and "Item2"
+func TypeName(s *Schema, fallback string) string {
+	if s != nil && s.Title != "" {
+		return typeName(s.Title)
+	}
+	return typeName(fallback)
+}
+
+// typeName converts a schema name, title, or property name into an
+// exported identifier, eg: "first_name" becomes "FirstName"
+func typeName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	buf := &strings.Builder{}
+	for _, w := range words {
+		if initialisms[strings.ToLower(w)] {
+			buf.WriteString(strings.ToUpper(w))
+			continue
+		}
+		rs := []rune(w)
+		rs[0] = unicode.ToUpper(rs[0])
+		buf.WriteString(string(rs))
+	}
+	str := buf.String()
+	if str == "" {
+		return "Type"
+	}
+	if unicode.IsDigit([]rune(str)[0]) {
+		return "T" + str
+	}
+	return str
+}
+
+// GenerateGoTypes generates go source declaring types for the schema,
+// returning gofmt-formatted source for package pkg. Object schemas become
+// structs with json tags, named after the schema title or definition name,
+// with nested object schemas named after the property that holds them.
+// Optional properties are pointers unless they're a slice, map, or
+// interface, as are required properties that would otherwise hold the
+// struct they're in. Properties whose names convert to the same field name
+// are told apart by numeric suffixes. "$ref" uses the type generated for
+// the referenced schema, and string enums declare a constant for each value
+func GenerateGoTypes(rs *RootSchema, pkg string) (string, error) {
+	g := newCodegen(rs)
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by jsonschema. DO NOT EDIT.\n\npackage %s\n", pkg)
+
+	for len(g.queue) > 0 {
+		sch := g.queue[0]
+		g.queue = g.queue[1:]
+		if err := g.goDecl(buf, sch); err != nil {
+			return "", err
+		}
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("error formatting generated code: %s", err.Error())
+	}
+	return string(src), nil
+}
+
+// goDecl writes the declaration of the named type for sch
+func (g *codegen) goDecl(buf *bytes.Buffer, sch *Schema) error {
+	name := g.names[sch]
+	buf.WriteString("\n")
+	writeComment(buf, "// ", sch.Description)
+
+	types, _ := nullableTypes(sch)
+	if sch.Ref == "" && len(types) == 1 && types[0] == "object" {
+		if _, ok := sch.Validators["properties"]; ok {
+			fmt.Fprintf(buf, "type %s struct {\n", name)
+			props, keys, required := sortedProperties(sch)
+			fields := fieldNames(keys)
+			for _, key := range keys {
+				prop := (*props)[key]
+				typ, err := g.goType(prop, name+fields[key])
+				if err != nil {
+					return err
+				}
+				tag := key
+				if !required[key] {
+					if !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "map[") && !strings.HasPrefix(typ, "*") && typ != "interface{}" {
+						typ = "*" + typ
+					}
+					tag += ",omitempty"
+				} else if g.containsStruct(g.valueStruct(prop), sch, map[*Schema]bool{}) {
+					// a struct can't hold itself by value
+					typ = "*" + typ
+				}
+				writeComment(buf, "\t// ", prop.Description)
+				fmt.Fprintf(buf, "\t%s %s `json:%q`\n", fields[key], typ, tag)
+			}
+			buf.WriteString("}\n")
+			return nil
+		}
+	}
+
+	typ, err := g.goTypeExpr(sch, name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(buf, "type %s %s\n", name, typ)
+
+	if typ == "string" {
+		vals := enumValues(sch)
+		if len(vals) == 0 {
+			return nil
+		}
+		buf.WriteString("\nconst (\n")
+		for _, v := range vals {
+			if str, ok := v.(string); ok {
+				fmt.Fprintf(buf, "\t%s%s %s = %q\n", name, typeName(str), name, str)
+			}
+		}
+		buf.WriteString(")\n")
+	}
+	return nil
+}
+
+// fieldNames gives the field name of each property of a generated struct
+// or message, telling apart properties with the same name by numeric
+// suffixes in sorted order, eg: "a-b" and "a_b" become "AB" and "AB2"
+func fieldNames(keys []string) map[string]string {
+	names := map[string]string{}
+	used := map[string]bool{}
+	for _, key := range keys {
+		base := typeName(key)
+		name := base
+		for i := 2; used[name]; i++ {
+			name = base + strconv.Itoa(i)
+		}
+		used[name] = true
+		names[key] = name
+	}
+	return names
+}
+
+// valueStruct gives the schema of the struct a required property of type
+// sch holds by value, or nil if it isn't a struct or is held by pointer
+func (g *codegen) valueStruct(sch *Schema) *Schema {
+	seen := map[*Schema]bool{}
+	for sch != nil && sch.Ref != "" && !seen[sch] {
+		seen[sch] = true
+		sch = g.refTarget(sch)
+	}
+	if sch == nil || sch.Ref != "" {
+		return nil
+	}
+	types, nullable := nullableTypes(sch)
+	if _, ok := sch.Validators["properties"]; !ok || nullable || len(types) != 1 || types[0] != "object" {
+		return nil
+	}
+	return sch
+}
+
+// containsStruct reports whether the struct generated for sch holds the
+// struct of target by value, directly or through its required fields
+func (g *codegen) containsStruct(sch, target *Schema, seen map[*Schema]bool) bool {
+	if sch == nil || seen[sch] {
+		return false
+	}
+	if sch == target {
+		return true
+	}
+	seen[sch] = true
+	props, keys, required := sortedProperties(sch)
+	for _, key := range keys {
+		if required[key] && g.containsStruct(g.valueStruct((*props)[key]), target, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// goType gives the go type used to hold instances of sch, with hint naming
+// nested object types
+func (g *codegen) goType(sch *Schema, hint string) (string, error) {
+	if name, ok := g.names[sch]; ok {
+		return name, nil
+	}
+	return g.goTypeExpr(sch, hint)
+}
+
+// goTypeExpr gives the go type expression for sch, without using a name
+// assigned to sch itself
+func (g *codegen) goTypeExpr(sch *Schema, hint string) (string, error) {
+	if sch == nil || sch.schemaType == schemaTypeTrue || sch.schemaType == schemaTypeFalse {
+		return "interface{}", nil
+	}
+	if sch.Ref != "" {
+		name, ok := g.refName(sch)
+		if !ok {
+			return "", fmt.Errorf("unresolved reference: %s", sch.Ref)
+		}
+		return name, nil
+	}
+
+	types, nullable := nullableTypes(sch)
+	if len(types) != 1 {
+		return "interface{}", nil
+	}
+
+	var typ string
+	switch types[0] {
+	case "string":
+		typ = "string"
+	case "integer":
+		typ = "int64"
+	case "number":
+		typ = "float64"
+	case "boolean":
+		typ = "bool"
+	case "array":
+		elem := "interface{}"
+		if it, ok := sch.Validators["items"].(*Items); ok && it.single {
+			t, err := g.goType(it.Schemas[0], hint+"Item")
+			if err != nil {
+				return "", err
+			}
+			elem = t
+		}
+		return "[]" + elem, nil
+	case "object":
+		if _, ok := sch.Validators["properties"]; ok {
+			typ = g.register(sch, TypeName(sch, hint))
+			break
+		}
+		elem := "interface{}"
+		if ap, ok := sch.Validators["additionalProperties"].(*AdditionalProperties); ok && ap.Schema.schemaType != schemaTypeTrue && ap.Schema.schemaType != schemaTypeFalse {
+			t, err := g.goType(ap.Schema, hint+"Value")
+			if err != nil {
+				return "", err
+			}
+			elem = t
+		}
+		return "map[string]" + elem, nil
+	default:
+		return "interface{}", nil
+	}
+
+	if nullable {
+		return "*" + typ, nil
+	}
+	return typ, nil
+}
+
+// writeComment writes text as a comment, one line at a time
+func writeComment(buf *bytes.Buffer, prefix, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		buf.WriteString(strings.TrimRight(prefix+line, " ") + "\n")
+	}
+}
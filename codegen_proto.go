@@ -0,0 +1,296 @@
+package jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// protoType is the type of a protobuf field
+type protoType struct {
+	name string
+	// repeated and mapped fields take no label, and can't be the elements
+	// of other repeated or mapped fields
+	repeated, mapped bool
+	// scalar is set for scalars and enums, which are marked optional when
+	// they aren't required. Message fields always track presence
+	scalar bool
+}
+
+// protoValue holds any json value
+var protoValue = protoType{name: "google.protobuf.Value"}
+
+// GenerateProto generates proto3 message definitions for the schema, which
+// must be an object schema with "properties". Object schemas become
+// messages, named the same way GenerateGoTypes names structs, with a field
+// for each property numbered in sorted order. Scalar and enum properties
+// that aren't required or are nullable are optional, message fields track
+// presence on their own. Arrays are repeated, objects with only
+// "additionalProperties" are maps, and string enums become enums.
+// Field names are the snake_case form of property names, with numeric
+// suffixes telling apart properties with the same form, and a json_name
+// when the proto JSON mapping wouldn't give the property name back. "$ref"
+// uses the message or enum generated for the referenced schema. Values
+// protobuf can't describe, like untyped properties, nested arrays, and
+// unions of types, use the well-known google.protobuf.Value and
+// google.protobuf.ListValue
+func GenerateProto(rs *RootSchema) (string, error) {
+	if !protoMessage(&rs.Schema) {
+		return "", fmt.Errorf(`root schema must be an object schema with "properties"`)
+	}
+	g := newCodegen(rs)
+	body := &bytes.Buffer{}
+	wellKnown := false
+
+	for len(g.queue) > 0 {
+		sch := g.queue[0]
+		g.queue = g.queue[1:]
+		uses, err := g.protoDecl(body, sch)
+		if err != nil {
+			return "", err
+		}
+		wellKnown = wellKnown || uses
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("// Code generated by jsonschema. DO NOT EDIT.\n\nsyntax = \"proto3\";\n")
+	if wellKnown {
+		buf.WriteString("\nimport \"google/protobuf/struct.proto\";\n")
+	}
+	buf.Write(body.Bytes())
+	return buf.String(), nil
+}
+
+// protoMessage reports whether sch is declared as a message
+func protoMessage(sch *Schema) bool {
+	types, _ := nullableTypes(sch)
+	_, ok := sch.Validators["properties"]
+	return ok && sch.Ref == "" && len(types) == 1 && types[0] == "object"
+}
+
+// protoEnum gives the values of sch when it's declared as an enum: a
+// schema that only allows strings, and possibly null
+func protoEnum(sch *Schema) ([]string, bool) {
+	types, _ := nullableTypes(sch)
+	if sch.Ref != "" || len(types) != 1 || types[0] != "string" {
+		return nil, false
+	}
+	vals := []string{}
+	for _, v := range enumValues(sch) {
+		if str, ok := v.(string); ok {
+			vals = append(vals, str)
+		} else if v != nil {
+			return nil, false
+		}
+	}
+	return vals, len(vals) > 0
+}
+
+// protoDecl writes the declaration of the named type for sch, reporting
+// whether it uses the well-known types. Named schemas that are neither
+// messages nor enums have no declaration, as protobuf has no type aliases.
+// Fields that would use them take their type directly
+func (g *codegen) protoDecl(buf *bytes.Buffer, sch *Schema) (wellKnown bool, err error) {
+	name := g.names[sch]
+	if vals, ok := protoEnum(sch); ok {
+		prefix := strings.ToUpper(snakeCase(name))
+		buf.WriteString("\n")
+		writeComment(buf, "// ", sch.Description)
+		fmt.Fprintf(buf, "enum %s {\n  %s = 0;\n", name, g.enumValue(prefix+"_UNSPECIFIED"))
+		for i, v := range vals {
+			fmt.Fprintf(buf, "  %s = %d;\n", g.enumValue(prefix+"_"+strings.ToUpper(snakeCase(typeName(v)))), i+1)
+		}
+		buf.WriteString("}\n")
+		return false, nil
+	}
+	if !protoMessage(sch) {
+		return false, nil
+	}
+
+	buf.WriteString("\n")
+	writeComment(buf, "// ", sch.Description)
+	fmt.Fprintf(buf, "message %s {\n", name)
+	props, keys, required := sortedProperties(sch)
+	fields, used := fieldNames(keys), map[string]bool{}
+	for i, key := range keys {
+		prop := (*props)[key]
+		typ, err := g.protoType(prop, name+fields[key])
+		if err != nil {
+			return false, err
+		}
+		wellKnown = wellKnown || strings.Contains(typ.name, "google.protobuf.")
+
+		_, nullable := nullableTypes(prop)
+		label := ""
+		if typ.repeated {
+			label = "repeated "
+		} else if typ.scalar && (!required[key] || nullable) {
+			label = "optional "
+		}
+		base := snakeCase(fields[key])
+		field := base
+		for n := 2; used[field]; n++ {
+			field = base + strconv.Itoa(n)
+		}
+		used[field] = true
+		option := ""
+		if protoJSONName(field) != key {
+			option = fmt.Sprintf(" [json_name = %q]", key)
+		}
+		writeComment(buf, "  // ", prop.Description)
+		fmt.Fprintf(buf, "  %s%s %s = %d%s;\n", label, typ.name, field, i+1, option)
+	}
+	buf.WriteString("}\n")
+	return wellKnown, nil
+}
+
+// protoType gives the type of fields holding instances of sch, with hint
+// naming nested messages
+func (g *codegen) protoType(sch *Schema, hint string) (protoType, error) {
+	if sch == nil || sch.schemaType == schemaTypeTrue || sch.schemaType == schemaTypeFalse {
+		return protoValue, nil
+	}
+	if sch.Ref != "" {
+		target := g.refTarget(sch)
+		if target == nil {
+			return protoType{}, fmt.Errorf("unresolved reference: %s", sch.Ref)
+		}
+		if _, ok := protoEnum(target); ok {
+			name, _ := g.refName(sch)
+			return protoType{name: name, scalar: true}, nil
+		}
+		if protoMessage(target) {
+			name, _ := g.refName(sch)
+			return protoType{name: name}, nil
+		}
+		if g.expanding[target] {
+			// a cycle of references with no message to name it
+			return protoValue, nil
+		}
+		g.expanding[target] = true
+		defer delete(g.expanding, target)
+		return g.protoType(target, hint)
+	}
+	if _, ok := protoEnum(sch); ok {
+		return protoType{name: g.register(sch, TypeName(sch, hint)), scalar: true}, nil
+	}
+
+	types, _ := nullableTypes(sch)
+	if len(types) != 1 {
+		return protoValue, nil
+	}
+	switch types[0] {
+	case "string":
+		return protoType{name: "string", scalar: true}, nil
+	case "integer":
+		return protoType{name: "int64", scalar: true}, nil
+	case "number":
+		return protoType{name: "double", scalar: true}, nil
+	case "boolean":
+		return protoType{name: "bool", scalar: true}, nil
+	case "array":
+		elem := protoValue
+		if it, ok := sch.Validators["items"].(*Items); ok && it.single {
+			t, err := g.protoType(it.Schemas[0], hint+"Item")
+			if err != nil {
+				return protoType{}, err
+			}
+			elem = t
+		}
+		elem = elem.element()
+		return protoType{name: elem.name, repeated: true}, nil
+	case "object":
+		if protoMessage(sch) {
+			return protoType{name: g.register(sch, TypeName(sch, hint))}, nil
+		}
+		ap, ok := sch.Validators["additionalProperties"].(*AdditionalProperties)
+		if !ok || ap.Schema.schemaType == schemaTypeTrue || ap.Schema.schemaType == schemaTypeFalse {
+			return protoType{name: "google.protobuf.Struct"}, nil
+		}
+		elem, err := g.protoType(ap.Schema, hint+"Value")
+		if err != nil {
+			return protoType{}, err
+		}
+		elem = elem.element()
+		return protoType{name: fmt.Sprintf("map<string, %s>", elem.name), mapped: true}, nil
+	}
+	return protoValue, nil
+}
+
+// enumValue gives a proto enum value name based on name that isn't yet
+// declared, telling apart values with the same name by numeric suffixes,
+// eg: "ROLE_READ_ONLY" and "ROLE_READ_ONLY2"
+func (g *codegen) enumValue(name string) string {
+	base := name
+	for i := 2; g.enumValues[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	g.enumValues[name] = true
+	return name
+}
+
+// element gives the type t is held as within a repeated or mapped field,
+// which can't hold other repeated or mapped fields directly
+func (t protoType) element() protoType {
+	if t.repeated {
+		return protoType{name: "google.protobuf.ListValue"}
+	}
+	if t.mapped {
+		return protoType{name: "google.protobuf.Struct"}
+	}
+	return t
+}
+
+// refTarget gives the schema a resolved reference points to
+func (g *codegen) refTarget(sch *Schema) *Schema {
+	doc, fragment := splitRef(sch.Ref)
+	if doc == "" && (fragment == "" || fragment == "/") {
+		return &g.root.Schema
+	}
+	switch target := sch.ref.(type) {
+	case *Schema:
+		return target
+	case *RootSchema:
+		return &target.Schema
+	}
+	return nil
+}
+
+// snakeCase converts an identifier to snake_case, keeping initialisms
+// together, eg: "UserID" becomes "user_id"
+func snakeCase(name string) string {
+	rs := []rune(name)
+	buf := &strings.Builder{}
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				buf.WriteRune('_')
+			}
+		}
+		buf.WriteRune(unicode.ToLower(r))
+	}
+	return buf.String()
+}
+
+// protoJSONName gives the JSON name protobuf derives from a field name,
+// eg: "first_name" becomes "firstName"
+func protoJSONName(field string) string {
+	buf := &strings.Builder{}
+	upper := false
+	for _, r := range field {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
@@ -0,0 +1,416 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+var codegenPersonSchema = `{
+    "title": "Person",
+    "type": "object",
+    "description": "A person",
+    "properties": {
+        "firstName": { "type": "string" },
+        "lastName": { "type": "string" },
+        "age": { "description": "Age in years", "type": "integer", "minimum": 0 },
+        "friends": { "type" : "array", "items" : { "title" : "REFERENCE", "$ref" : "#" } },
+        "address": { "type": "object", "properties": { "city": { "type": "string" }, "zip": { "type": ["string", "null"] } }, "required": ["city"] },
+        "role": { "$ref": "#/definitions/role" },
+        "tags": { "type": "object", "additionalProperties": { "type": "number" } },
+        "extra": {}
+    },
+    "required": ["firstName", "lastName"],
+    "definitions": {
+        "role": { "type": "string", "enum": ["admin", "read-only"] }
+    }
+}`
+
+var codegenNamesSchema = `{
+    "title": "Names",
+    "type": "object",
+    "properties": {
+        "a-b": { "type": "string" },
+        "a_b": { "type": "object", "properties": { "c": { "type": "string" } } },
+        "aB": { "type": "object", "properties": { "d": { "type": "string" } } }
+    },
+    "required": ["a-b"]
+}`
+
+func TestGenerateGoTypes(t *testing.T) {
+	expect := `// Code generated by jsonschema. DO NOT EDIT.
+
+package models
+
+// A person
+type Person struct {
+	Address *PersonAddress ` + "`" + `json:"address,omitempty"` + "`" + `
+	// Age in years
+	Age       *int64             ` + "`" + `json:"age,omitempty"` + "`" + `
+	Extra     interface{}        ` + "`" + `json:"extra,omitempty"` + "`" + `
+	FirstName string             ` + "`" + `json:"firstName"` + "`" + `
+	Friends   []Person           ` + "`" + `json:"friends,omitempty"` + "`" + `
+	LastName  string             ` + "`" + `json:"lastName"` + "`" + `
+	Role      *Role              ` + "`" + `json:"role,omitempty"` + "`" + `
+	Tags      map[string]float64 ` + "`" + `json:"tags,omitempty"` + "`" + `
+}
+
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleReadOnly Role = "read-only"
+)
+
+type PersonAddress struct {
+	City string  ` + "`" + `json:"city"` + "`" + `
+	Zip  *string ` + "`" + `json:"zip,omitempty"` + "`" + `
+}
+`
+
+	got, err := GenerateGoTypes(Must(codegenPersonSchema), "models")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != expect {
+		t.Errorf("generated code mismatch. expected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	if _, err := GenerateGoTypes(Must(`{ "properties": { "a": { "$ref": "other.json" } } }`), "models"); err == nil {
+		t.Errorf("expected error generating code for an unresolved reference")
+	}
+}
+
+func TestGenerateGoTypesRecursive(t *testing.T) {
+	cases := []struct {
+		schema, expect string
+	}{
+		{`{
+			"title": "Node",
+			"type": "object",
+			"properties": { "next": { "$ref": "#" }, "value": { "type": "integer" } },
+			"required": ["next", "value"]
+		}`, `// Code generated by jsonschema. DO NOT EDIT.
+
+package models
+
+type Node struct {
+	Next  *Node ` + "`" + `json:"next"` + "`" + `
+	Value int64 ` + "`" + `json:"value"` + "`" + `
+}
+`},
+		{`{
+			"title": "Pair",
+			"type": "object",
+			"properties": { "left": { "$ref": "#/definitions/left" } },
+			"required": ["left"],
+			"definitions": {
+				"left": {
+					"type": "object",
+					"properties": { "pair": { "$ref": "#" }, "tags": { "type": "array", "items": { "$ref": "#" } } },
+					"required": ["pair", "tags"]
+				}
+			}
+		}`, `// Code generated by jsonschema. DO NOT EDIT.
+
+package models
+
+type Pair struct {
+	Left *Left ` + "`" + `json:"left"` + "`" + `
+}
+
+type Left struct {
+	Pair *Pair  ` + "`" + `json:"pair"` + "`" + `
+	Tags []Pair ` + "`" + `json:"tags"` + "`" + `
+}
+`},
+	}
+	for i, c := range cases {
+		got, err := GenerateGoTypes(Must(c.schema), "models")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.expect {
+			t.Errorf("case %d: generated code mismatch. expected:\n%s\ngot:\n%s", i, c.expect, got)
+		}
+	}
+}
+
+func TestGenerateGoTypesFieldNames(t *testing.T) {
+	expect := `// Code generated by jsonschema. DO NOT EDIT.
+
+package models
+
+type Names struct {
+	AB  string    ` + "`" + `json:"a-b"` + "`" + `
+	AB2 *NamesAB2 ` + "`" + `json:"aB,omitempty"` + "`" + `
+	AB3 *NamesAB3 ` + "`" + `json:"a_b,omitempty"` + "`" + `
+}
+
+type NamesAB2 struct {
+	D *string ` + "`" + `json:"d,omitempty"` + "`" + `
+}
+
+type NamesAB3 struct {
+	C *string ` + "`" + `json:"c,omitempty"` + "`" + `
+}
+`
+
+	got, err := GenerateGoTypes(Must(codegenNamesSchema), "models")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != expect {
+		t.Errorf("generated code mismatch. expected:\n%s\ngot:\n%s", expect, got)
+	}
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	expect := `// Code generated by jsonschema. DO NOT EDIT.
+
+/** A person */
+export interface Person {
+  address?: PersonAddress;
+  /** Age in years */
+  age?: number;
+  extra?: unknown;
+  firstName: string;
+  friends?: Person[];
+  lastName: string;
+  role?: Role;
+  tags?: { [key: string]: number };
+}
+
+export type Role = "admin" | "read-only";
+
+export interface PersonAddress {
+  city: string;
+  zip?: string | null;
+}
+`
+
+	got, err := GenerateTypeScript(Must(codegenPersonSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != expect {
+		t.Errorf("generated code mismatch. expected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	got, err = GenerateTypeScript(Must(`{ "title": "Flags", "type": "object", "properties": { "x-mode": { "enum": [1, "two", null] }, "list": { "type": "array", "items": { "type": ["string", "integer"] } } } }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = `// Code generated by jsonschema. DO NOT EDIT.
+
+export interface Flags {
+  list?: (string | number)[];
+  "x-mode"?: 1 | "two" | null;
+}
+`
+	if got != expect {
+		t.Errorf("generated code mismatch. expected:\n%s\ngot:\n%s", expect, got)
+	}
+}
+
+func TestTypeName(t *testing.T) {
+	cases := []struct {
+		schema, fallback, expect string
+	}{
+		{`{"title": "shipping address"}`, "", "ShippingAddress"},
+		{`{"title": "line-item"}`, "", "LineItem"},
+		{`{"title": "3d model"}`, "", "T3dModel"},
+		{`{"title": "user id"}`, "", "UserID"},
+		{`{"title": "  "}`, "", "Type"},
+		{`{}`, "order_status", "OrderStatus"},
+		{`{}`, "2fa-settings", "T2faSettings"},
+	}
+	for i, c := range cases {
+		if got := TypeName(&Must(c.schema).Schema, c.fallback); got != c.expect {
+			t.Errorf("case %d: expected %q, got %q", i, c.expect, got)
+		}
+	}
+
+	rs := Must(`{
+		"title": "line item",
+		"type": "object",
+		"properties": {
+			"a": { "title": "line-item", "type": "object", "properties": { "x": { "type": "string" } } },
+			"b": { "title": "Line Item", "type": "object", "properties": { "y": { "type": "string" } } }
+		}
+	}`)
+	ts, err := GenerateTypeScript(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, decl := range []string{"export interface LineItem {", "export interface LineItem2 {", "export interface LineItem3 {", "a?: LineItem2;", "b?: LineItem3;"} {
+		if !strings.Contains(ts, decl) {
+			t.Errorf("expected TypeScript to contain %q, got:\n%s", decl, ts)
+		}
+	}
+	if _, err := GenerateGoTypes(rs, "models"); err != nil {
+		t.Errorf("expected colliding names to generate valid go, got: %s", err.Error())
+	}
+}
+
+func TestGenerateProto(t *testing.T) {
+	expect := `// Code generated by jsonschema. DO NOT EDIT.
+
+syntax = "proto3";
+
+import "google/protobuf/struct.proto";
+
+// A person
+message Person {
+  PersonAddress address = 1;
+  // Age in years
+  optional int64 age = 2;
+  google.protobuf.Value extra = 3;
+  string first_name = 4;
+  repeated Person friends = 5;
+  string last_name = 6;
+  optional Role role = 7;
+  map<string, double> tags = 8;
+}
+
+enum Role {
+  ROLE_UNSPECIFIED = 0;
+  ROLE_ADMIN = 1;
+  ROLE_READ_ONLY = 2;
+}
+
+message PersonAddress {
+  string city = 1;
+  optional string zip = 2;
+}
+`
+
+	got, err := GenerateProto(Must(codegenPersonSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != expect {
+		t.Errorf("generated code mismatch. expected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	got, err = GenerateProto(Must(`{
+		"title": "Event",
+		"type": "object",
+		"properties": {
+			"x-trace-id": { "type": "string" },
+			"grid": { "type": "array", "items": { "type": "array", "items": { "type": "number" } } },
+			"at": { "$ref": "#/definitions/timestamp" }
+		},
+		"required": ["at"],
+		"definitions": { "timestamp": { "type": "string", "format": "date-time" } }
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = `// Code generated by jsonschema. DO NOT EDIT.
+
+syntax = "proto3";
+
+import "google/protobuf/struct.proto";
+
+message Event {
+  string at = 1;
+  repeated google.protobuf.ListValue grid = 2;
+  optional string x_trace_id = 3 [json_name = "x-trace-id"];
+}
+`
+	if got != expect {
+		t.Errorf("generated code mismatch. expected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	got, err = GenerateProto(Must(codegenNamesSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = `// Code generated by jsonschema. DO NOT EDIT.
+
+syntax = "proto3";
+
+message Names {
+  string ab = 1 [json_name = "a-b"];
+  NamesAB2 ab2 = 2 [json_name = "aB"];
+  NamesAB3 ab3 = 3 [json_name = "a_b"];
+}
+
+message NamesAB2 {
+  optional string d = 1;
+}
+
+message NamesAB3 {
+  optional string c = 1;
+}
+`
+	if got != expect {
+		t.Errorf("generated code mismatch. expected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	got, err = GenerateProto(Must(`{
+		"title": "Forest",
+		"type": "object",
+		"properties": { "trees": { "$ref": "#/definitions/tree" } },
+		"definitions": { "tree": { "type": "array", "items": { "$ref": "#/definitions/tree" } } }
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = `// Code generated by jsonschema. DO NOT EDIT.
+
+syntax = "proto3";
+
+import "google/protobuf/struct.proto";
+
+message Forest {
+  repeated google.protobuf.Value trees = 1;
+}
+`
+	if got != expect {
+		t.Errorf("generated code mismatch. expected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	got, err = GenerateProto(Must(`{
+		"title": "R",
+		"type": "object",
+		"properties": {
+			"r": { "enum": ["read-only", "read_only"] },
+			"rRead": { "enum": ["only", "unspecified"] }
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = `// Code generated by jsonschema. DO NOT EDIT.
+
+syntax = "proto3";
+
+message R {
+  optional RR r = 1;
+  optional RRRead r_read = 2;
+}
+
+enum RR {
+  RR_UNSPECIFIED = 0;
+  RR_READ_ONLY = 1;
+  RR_READ_ONLY2 = 2;
+}
+
+enum RRRead {
+  RR_READ_UNSPECIFIED = 0;
+  RR_READ_ONLY3 = 1;
+  RR_READ_UNSPECIFIED2 = 2;
+}
+`
+	if got != expect {
+		t.Errorf("generated code mismatch. expected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	if _, err := GenerateProto(Must(`{ "type": "string" }`)); err == nil {
+		t.Errorf("expected error generating a message for a non-object schema")
+	}
+	if _, err := GenerateProto(Must(`{ "type": "object", "properties": { "a": { "$ref": "other.json" } } }`)); err == nil {
+		t.Errorf("expected error generating code for an unresolved reference")
+	}
+}
@@ -0,0 +1,175 @@
+package jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// tsIdentifier matches property names that don't need quoting in
+// TypeScript
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// GenerateTypeScript generates TypeScript declarations for the schema.
+// Object schemas become interfaces, named the same way GenerateGoTypes
+// names structs, with properties that aren't required marked optional.
+// Enums become unions of their values, arrays become T[], and "$ref" uses
+// the declaration generated for the referenced schema
+func GenerateTypeScript(rs *RootSchema) (string, error) {
+	g := newCodegen(rs)
+	buf := &bytes.Buffer{}
+	buf.WriteString("// Code generated by jsonschema. DO NOT EDIT.\n")
+
+	for len(g.queue) > 0 {
+		sch := g.queue[0]
+		g.queue = g.queue[1:]
+		if err := g.tsDecl(buf, sch); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
+
+// tsDecl writes the declaration of the named type for sch
+func (g *codegen) tsDecl(buf *bytes.Buffer, sch *Schema) error {
+	name := g.names[sch]
+	buf.WriteString("\n")
+	writeTSDoc(buf, "", sch.Description)
+
+	// a nullable object is declared as an interface, references to it add
+	// the null
+	types, _ := nullableTypes(sch)
+	if sch.Ref == "" && len(types) == 1 && types[0] == "object" && enumValues(sch) == nil {
+		if _, ok := sch.Validators["properties"]; ok {
+			fmt.Fprintf(buf, "export interface %s {\n", name)
+			props, keys, required := sortedProperties(sch)
+			for _, key := range keys {
+				prop := (*props)[key]
+				typ, err := g.tsType(prop, name+typeName(key))
+				if err != nil {
+					return err
+				}
+				field := key
+				if !tsIdentifier.MatchString(key) {
+					field = fmt.Sprintf("%q", key)
+				}
+				if !required[key] {
+					field += "?"
+				}
+				writeTSDoc(buf, "  ", prop.Description)
+				fmt.Fprintf(buf, "  %s: %s;\n", field, typ)
+			}
+			buf.WriteString("}\n")
+			return nil
+		}
+	}
+
+	typ, err := g.tsTypeExpr(sch, name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(buf, "export type %s = %s;\n", name, typ)
+	return nil
+}
+
+// tsType gives the TypeScript type of instances of sch, with hint naming
+// nested object types
+func (g *codegen) tsType(sch *Schema, hint string) (string, error) {
+	if name, ok := g.names[sch]; ok {
+		return name, nil
+	}
+	return g.tsTypeExpr(sch, hint)
+}
+
+// tsTypeExpr gives the TypeScript type expression for sch, without using
+// a name assigned to sch itself
+func (g *codegen) tsTypeExpr(sch *Schema, hint string) (string, error) {
+	if sch == nil || sch.schemaType == schemaTypeTrue {
+		return "unknown", nil
+	}
+	if sch.schemaType == schemaTypeFalse {
+		return "never", nil
+	}
+	if sch.Ref != "" {
+		name, ok := g.refName(sch)
+		if !ok {
+			return "", fmt.Errorf("unresolved reference: %s", sch.Ref)
+		}
+		return name, nil
+	}
+
+	if vals := enumValues(sch); vals != nil {
+		literals := make([]string, len(vals))
+		for i, v := range vals {
+			data, err := DefaultEncoder.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			literals[i] = string(data)
+		}
+		return strings.Join(literals, " | "), nil
+	}
+
+	types, nullable := nullableTypes(sch)
+	if len(types) == 0 && !nullable {
+		return "unknown", nil
+	}
+
+	union := []string{}
+	for _, t := range types {
+		switch t {
+		case "string", "boolean":
+			union = append(union, t)
+		case "integer", "number":
+			union = append(union, "number")
+		case "array":
+			elem := "unknown"
+			if it, ok := sch.Validators["items"].(*Items); ok && it.single {
+				e, err := g.tsType(it.Schemas[0], hint+"Item")
+				if err != nil {
+					return "", err
+				}
+				elem = e
+			}
+			if strings.Contains(elem, " ") {
+				elem = "(" + elem + ")"
+			}
+			union = append(union, elem+"[]")
+		case "object":
+			if _, ok := sch.Validators["properties"]; ok {
+				union = append(union, g.register(sch, TypeName(sch, hint)))
+				continue
+			}
+			elem := "unknown"
+			if ap, ok := sch.Validators["additionalProperties"].(*AdditionalProperties); ok && ap.Schema.schemaType != schemaTypeTrue && ap.Schema.schemaType != schemaTypeFalse {
+				e, err := g.tsType(ap.Schema, hint+"Value")
+				if err != nil {
+					return "", err
+				}
+				elem = e
+			}
+			union = append(union, fmt.Sprintf("{ [key: string]: %s }", elem))
+		}
+	}
+	if nullable {
+		union = append(union, "null")
+	}
+	return strings.Join(union, " | "), nil
+}
+
+// writeTSDoc writes text as a doc comment
+func writeTSDoc(buf *bytes.Buffer, indent, text string) {
+	if text = strings.TrimSpace(text); text == "" {
+		return
+	}
+	if !strings.Contains(text, "\n") {
+		fmt.Fprintf(buf, "%s/** %s */\n", indent, text)
+		return
+	}
+	buf.WriteString(indent + "/**\n")
+	for _, line := range strings.Split(text, "\n") {
+		buf.WriteString(strings.TrimRight(indent+" * "+line, " ") + "\n")
+	}
+	buf.WriteString(indent + " */\n")
+}
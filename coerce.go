@@ -0,0 +1,110 @@
+package jsonschema
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/qri-io/jsonpointer"
+)
+
+// jsonNumberPattern matches strings that are valid JSON numbers
+var jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// coerce converts string values in data to the integer, number, or boolean
+// type declared by the schema that applies to them, modifying data in
+// place. Strings are only converted when they're exactly the JSON text of
+// the declared type, so "4.5" remains a string when an integer is
+// expected. When "string" is also an allowed type, strings are still
+// converted to an allowed numeric type, so numeric keywords like "minimum"
+// apply to them, but not to booleans. the possibly-replaced value is
+// returned
+func coerce(sch *Schema, data interface{}) interface{} {
+	schemas := expandSchema(sch, nil, map[*Schema]bool{})
+
+	switch v := data.(type) {
+	case string:
+		for _, s := range schemas {
+			if t, ok := s.Validators["type"].(*Type); ok {
+				return coerceString(v, t.vals)
+			}
+		}
+	case map[string]interface{}:
+		for key, val := range v {
+			for _, s := range schemas {
+				if props, ok := s.Validators["properties"].(*Properties); ok && (*props)[key] != nil {
+					v[key] = coerce((*props)[key], val)
+					val = v[key]
+				}
+			}
+		}
+	case []interface{}:
+		for _, s := range schemas {
+			it, ok := s.Validators["items"].(*Items)
+			if !ok {
+				continue
+			}
+			for i, elem := range v {
+				if it.single {
+					v[i] = coerce(it.Schemas[0], elem)
+				} else if i < len(it.Schemas) {
+					v[i] = coerce(it.Schemas[i], elem)
+				}
+			}
+		}
+	}
+	return data
+}
+
+// coerceString converts str to the first of types it cleanly parses as.
+// Allowing "string" only permits numeric conversions
+func coerceString(str string, types []string) interface{} {
+	allowsString := false
+	for _, t := range types {
+		allowsString = allowsString || t == "string"
+	}
+
+	for _, t := range types {
+		switch t {
+		case "integer", "number":
+			if !jsonNumberPattern.MatchString(str) {
+				continue
+			}
+			num, err := strconv.ParseFloat(str, 64)
+			if err != nil {
+				continue
+			}
+			if t == "integer" && DataType(num) != "integer" {
+				continue
+			}
+			return num
+		case "boolean":
+			if !allowsString && (str == "true" || str == "false") {
+				return str == "true"
+			}
+		}
+	}
+	return str
+}
+
+// preTransform replaces each scalar within data with the result of calling
+// fn with its path and value, modifying data in place. the
+// possibly-replaced value is returned
+func preTransform(path string, data interface{}, fn func(path string, value interface{}) interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		jp, _ := jsonpointer.Parse(path)
+		for key, val := range v {
+			d, _ := jp.Descendant(key)
+			v[key] = preTransform(d.String(), val, fn)
+		}
+		return v
+	case []interface{}:
+		jp, _ := jsonpointer.Parse(path)
+		for i, elem := range v {
+			d, _ := jp.Descendant(strconv.Itoa(i))
+			v[i] = preTransform(d.String(), elem, fn)
+		}
+		return v
+	}
+	return fn(path, data)
+}
@@ -0,0 +1,157 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCoerce(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"age": { "type": "integer", "minimum": 18 },
+			"score": { "type": "number" },
+			"active": { "type": "boolean" },
+			"name": { "type": "string" },
+			"tags": { "type": "array", "items": { "type": "integer" } }
+		}
+	}`)
+
+	cases := []struct {
+		input  string
+		errors []string
+	}{
+		{`{"age": "42", "score": "4.5", "active": "true", "name": "42"}`, nil},
+		{`{"age": "4.5"}`, []string{`/age: "4.5" type should be integer`}},
+		{`{"age": "12"}`, []string{`/age: 12 must be >= 18`}},
+		{`{"age": " 42"}`, []string{`/age: " 42" type should be integer`}},
+		{`{"score": "1e3", "active": "false"}`, nil},
+		{`{"active": "yes"}`, []string{`/active: "yes" type should be boolean`}},
+		{`{"tags": ["1", "2", "x"]}`, []string{`/tags/2: "x" type should be integer`}},
+	}
+
+	for i, c := range cases {
+		errors, err := rs.ValidateBytesWithOptions([]byte(c.input), ValidateOptions{Coerce: true})
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err.Error())
+			continue
+		}
+
+		if len(errors) != len(c.errors) {
+			t.Errorf("case %d: error length mismatch. expected: %d, got: %d", i, len(c.errors), len(errors))
+			t.Errorf("%v", errors)
+			continue
+		}
+
+		for j, e := range errors {
+			if e.Error() != c.errors[j] {
+				t.Errorf("case %d: validation error %d mismatch. expected: %s, got: %s", i, j, c.errors[j], e.Error())
+			}
+		}
+	}
+
+	errs, err := rs.ValidateBytes([]byte(`{"age": "42"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected strings not to be coerced without the Coerce option, got: %v", errs)
+	}
+}
+
+func TestCoerceStringUnion(t *testing.T) {
+	cases := []struct {
+		schema, input string
+		errors        []string
+	}{
+		{`{ "type": ["integer"], "minimum": 40 }`, `"42"`, nil},
+		{`{ "type": ["integer"], "minimum": 40 }`, `"12"`, []string{`/: 12 must be >= 40`}},
+		{`{ "type": ["integer", "string"], "minimum": 40 }`, `"42"`, nil},
+		{`{ "type": ["integer", "string"], "minimum": 40 }`, `"12"`, []string{`/: 12 must be >= 40`}},
+		{`{ "type": ["string", "integer"], "minimum": 40 }`, `"12"`, []string{`/: 12 must be >= 40`}},
+		{`{ "type": ["integer", "string"], "minimum": 40 }`, `"4.5"`, nil},
+		{`{ "type": ["number", "string"], "maximum": 4 }`, `"4.5"`, []string{`/: 4.5 must be <= 4`}},
+		{`{ "type": ["integer", "string"], "minimum": 40 }`, `"abc"`, nil},
+		{`{ "type": ["boolean", "string"], "const": "true" }`, `"true"`, nil},
+	}
+
+	for i, c := range cases {
+		errs, err := Must(c.schema).ValidateBytesWithOptions([]byte(c.input), ValidateOptions{Coerce: true})
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err.Error())
+			continue
+		}
+		if len(errs) != len(c.errors) {
+			t.Errorf("case %d: error length mismatch. expected: %v, got: %v", i, c.errors, errs)
+			continue
+		}
+		for j, e := range errs {
+			if e.Error() != c.errors[j] {
+				t.Errorf("case %d: validation error %d mismatch. expected: %s, got: %s", i, j, c.errors[j], e.Error())
+			}
+		}
+	}
+}
+
+func TestPreTransform(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"role": { "enum": ["admin", "user"] },
+			"age": { "type": "integer", "minimum": 18 },
+			"tags": { "type": "array", "items": { "type": "string", "maxLength": 3 } },
+			"nick": { "type": "string", "default": "anon" }
+		}
+	}`)
+
+	paths := []string{}
+	opts := ValidateOptions{
+		PreTransform: func(path string, value interface{}) interface{} {
+			paths = append(paths, path)
+			if str, ok := value.(string); ok {
+				return strings.ToLower(strings.TrimSpace(str))
+			}
+			return value
+		},
+	}
+
+	errs, err := rs.ValidateBytesWithOptions([]byte(`{ "role": " Admin ", "tags": ["  ab  ", "ABC"] }`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected transformed values to be valid, got: %v", errs)
+	}
+	sort.Strings(paths)
+	if expect := []string{"/role", "/tags/0", "/tags/1"}; !reflect.DeepEqual(expect, paths) {
+		t.Errorf("expected transform paths: %v, got: %v", expect, paths)
+	}
+
+	// transforms run before coercion
+	opts.Coerce = true
+	errs, err = rs.ValidateBytesWithOptions([]byte(`{ "age": " 12 " }`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Error() != `/age: 12 must be >= 18` {
+		t.Errorf("expected a minimum error, got: %v", errs)
+	}
+
+	normalized, errs, err := rs.ValidateAndNormalizeWithOptions([]byte(`{ "role": "USER", "age": "20" }`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(normalized, &got); err != nil {
+		t.Fatal(err)
+	}
+	if expect := map[string]interface{}{"role": "user", "age": float64(20), "nick": "anon"}; !reflect.DeepEqual(expect, got) {
+		t.Errorf("expected normalized document: %v, got: %v", expect, got)
+	}
+}
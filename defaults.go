@@ -0,0 +1,105 @@
+package jsonschema
+
+import (
+	"fmt"
+)
+
+// ApplyDefaults fills in missing object properties of a json document with
+// the "default" values declared by the schema, returning the resulting
+// document. Defaults are collected from the "properties" of the schema
+// itself, the target of its "$ref", and each of its "allOf" branches, in
+// that order. When more than one of these declares a default for the same
+// property, the first one found wins.
+func (rs *RootSchema) ApplyDefaults(data []byte) ([]byte, error) {
+	var doc interface{}
+	if err := DefaultDecoder.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
+	}
+	doc = applyDefaults(&rs.Schema, doc)
+	return DefaultEncoder.Marshal(doc)
+}
+
+// applyDefaults fills defaults into data, modifying it in place. the
+// possibly-replaced value is returned
+func applyDefaults(sch *Schema, data interface{}) interface{} {
+	schemas := expandSchema(sch, nil, map[*Schema]bool{})
+
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for _, s := range schemas {
+			props, ok := s.Validators["properties"].(*Properties)
+			if !ok {
+				continue
+			}
+			for key, prop := range *props {
+				if _, ok := v[key]; ok || prop == nil {
+					continue
+				}
+				if def := schemaDefault(prop); def != nil {
+					if cp, err := toGeneric(def); err == nil {
+						v[key] = cp
+					}
+				}
+			}
+		}
+		for key, val := range v {
+			for _, s := range schemas {
+				if props, ok := s.Validators["properties"].(*Properties); ok && (*props)[key] != nil {
+					v[key] = applyDefaults((*props)[key], val)
+					val = v[key]
+				}
+			}
+		}
+	case []interface{}:
+		for _, s := range schemas {
+			it, ok := s.Validators["items"].(*Items)
+			if !ok {
+				continue
+			}
+			for i, elem := range v {
+				if it.single {
+					v[i] = applyDefaults(it.Schemas[0], elem)
+				} else if i < len(it.Schemas) {
+					v[i] = applyDefaults(it.Schemas[i], elem)
+				}
+			}
+		}
+	}
+	return data
+}
+
+// schemaDefault finds the default value for a schema, following "$ref"
+// and "allOf" the same way property collection does
+func schemaDefault(sch *Schema) interface{} {
+	for _, s := range expandSchema(sch, nil, map[*Schema]bool{}) {
+		if s.Default != nil {
+			return s.Default
+		}
+	}
+	return nil
+}
+
+// expandSchema lists sch followed by the target of its reference and each
+// of its allOf branches, recursively, in depth-first order
+func expandSchema(sch *Schema, list []*Schema, seen map[*Schema]bool) []*Schema {
+	if sch == nil || seen[sch] {
+		return list
+	}
+	seen[sch] = true
+	list = append(list, sch)
+
+	if sch.Ref != "" {
+		switch ref := sch.ref.(type) {
+		case *Schema:
+			list = expandSchema(ref, list, seen)
+		case *RootSchema:
+			list = expandSchema(&ref.Schema, list, seen)
+		}
+	}
+	if all, ok := sch.Validators["allOf"].(*AllOf); ok {
+		for _, s := range *all {
+			list = expandSchema(s, list, seen)
+		}
+	}
+	return list
+}
@@ -0,0 +1,69 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/json-iterator/go"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	cases := []struct {
+		schema, input, expect string
+	}{
+		{`{ "properties": { "a": { "default": 1 } } }`, `{}`, `{"a":1}`},
+		{`{ "properties": { "a": { "default": 1 } } }`, `{"a":2}`, `{"a":2}`},
+		{`{ "properties": { "a": { "properties": { "b": { "default": "x" } } } } }`, `{"a":{}}`, `{"a":{"b":"x"}}`},
+		{`{ "properties": { "a": { "default": {}, "properties": { "b": { "default": "x" } } } } }`, `{}`, `{"a":{"b":"x"}}`},
+		{`{ "items": { "properties": { "a": { "default": true } } } }`, `[{},{"a":false}]`, `[{"a":true},{"a":false}]`},
+		// defaults contributed by allOf branches
+		{`{ "allOf": [
+			{ "properties": { "a": { "default": 1 } } },
+			{ "properties": { "b": { "default": 2 } } }
+		] }`, `{}`, `{"a":1,"b":2}`},
+		// first branch wins
+		{`{ "allOf": [
+			{ "properties": { "a": { "default": "first" } } },
+			{ "properties": { "a": { "default": "second" } } }
+		] }`, `{}`, `{"a":"first"}`},
+		// the schema's own properties come before allOf branches
+		{`{
+			"properties": { "a": { "default": "own" } },
+			"allOf": [ { "properties": { "a": { "default": "branch" } } } ]
+		}`, `{}`, `{"a":"own"}`},
+		// defaults found through $ref
+		{`{
+			"definitions": { "base": { "properties": { "a": { "default": 1 } } } },
+			"allOf": [ { "$ref": "#/definitions/base" } ]
+		}`, `{}`, `{"a":1}`},
+		{`{
+			"definitions": { "a": { "default": "ref" } },
+			"properties": { "a": { "$ref": "#/definitions/a" } }
+		}`, `{}`, `{"a":"ref"}`},
+		// recursive references terminate
+		{`{ "properties": { "child": { "$ref": "#" }, "a": { "default": 0 } } }`, `{"child":{"child":{}}}`, `{"a":0,"child":{"a":0,"child":{"a":0}}}`},
+	}
+
+	for i, c := range cases {
+		rs := &RootSchema{}
+		if err := rs.UnmarshalJSON([]byte(c.schema)); err != nil {
+			t.Errorf("case %d error parsing schema: %s", i, err.Error())
+			continue
+		}
+		got, err := rs.ApplyDefaults([]byte(c.input))
+		if err != nil {
+			t.Errorf("case %d error applying defaults: %s", i, err.Error())
+			continue
+		}
+		var expect, result interface{}
+		if err := jsoniter.Unmarshal([]byte(c.expect), &expect); err != nil {
+			t.Fatal(err)
+		}
+		if err := jsoniter.Unmarshal(got, &result); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expect, result) {
+			t.Errorf("case %d result mismatch. expected: %s, got: %s", i, c.expect, string(got))
+		}
+	}
+}
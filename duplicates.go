@@ -0,0 +1,56 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/json-iterator/go"
+	"github.com/qri-io/jsonpointer"
+)
+
+// duplicateKeys scans json byte data for objects that repeat a key,
+// giving an error at the path of each such object. Decoding keeps only the
+// last value of a repeated key, so duplicates can only be found in the raw
+// document
+func duplicateKeys(data []byte) []ValError {
+	errs := []ValError{}
+	iter := jsoniter.ParseBytes(jsoniter.ConfigDefault, data)
+	scanDuplicateKeys(iter, jsonpointer.Pointer{}, &errs)
+	return errs
+}
+
+// scanDuplicateKeys reads the value at the iterator's position, recording
+// repeated keys of objects within it
+func scanDuplicateKeys(iter *jsoniter.Iterator, path jsonpointer.Pointer, errs *[]ValError) {
+	switch iter.WhatIsNext() {
+	case jsoniter.ObjectValue:
+		seen := map[string]bool{}
+		iter.ReadObjectCB(func(iter *jsoniter.Iterator, key string) bool {
+			if seen[key] {
+				propPath := path.String()
+				if propPath == "" {
+					propPath = "/"
+				}
+				*errs = append(*errs, ValError{
+					PropertyPath: propPath,
+					Message:      fmt.Sprintf("duplicate key %q", key),
+					Params:       map[string]interface{}{"key": key},
+				})
+			}
+			seen[key] = true
+			d, _ := path.Descendant(key)
+			scanDuplicateKeys(iter, d, errs)
+			return true
+		})
+	case jsoniter.ArrayValue:
+		i := 0
+		iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+			d, _ := path.Descendant(strconv.Itoa(i))
+			scanDuplicateKeys(iter, d, errs)
+			i++
+			return true
+		})
+	default:
+		iter.Skip()
+	}
+}
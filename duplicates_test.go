@@ -0,0 +1,40 @@
+package jsonschema
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRejectDuplicateKeys(t *testing.T) {
+	rs := Must(`{"type": "object"}`)
+	cases := []struct {
+		doc  string
+		errs []string
+	}{
+		{`{"a":1,"a":2}`, []string{`/: duplicate key "a"`}},
+		{`{"a":1,"b":2}`, nil},
+		{`{"a":{"b":1,"b":2},"c":[{"d":1},{"d":1,"d":1}]}`, []string{`/a: duplicate key "b"`, `/c/1: duplicate key "d"`}},
+		{`{"a~b":{"x":1,"x":1}}`, []string{`/a~0b: duplicate key "x"`}},
+	}
+
+	for i, c := range cases {
+		errs, err := rs.ValidateBytesWithOptions([]byte(c.doc), ValidateOptions{RejectDuplicateKeys: true})
+		if err != nil {
+			t.Fatalf("case %d: %s", i, err.Error())
+		}
+		want := append([]string{}, c.errs...)
+		sort.Strings(want)
+		if got := errorLines(errs); got != strings.Join(want, "\n") {
+			t.Errorf("case %d: expected errors:\n%s\ngot:\n%s", i, strings.Join(want, "\n"), got)
+		}
+	}
+
+	errs, err := rs.ValidateBytes([]byte(`{"a":1,"a":2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected duplicate keys to be accepted by default, got: %v", errs)
+	}
+}
@@ -0,0 +1,127 @@
+package jsonschema
+
+import (
+	"sort"
+)
+
+// Evaluated records the object properties and array items of a document
+// that keywords evaluated while validating it: properties matched by
+// "properties", "patternProperties", or "additionalProperties", and items
+// matched by "items", "additionalItems", or "contains". These are the
+// annotations keywords like "unevaluatedProperties" are defined by, so
+// they can be used to build such keywords on top of validation. As the
+// spec requires, evaluations by subschemas that fail are discarded, eg: by
+// an "anyOf" branch that doesn't match. This is an advanced feature, most
+// validations don't need it
+type Evaluated struct {
+	entries []evaluation
+}
+
+// evaluation is a single property or item evaluated at a location, item
+// is -1 for properties. contains marks items matched by "contains"
+type evaluation struct {
+	path     string
+	property string
+	item     int
+	contains bool
+}
+
+// Locations lists the property paths of objects and arrays with evaluated
+// properties or items, in sorted order
+func (e *Evaluated) Locations() []string {
+	seen := map[string]bool{}
+	paths := []string{}
+	for _, ev := range e.entries {
+		if !seen[ev.path] {
+			seen[ev.path] = true
+			paths = append(paths, ev.path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Properties gives the sorted names of the evaluated properties of the
+// object at path
+func (e *Evaluated) Properties(path string) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, ev := range e.entries {
+		if ev.path == path && ev.item < 0 && !seen[ev.property] {
+			seen[ev.property] = true
+			names = append(names, ev.property)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Items gives the sorted indices of the evaluated items of the array at
+// path
+func (e *Evaluated) Items(path string) []int {
+	seen := map[int]bool{}
+	indices := []int{}
+	for _, ev := range e.entries {
+		if ev.path == path && ev.item >= 0 && !seen[ev.item] {
+			seen[ev.item] = true
+			indices = append(indices, ev.item)
+		}
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// Contains gives the sorted indices of the items of the array at path
+// that matched its "contains" schema. They're also among its Items
+func (e *Evaluated) Contains(path string) []int {
+	seen := map[int]bool{}
+	indices := []int{}
+	for _, ev := range e.entries {
+		if ev.path == path && ev.contains && !seen[ev.item] {
+			seen[ev.item] = true
+			indices = append(indices, ev.item)
+		}
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// evaluateProperty records that a keyword evaluated the property name of
+// the object at path
+func (vc *ValidationContext) evaluateProperty(path, name string) {
+	if e := vc.Options.Evaluated; e != nil {
+		e.entries = append(e.entries, evaluation{path: path, property: name, item: -1})
+	}
+}
+
+// evaluateItem records that a keyword evaluated item i of the array at
+// path
+func (vc *ValidationContext) evaluateItem(path string, i int) {
+	if e := vc.Options.Evaluated; e != nil {
+		e.entries = append(e.entries, evaluation{path: path, item: i})
+	}
+}
+
+// containsItem records that item i of the array at path matched a
+// "contains" schema
+func (vc *ValidationContext) containsItem(path string, i int) {
+	if e := vc.Options.Evaluated; e != nil {
+		e.entries = append(e.entries, evaluation{path: path, item: i, contains: true})
+	}
+}
+
+// evaluations marks the current position in the evaluation record, for
+// discarding the evaluations of a subschema that fails
+func (vc *ValidationContext) evaluations() int {
+	if e := vc.Options.Evaluated; e != nil {
+		return len(e.entries)
+	}
+	return 0
+}
+
+// discardEvaluations drops evaluations recorded since mark
+func (vc *ValidationContext) discardEvaluations(mark int) {
+	if e := vc.Options.Evaluated; e != nil && mark < len(e.entries) {
+		e.entries = e.entries[:mark]
+	}
+}
@@ -0,0 +1,105 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEvaluated(t *testing.T) {
+	schema := `{
+		"properties": { "a": {}, "nested": { "items": [{}, {}] } },
+		"patternProperties": { "^x-": {} },
+		"anyOf": [
+			{ "properties": { "b": { "type": "string" } } },
+			{ "properties": { "c": {} }, "required": ["missing"] }
+		],
+		"not": { "properties": { "d": { "type": "string" } } },
+		"if": { "properties": { "e": { "const": 1 } } },
+		"then": { "properties": { "f": {} } },
+		"else": { "properties": { "g": {} } }
+	}`
+	rs := &RootSchema{}
+	if err := rs.UnmarshalJSON([]byte(schema)); err != nil {
+		t.Fatal(err)
+	}
+
+	ev := &Evaluated{}
+	data := `{"a": 1, "b": "x", "c": 2, "d": 3, "e": 2, "f": 4, "g": 5, "x-y": 6, "nested": [1, 2, 3]}`
+	errs, err := rs.ValidateBytesWithOptions([]byte(data), ValidateOptions{Evaluated: ev})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	expectProps := []string{"a", "b", "g", "nested", "x-y"}
+	if got := ev.Properties("/"); !reflect.DeepEqual(got, expectProps) {
+		t.Errorf("properties mismatch. expected: %v, got: %v", expectProps, got)
+	}
+	if got := ev.Items("/nested"); !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Errorf("items mismatch. expected: [0 1], got: %v", got)
+	}
+	expectLocs := []string{"/", "/nested"}
+	if got := ev.Locations(); !reflect.DeepEqual(got, expectLocs) {
+		t.Errorf("locations mismatch. expected: %v, got: %v", expectLocs, got)
+	}
+
+	// evaluations are cleared each validation
+	if _, err := rs.ValidateBytesWithOptions([]byte(`{"a": 1}`), ValidateOptions{Evaluated: ev}); err != nil {
+		t.Fatal(err)
+	}
+	if got := ev.Properties("/"); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("properties after revalidating mismatch. expected: [a], got: %v", got)
+	}
+}
+
+func TestEvaluatedContainsItems(t *testing.T) {
+	rs := &RootSchema{}
+	if err := rs.UnmarshalJSON([]byte(`{"contains": {"type": "string"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	ev := &Evaluated{}
+	errs, err := rs.ValidateBytesWithOptions([]byte(`[1, "a", 2, "b"]`), ValidateOptions{Evaluated: ev})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if got := ev.Items("/"); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Errorf("items mismatch. expected: [1 3], got: %v", got)
+	}
+}
+
+func TestEvaluatedContainsIndices(t *testing.T) {
+	rs := &RootSchema{}
+	if err := rs.UnmarshalJSON([]byte(`{"items": [{}], "contains": {"type": "integer"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	ev := &Evaluated{}
+	errs, err := rs.ValidateBytesWithOptions([]byte(`["a", 1, true, 2.5, 3, null, {"b": 4}]`), ValidateOptions{Evaluated: ev})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if got := ev.Contains("/"); !reflect.DeepEqual(got, []int{1, 4}) {
+		t.Errorf("contains mismatch. expected: [1 4], got: %v", got)
+	}
+	if got := ev.Items("/"); !reflect.DeepEqual(got, []int{0, 1, 4}) {
+		t.Errorf("items mismatch. expected: [0 1 4], got: %v", got)
+	}
+
+	errs, err = rs.ValidateBytesWithOptions([]byte(`["a", true, 2.5]`), ValidateOptions{Evaluated: ev})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Message != "none of the 3 items match the contains schema" || errs[0].Params["items"] != 3 {
+		t.Errorf("expected a single contains error, got: %v", errs)
+	}
+	if got := ev.Contains("/"); len(got) != 0 {
+		t.Errorf("expected no contains matches, got: %v", got)
+	}
+}
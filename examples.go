@@ -0,0 +1,42 @@
+package jsonschema
+
+import (
+	"strconv"
+
+	"github.com/qri-io/jsonpointer"
+)
+
+// CheckExamples validates the sample values of every schema in the tree,
+// the entries of "examples" and the single "example" of OpenAPI and
+// draft-04 schemas, against the schema declaring them. Examples are
+// instances like any other, so errors are reported at the JSON pointer of
+// the example within the schema document, followed by the path to the
+// value that failed, eg: "/properties/age/examples/1". Each error's
+// SchemaPath locates the failing keyword in the schema document, which
+// makes stale examples easy to catch in CI
+func (rs *RootSchema) CheckExamples() []ValError {
+	errs := []ValError{}
+	walkJSONPath(&rs.Schema, jsonpointer.Pointer{}, func(ptr jsonpointer.Pointer, elem JSONPather) error {
+		sch := nodeSchema(elem)
+		if sch == nil {
+			return nil
+		}
+		for i, example := range sch.Examples {
+			checkExample(sch, ptr, append(ptr[:len(ptr):len(ptr)], "examples", strconv.Itoa(i)), example, &errs)
+		}
+		if sch.Example != nil {
+			checkExample(sch, ptr, append(ptr[:len(ptr):len(ptr)], "example"), sch.Example, &errs)
+		}
+		return nil
+	})
+	return errs
+}
+
+// checkExample validates an example found at examplePtr against sch, the
+// schema at schemaPtr
+func checkExample(sch *Schema, schemaPtr, examplePtr jsonpointer.Pointer, example interface{}, errs *[]ValError) {
+	vc := newValidationContext(nil)
+	vc.Root = example
+	vc.location.path = schemaPtr.String()
+	sch.ValidateContext(vc, examplePtr.String(), example, errs)
+}
@@ -0,0 +1,52 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestCheckExamples(t *testing.T) {
+	rs := &RootSchema{}
+	if err := rs.UnmarshalJSON([]byte(`{
+		"type": "object",
+		"properties": {
+			"age": { "type": "integer", "minimum": 0, "examples": [30, -1, "old"] },
+			"name": { "type": "string", "example": "Ada" },
+			"tags": { "type": "array", "items": { "type": "string", "example": 5 } }
+		},
+		"examples": [{ "age": 30, "name": "Ada" }, { "name": 7 }]
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{
+		"/examples/1/name: type should be string #/properties/name/type",
+		"/properties/age/examples/1: must be >= 0 #/properties/age/minimum",
+		"/properties/age/examples/2: type should be integer #/properties/age/type",
+		"/properties/tags/items/example: type should be string #/properties/tags/items/type",
+	}
+	got := map[string]bool{}
+	for _, e := range rs.CheckExamples() {
+		got[e.PropertyPath+": "+e.Message+" "+e.SchemaPath] = true
+	}
+	if len(got) != len(expect) {
+		t.Errorf("expected %d errors, got: %v", len(expect), got)
+	}
+	for _, e := range expect {
+		if !got[e] {
+			t.Errorf("missing error: %s", e)
+		}
+	}
+
+	if errs := Must(`{ "type": "string", "examples": ["a", "b"] }`).CheckExamples(); len(errs) != 0 {
+		t.Errorf("expected valid examples to pass, got: %v", errs)
+	}
+
+	// "example" round trips
+	data, err := Must(`{"example":{"a":1}}`).MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"example":{"a":1}}` {
+		t.Errorf("expected example to round trip, got: %s", data)
+	}
+}
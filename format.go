@@ -0,0 +1,100 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatOptions configures FormatErrors
+type FormatOptions struct {
+	// MaxValueLen is how long a printed value can be before it's truncated.
+	// zero uses MaxValueErrStringLen, a special value of -1 prints full
+	// values
+	MaxValueLen int
+	// Keyword prefixes each error with the keyword that produced it
+	Keyword bool
+	// Color highlights keywords and property paths with ANSI escape codes,
+	// giving each keyword its own color
+	Color bool
+	// GroupByPath lists errors under a heading for each property path, in
+	// the order the paths first appear
+	GroupByPath bool
+}
+
+// keywordColors is the palette of ANSI colors keywords are drawn from
+var keywordColors = []string{"31", "32", "33", "34", "35", "36"}
+
+// FormatErrors renders validation errors for display, one per line
+func FormatErrors(errs []ValError, opts FormatOptions) string {
+	maxLen := opts.MaxValueLen
+	if maxLen == 0 {
+		maxLen = MaxValueErrStringLen
+	}
+
+	if !opts.GroupByPath {
+		lines := make([]string, len(errs))
+		for i, e := range errs {
+			lines[i] = opts.formatError(e, maxLen, true)
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	paths := []string{}
+	groups := map[string][]ValError{}
+	for _, e := range errs {
+		if _, ok := groups[e.PropertyPath]; !ok {
+			paths = append(paths, e.PropertyPath)
+		}
+		groups[e.PropertyPath] = append(groups[e.PropertyPath], e)
+	}
+
+	buf := &strings.Builder{}
+	for i, path := range paths {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		if path == "" {
+			path = "/"
+		}
+		buf.WriteString(opts.colorize("1", path))
+		for _, e := range groups[paths[i]] {
+			buf.WriteString("\n  ")
+			buf.WriteString(opts.formatError(e, maxLen, false))
+		}
+	}
+	return buf.String()
+}
+
+// formatError renders a single error, optionally leading with it's
+// property path
+func (opts FormatOptions) formatError(e ValError, maxLen int, withPath bool) string {
+	parts := []string{}
+	if opts.Keyword && e.Keyword != "" {
+		parts = append(parts, opts.colorize(keywordColor(e.Keyword), "["+e.Keyword+"]"))
+	}
+	if withPath && e.PropertyPath != "" {
+		parts = append(parts, opts.colorize("1", e.PropertyPath+":"))
+	}
+	if e.InvalidValue != nil {
+		parts = append(parts, truncatedValueString(e.InvalidValue, maxLen))
+	}
+	parts = append(parts, e.Message)
+	return strings.Join(parts, " ")
+}
+
+// colorize wraps str in an ANSI escape sequence when color is enabled
+func (opts FormatOptions) colorize(code, str string) string {
+	if !opts.Color {
+		return str
+	}
+	return fmt.Sprintf("\x1b[%sm%s\x1b[0m", code, str)
+}
+
+// keywordColor picks a stable color for a keyword
+func keywordColor(keyword string) string {
+	sum := 0
+	for _, r := range keyword {
+		sum += int(r)
+	}
+	return keywordColors[sum%len(keywordColors)]
+}
@@ -0,0 +1,41 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestFormatErrors(t *testing.T) {
+	errs := []ValError{
+		{PropertyPath: "/name", InvalidValue: "Prince Rogers Nelson, formerly", Message: "max length of 10 characters exceeded", Keyword: "maxLength"},
+		{PropertyPath: "/age", InvalidValue: -1, Message: "must be greater than or equal to 0", Keyword: "minimum"},
+		{PropertyPath: "/name", InvalidValue: "Prince Rogers Nelson, formerly", Message: "should be foo", Keyword: "foo"},
+	}
+
+	cases := []struct {
+		opts   FormatOptions
+		expect string
+	}{
+		{FormatOptions{}, `/name: "Prince Rogers Nelso... max length of 10 characters exceeded
+/age: -1 must be greater than or equal to 0
+/name: "Prince Rogers Nelso... should be foo`},
+		{FormatOptions{MaxValueLen: -1, Keyword: true}, `[maxLength] /name: "Prince Rogers Nelson, formerly" max length of 10 characters exceeded
+[minimum] /age: -1 must be greater than or equal to 0
+[foo] /name: "Prince Rogers Nelson, formerly" should be foo`},
+		{FormatOptions{MaxValueLen: 5, GroupByPath: true}, `/name
+  "Prin... max length of 10 characters exceeded
+  "Prin... should be foo
+/age
+  -1 must be greater than or equal to 0`},
+		{FormatOptions{Color: true, Keyword: true}, "\x1b[33m[minimum]\x1b[0m \x1b[1m/age:\x1b[0m -1 must be greater than or equal to 0"},
+	}
+
+	for i, c := range cases {
+		input := errs
+		if c.opts.Color {
+			input = errs[1:2]
+		}
+		if got := FormatErrors(input, c.opts); got != c.expect {
+			t.Errorf("case %d mismatch. expected:\n%s\ngot:\n%s", i, c.expect, got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package jsonschema
+
+// customFormat is a format registered with RegisterFormatWithNormalizer
+type customFormat struct {
+	validate  func(string) bool
+	normalize func(string) string
+}
+
+// customFormats holds registered formats, by format name
+var customFormats = map[string]customFormat{}
+
+// RegisterFormatWithNormalizer adds a "format" that strings are validated
+// against with validate, registering a standard format replaces it.
+// ValidateAndNormalize rewrites strings that are valid against the format
+// to the canonical form normalize gives for them, eg: a "date-time" with a
+// lowercase "t" and "z". normalize may be nil for formats without a
+// canonical form. Schemas parsed before registering use the format too
+func RegisterFormatWithNormalizer(name string, validate func(string) bool, normalize func(string) string) {
+	customFormats[name] = customFormat{validate: validate, normalize: normalize}
+}
+
+// normalizeFormats rewrites the strings of data that are valid against a
+// registered format of the schema that applies to them to their
+// normalized form, modifying data in place. The possibly-replaced value is
+// returned
+func normalizeFormats(sch *Schema, data interface{}) interface{} {
+	schemas := expandSchema(sch, nil, map[*Schema]bool{})
+
+	switch v := data.(type) {
+	case string:
+		for _, s := range schemas {
+			if f, ok := customFormats[s.Format]; ok && f.normalize != nil && f.validate(v) {
+				return f.normalize(v)
+			}
+		}
+	case map[string]interface{}:
+		for key, val := range v {
+			for _, s := range schemas {
+				if props, ok := s.Validators["properties"].(*Properties); ok && (*props)[key] != nil {
+					v[key] = normalizeFormats((*props)[key], val)
+					val = v[key]
+				}
+			}
+		}
+	case []interface{}:
+		for _, s := range schemas {
+			it, ok := s.Validators["items"].(*Items)
+			if !ok {
+				continue
+			}
+			for i, elem := range v {
+				if it.single {
+					v[i] = normalizeFormats(it.Schemas[0], elem)
+				} else if i < len(it.Schemas) {
+					v[i] = normalizeFormats(it.Schemas[i], elem)
+				}
+			}
+		}
+	}
+	return data
+}
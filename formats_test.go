@@ -0,0 +1,62 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegisterFormatWithNormalizer(t *testing.T) {
+	RegisterFormatWithNormalizer("date-time", func(str string) bool {
+		return isValidDateTime(strings.ToUpper(str)) == nil
+	}, strings.ToUpper)
+	defer delete(customFormats, "date-time")
+
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"at": { "type": "string", "format": "date-time" },
+			"log": { "type": "array", "items": { "format": "date-time" } },
+			"note": { "type": "string" }
+		}
+	}`)
+
+	normalized, errs, err := rs.ValidateAndNormalize([]byte(`{"at": "2021-01-01t00:00:00z", "log": ["2021-01-02t10:30:00+01:00"], "note": "2021-01-01t00:00:00z"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	expect := `{"at":"2021-01-01T00:00:00Z","log":["2021-01-02T10:30:00+01:00"],"note":"2021-01-01t00:00:00z"}`
+	if got := canonicalJSON(decodeJSON(t, normalized)); got != expect {
+		t.Errorf("normalized mismatch. expected: %s, got: %s", expect, got)
+	}
+
+	// validation alone accepts the non-canonical form, and rejects invalid values
+	errs, err = rs.ValidateBytes([]byte(`{"at": "2021-01-01t00:00:00z"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	normalized, errs, err = rs.ValidateAndNormalize([]byte(`{"at": "yesterday"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Message != "invalid date-time" {
+		t.Errorf("expected an invalid date-time error, got: %v", errs)
+	}
+	if got := canonicalJSON(decodeJSON(t, normalized)); got != `{"at":"yesterday"}` {
+		t.Errorf("expected invalid values to be left as they are, got: %s", got)
+	}
+}
+
+// decodeJSON decodes json byte data into a generic value
+func decodeJSON(t *testing.T, data []byte) interface{} {
+	var v interface{}
+	if err := DefaultDecoder.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
@@ -6,4 +6,4 @@ require (
 	github.com/sergi/go-diff v1.0.0
 )
 
-go 1.13
+go 1.16
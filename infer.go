@@ -0,0 +1,209 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"sort"
+)
+
+// InferOptions configures schema inference
+type InferOptions struct {
+	// OptionalProperties leaves properties out of "required". By default
+	// every property of an object is required, or for arrays of objects
+	// every property that all of the objects have
+	OptionalProperties bool
+	// GuessFormats adds a "format" to strings that look like a
+	// "date-time", "uuid", "email", or "uri". Guesses are conservative, a
+	// format is only added when all strings at a location are plainly of
+	// that format, eg: emails with a display name or URIs without a host
+	// aren't tagged
+	GuessFormats bool
+}
+
+// InferSchema generates a draft-07 schema describing the json document
+// data: the types of values, the properties of objects, and a single
+// "items" schema for arrays that unions the schemas of their elements.
+// It's a starting point for writing a schema by hand, as any document
+// has many schemas that describe it
+func InferSchema(data []byte) (*RootSchema, error) {
+	return InferSchemaWithOptions(data, InferOptions{})
+}
+
+// InferSchemaWithOptions works like InferSchema, configured by opts
+func InferSchemaWithOptions(data []byte, opts InferOptions) (*RootSchema, error) {
+	var doc interface{}
+	if err := DefaultDecoder.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
+	}
+
+	obj := infer(doc, &opts).toJSON(&opts)
+	obj["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema, err := DefaultEncoder.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	rs := &RootSchema{}
+	if err := DefaultDecoder.Unmarshal(schema, rs); err != nil {
+		return nil, fmt.Errorf("error parsing inferred schema: %s", err.Error())
+	}
+	return rs, nil
+}
+
+// inferred describes the values seen at a location of a document
+type inferred struct {
+	types map[string]bool
+	// properties and required are the properties of objects, and the ones
+	// all objects have
+	properties map[string]*inferred
+	required   map[string]bool
+	// items describes the elements of arrays, nil if all were empty
+	items *inferred
+	// format is the format all strings are valid against, if any
+	format string
+}
+
+// infer describes a single decoded json value
+func infer(value interface{}, opts *InferOptions) *inferred {
+	in := &inferred{types: map[string]bool{}}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		in.types["object"] = true
+		in.properties = map[string]*inferred{}
+		in.required = map[string]bool{}
+		for key, val := range v {
+			in.properties[key] = infer(val, opts)
+			in.required[key] = true
+		}
+	case []interface{}:
+		in.types["array"] = true
+		for _, elem := range v {
+			in.items = in.items.merge(infer(elem, opts))
+		}
+	case string:
+		in.types["string"] = true
+		if opts.GuessFormats {
+			in.format = guessFormat(v)
+		}
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			in.types["integer"] = true
+		} else {
+			in.types["number"] = true
+		}
+	case json.Number:
+		// as decoded with UseNumber
+		if DataType(v) == "integer" {
+			in.types["integer"] = true
+		} else {
+			in.types["number"] = true
+		}
+	case bool:
+		in.types["boolean"] = true
+	case nil:
+		in.types["null"] = true
+	}
+	return in
+}
+
+// merge unions the values described by in with those described by b
+func (in *inferred) merge(b *inferred) *inferred {
+	if in == nil {
+		return b
+	}
+	if b == nil {
+		return in
+	}
+
+	if in.types["string"] && b.types["string"] && in.format != b.format {
+		in.format = ""
+	} else if !in.types["string"] {
+		in.format = b.format
+	}
+
+	if in.types["object"] && b.types["object"] {
+		for key := range in.required {
+			if !b.required[key] {
+				delete(in.required, key)
+			}
+		}
+		for key, prop := range b.properties {
+			in.properties[key] = in.properties[key].merge(prop)
+		}
+	} else if b.types["object"] {
+		in.properties, in.required = b.properties, b.required
+	}
+
+	in.items = in.items.merge(b.items)
+	for t := range b.types {
+		in.types[t] = true
+	}
+	return in
+}
+
+// toJSON gives the generic JSON form of the schema for in
+func (in *inferred) toJSON(opts *InferOptions) map[string]interface{} {
+	types := []string{}
+	for t := range in.types {
+		// integers are numbers
+		if t == "integer" && in.types["number"] {
+			continue
+		}
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	sch := map[string]interface{}{}
+	if len(types) == 1 {
+		sch["type"] = types[0]
+	} else {
+		sch["type"] = types
+	}
+	if in.format != "" {
+		sch["format"] = in.format
+	}
+	if in.properties != nil {
+		props := map[string]interface{}{}
+		for key, prop := range in.properties {
+			props[key] = prop.toJSON(opts)
+		}
+		sch["properties"] = props
+
+		required := []string{}
+		for key := range in.required {
+			required = append(required, key)
+		}
+		sort.Strings(required)
+		if len(required) > 0 && !opts.OptionalProperties {
+			sch["required"] = required
+		}
+	}
+	if in.items != nil {
+		sch["items"] = in.items.toJSON(opts)
+	}
+	return sch
+}
+
+// guessFormat gives the format a string is valid against, checking only
+// formats that are unlikely to match by accident. Strings must also pass
+// stricter checks than the format validators make, as values like "a:b"
+// are valid URIs
+func guessFormat(str string) string {
+	switch {
+	case isValidDateTime(str) == nil:
+		return "date-time"
+	case isValidUUID(str) == nil:
+		return "uuid"
+	case isValidEmail(str) == nil:
+		if addr, err := mail.ParseAddress(str); err == nil && addr.Address == str {
+			return "email"
+		}
+	case isValidURI(str) == nil:
+		if u, err := url.Parse(str); err == nil && u.Host != "" && u.Scheme != "" {
+			return "uri"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInferSchema(t *testing.T) {
+	cases := []struct {
+		opts   InferOptions
+		doc    string
+		expect string
+	}{
+		{InferOptions{}, `"a"`, `{"type": "string"}`},
+		{InferOptions{}, `1`, `{"type": "integer"}`},
+		{InferOptions{}, `1.5`, `{"type": "number"}`},
+		{InferOptions{}, `null`, `{"type": "null"}`},
+		{InferOptions{}, `[]`, `{"type": "array"}`},
+		{InferOptions{}, `[1, 2.5]`, `{"type": "array", "items": {"type": "number"}}`},
+		{InferOptions{}, `[1, "a", null]`, `{"type": "array", "items": {"type": ["integer", "null", "string"]}}`},
+		{InferOptions{},
+			`{"name": "a", "tags": ["x"], "address": {"zip": "1"}}`,
+			`{"type": "object", "required": ["address", "name", "tags"], "properties": {
+				"name": {"type": "string"},
+				"tags": {"type": "array", "items": {"type": "string"}},
+				"address": {"type": "object", "required": ["zip"], "properties": {"zip": {"type": "string"}}}
+			}}`},
+		// only properties all objects have are required
+		{InferOptions{},
+			`[{"a": 1, "b": true}, {"a": 2, "c": null}]`,
+			`{"type": "array", "items": {"type": "object", "required": ["a"], "properties": {
+				"a": {"type": "integer"}, "b": {"type": "boolean"}, "c": {"type": "null"}
+			}}}`},
+		{InferOptions{OptionalProperties: true},
+			`{"a": 1}`,
+			`{"type": "object", "properties": {"a": {"type": "integer"}}}`},
+		{InferOptions{GuessFormats: true},
+			`["2018-11-13T20:20:39Z", "2019-01-01T00:00:00+01:00"]`,
+			`{"type": "array", "items": {"type": "string", "format": "date-time"}}`},
+		{InferOptions{GuessFormats: true},
+			`{"email": "a@example.com", "date": "2018-11-13T20:20:39Z", "mixed": ["a@example.com", "b"]}`,
+			`{"type": "object", "required": ["date", "email", "mixed"], "properties": {
+				"email": {"type": "string", "format": "email"},
+				"date": {"type": "string", "format": "date-time"},
+				"mixed": {"type": "array", "items": {"type": "string"}}
+			}}`},
+	}
+
+	for i, c := range cases {
+		rs, err := InferSchemaWithOptions([]byte(c.doc), c.opts)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %s", i, err)
+			continue
+		}
+		if rs.SchemaURI != "http://json-schema.org/draft-07/schema#" {
+			t.Errorf("case %d: expected a draft-07 $schema, got: %q", i, rs.SchemaURI)
+		}
+		data, err := json.Marshal(&rs.Schema)
+		if err != nil {
+			t.Errorf("case %d: error marshaling schema: %s", i, err)
+			continue
+		}
+		var got, expect interface{}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(c.expect), &expect); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("case %d: schema mismatch. expected: %s, got: %s", i, c.expect, data)
+		}
+
+		errs, err := rs.ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != 0 {
+			t.Errorf("case %d: expected the document to be valid against its schema, got: %v", i, errs)
+		}
+	}
+
+	if _, err := InferSchema([]byte(`{`)); err == nil {
+		t.Error("expected an error inferring from invalid json")
+	}
+}
+
+func TestInferNumbers(t *testing.T) {
+	cases := []struct {
+		value  interface{}
+		expect string
+	}{
+		{json.Number("12345678901234567890"), "integer"},
+		{json.Number("1e2"), "integer"},
+		{json.Number("2.5"), "number"},
+		{2.5, "number"},
+		{100.0, "integer"},
+	}
+	for i, c := range cases {
+		if got := infer(c.value, &InferOptions{}).toJSON(&InferOptions{})["type"]; got != c.expect {
+			t.Errorf("case %d: expected type %s, got: %v", i, c.expect, got)
+		}
+	}
+}
+
+func TestInferSchemaGuessFormats(t *testing.T) {
+	cases := []struct {
+		value  string
+		format string
+	}{
+		{"2018-11-13T20:20:39Z", "date-time"},
+		{"2eb8aa08-aa98-11ea-b4aa-73b441d16380", "uuid"},
+		{"a@example.com", "email"},
+		{"https://example.com/path?q=1", "uri"},
+		{"urn:isbn:0451450523", ""},
+		{"a:b", ""},
+		{"Jane Doe <jane@example.com>", ""},
+		{"2018-11-13", ""},
+		{"hello", ""},
+	}
+
+	for _, c := range cases {
+		if got := guessFormat(c.value); got != c.format {
+			t.Errorf("expected %q to be guessed as format %q, got: %q", c.value, c.format, got)
+		}
+	}
+
+	rs, err := InferSchemaWithOptions([]byte(`{"id": "2eb8aa08-aa98-11ea-b4aa-73b441d16380"}`), InferOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(&rs.Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "format") {
+		t.Errorf("expected no formats without GuessFormats, got: %s", data)
+	}
+}
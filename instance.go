@@ -0,0 +1,167 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PreparedInstance is a decoded json document that can be validated
+// against any number of schemas, decoding it only once. Canonical forms
+// of values computed for comparisons, eg: by "uniqueItems", are cached
+// and shared between validations. A PreparedInstance is safe for
+// concurrent use, and must not be modified
+type PreparedInstance struct {
+	doc interface{}
+
+	lock      sync.Mutex
+	canonical map[*interface{}]string
+}
+
+// NewPreparedInstance decodes json byte data into a PreparedInstance
+func NewPreparedInstance(data []byte) (*PreparedInstance, error) {
+	var doc interface{}
+	if err := DefaultDecoder.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
+	}
+	return &PreparedInstance{doc: doc, canonical: map[*interface{}]string{}}, nil
+}
+
+// Value gives the decoded document
+func (pi *PreparedInstance) Value() interface{} {
+	return pi.doc
+}
+
+// canonicalElem gives the canonical form of an array element within the
+// document, elements are identified by their address
+func (pi *PreparedInstance) canonicalElem(elem *interface{}) string {
+	pi.lock.Lock()
+	defer pi.lock.Unlock()
+	if str, ok := pi.canonical[elem]; ok {
+		return str
+	}
+	str := canonicalJSON(*elem)
+	pi.canonical[elem] = str
+	return str
+}
+
+// ValidateInstance performs schema validation against a prepared instance
+func (rs *RootSchema) ValidateInstance(pi *PreparedInstance) []ValError {
+	errs := []ValError{}
+	vc := newValidationContext(nil)
+	vc.Root = pi.doc
+	vc.instance = pi
+	rs.ValidateContext(vc, "/", pi.doc, &errs)
+	return errs
+}
+
+// canonicalJSON encodes a decoded json value with sorted object keys, so
+// equal values have equal encodings
+func canonicalJSON(value interface{}) string {
+	buf := &strings.Builder{}
+	writeCanonicalJSON(buf, value)
+	return buf.String()
+}
+
+// equalJSON compares decoded json values, numbers are equal by value
+// rather than representation, so a json.Number equals the float64 it
+// stands for
+func equalJSON(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b) || canonicalJSON(a) == canonicalJSON(b)
+}
+
+func writeCanonicalJSON(buf *strings.Builder, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(strconv.Quote(key))
+			buf.WriteByte(':')
+			writeCanonicalJSON(buf, v[key])
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalJSON(buf, elem)
+		}
+		buf.WriteByte(']')
+	case string:
+		buf.WriteString(strconv.Quote(v))
+	case float64:
+		buf.WriteString(canonicalNumber(strconv.FormatFloat(v, 'g', -1, 64)))
+	case json.Number:
+		buf.WriteString(canonicalNumber(string(v)))
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case nil:
+		buf.WriteString("null")
+	default:
+		fmt.Fprintf(buf, "%#v", v)
+	}
+}
+
+// canonicalNumber rewrites the decimal representation of a number so
+// numbers with the same value have the same representation, whatever
+// their precision, eg: "1.50", "15e-1", and "1.5" all give "1.5", and "-0"
+// gives "0". json.Numbers are compared exactly, so integers too large for
+// a float64 aren't rounded onto each other
+func canonicalNumber(num string) string {
+	str := num
+	neg := strings.HasPrefix(str, "-")
+	str = strings.TrimPrefix(str, "-")
+	exp := 0
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		e, err := strconv.Atoi(str[i+1:])
+		if err != nil {
+			return num
+		}
+		str, exp = str[:i], e
+	}
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		exp -= len(str) - i - 1
+		str = str[:i] + str[i+1:]
+	}
+	for _, c := range str {
+		if c < '0' || c > '9' {
+			return num
+		}
+	}
+
+	digits := strings.TrimLeft(str, "0")
+	if digits == "" {
+		return "0"
+	}
+	for strings.HasSuffix(digits, "0") {
+		digits = digits[:len(digits)-1]
+		exp++
+	}
+	sign := ""
+	if neg {
+		sign = "-"
+	}
+	switch {
+	case exp >= 0 && exp <= 20:
+		return sign + digits + strings.Repeat("0", exp)
+	case exp < 0 && len(digits) > -exp:
+		return sign + digits[:len(digits)+exp] + "." + digits[len(digits)+exp:]
+	case exp < 0 && -exp-len(digits) <= 6:
+		return sign + "0." + strings.Repeat("0", -exp-len(digits)) + digits
+	}
+	return sign + digits + "e" + strconv.Itoa(exp)
+}
@@ -0,0 +1,79 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestValidateInstance(t *testing.T) {
+	data := []byte(`{"tags": ["a", "b", "a"], "points": [{"x": 1, "y": 2}, {"y": 2, "x": 1.0}], "count": 3}`)
+	pi, err := NewPreparedInstance(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemas := []string{
+		`{ "type": "object" }`,
+		`{ "properties": { "tags": { "uniqueItems": true } } }`,
+		`{ "properties": { "points": { "uniqueItems": true, "items": { "required": ["x", "y"] } } } }`,
+		`{ "properties": { "count": { "maximum": 2 }, "tags": { "items": { "enum": ["a"] } } } }`,
+	}
+
+	for i, s := range schemas {
+		rs := Must(s)
+		expect, err := rs.ValidateBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// validate twice to exercise cached canonical forms
+		for j := 0; j < 2; j++ {
+			if got := rs.ValidateInstance(pi); !reflect.DeepEqual(sortedErrorStrings(expect), sortedErrorStrings(got)) {
+				t.Errorf("case %d: ValidateInstance mismatch. expected: %v, got: %v", i, expect, got)
+			}
+		}
+	}
+
+	if _, err := NewPreparedInstance([]byte(`{`)); err == nil {
+		t.Errorf("expected error preparing invalid JSON")
+	}
+}
+
+// sortedErrorStrings lists the paths & messages of errors in sorted order,
+// as keywords validate in no particular order. Error isn't used because
+// object values print with keys in no particular order
+func sortedErrorStrings(errs []ValError) []string {
+	strs := make([]string, len(errs))
+	for i, e := range errs {
+		strs[i] = e.PropertyPath + ": " + e.Message
+	}
+	sort.Strings(strs)
+	return strs
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	cases := []struct {
+		input  interface{}
+		expect string
+	}{
+		{map[string]interface{}{"b": 1.0, "a": []interface{}{"x", nil, true}}, `{"a":["x",null,true],"b":1}`},
+		{2.5, `2.5`},
+		{"q\"uote", `"q\"uote"`},
+		{[]interface{}{math.Copysign(0, -1), 0.0}, `[0,0]`},
+		{json.Number("1.50"), `1.5`},
+		{json.Number("15e-1"), `1.5`},
+		{json.Number("-0.0"), `0`},
+		{json.Number("0.00012"), `0.00012`},
+		{json.Number("1E30"), `1e30`},
+		{json.Number("9007199254740993"), `9007199254740993`},
+		{9007199254740992.0, `9007199254740992`},
+		{1e-10, `1e-10`},
+	}
+	for i, c := range cases {
+		if got := canonicalJSON(c.input); got != c.expect {
+			t.Errorf("case %d: expected: %s, got: %s", i, c.expect, got)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package jsonschema
+
+import (
+	"fmt"
+)
+
+// ParseJSONC parses json byte data that may contain comments into a
+// *RootSchema. "//" line comments and "/* */" block comments are removed
+// before parsing, comment-like text within strings is left untouched
+func ParseJSONC(data []byte) (*RootSchema, error) {
+	stripped, err := stripJSONComments(data)
+	if err != nil {
+		return nil, err
+	}
+	rs := &RootSchema{}
+	if err := rs.UnmarshalJSON(stripped); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// stripJSONComments gives a copy of data with comments replaced by spaces.
+// Newlines within comments are kept, so line and column offsets of the
+// remaining json are unchanged
+func stripJSONComments(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	copy(out, data)
+
+	inString := false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		if c == '"' {
+			inString = true
+			continue
+		}
+		if c != '/' || i+1 == len(out) {
+			continue
+		}
+
+		switch out[i+1] {
+		case '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case '*':
+			start := i
+			for i += 2; i+1 < len(out) && !(out[i] == '*' && out[i+1] == '/'); i++ {
+			}
+			if i+1 >= len(out) {
+				return nil, fmt.Errorf("unterminated comment at offset %d", start)
+			}
+			for j := start; j <= i+1; j++ {
+				if out[j] != '\n' && out[j] != '\r' {
+					out[j] = ' '
+				}
+			}
+			i++
+		}
+	}
+	return out, nil
+}
+
+// stripTrailingCommas gives a copy of data with commas that directly
+// precede the end of an array or object, ignoring whitespace, replaced by
+// spaces. Commas that don't follow an element, eg: [,], are kept
+func stripTrailingCommas(data []byte) []byte {
+	out := make([]byte, len(data))
+	copy(out, data)
+
+	inString := false
+	comma := -1
+	var prev byte
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case ']', '}':
+			if comma >= 0 {
+				out[comma] = ' '
+			}
+		case '"':
+			inString = true
+		}
+		comma = -1
+		if c == ',' && prev != '[' && prev != '{' && prev != ',' {
+			comma = i
+		}
+		prev = c
+	}
+	return out
+}
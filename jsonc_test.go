@@ -0,0 +1,107 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseJSONC(t *testing.T) {
+	withComments := `// a person
+{
+	/* the display name,
+	   shown to other users */
+	"title": "Person // not a comment",
+	"type": "object", // trailing comment
+	"properties": {
+		"url": { "type": "string", "pattern": "^https?://" }, /* block */
+		"glob": { "type": "string", "default": "/* not a comment */" },
+		"quote": { "type": "string", "default": "\"// still a string\"" }
+	}
+	/**/
+}
+// done`
+	plain := `{
+	"title": "Person // not a comment",
+	"type": "object",
+	"properties": {
+		"url": { "type": "string", "pattern": "^https?://" },
+		"glob": { "type": "string", "default": "/* not a comment */" },
+		"quote": { "type": "string", "default": "\"// still a string\"" }
+	}
+}`
+
+	rs, err := ParseJSONC([]byte(withComments))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := json.Marshal(Must(plain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g, e interface{}
+	json.Unmarshal(got, &g)
+	json.Unmarshal(expect, &e)
+	if !reflect.DeepEqual(e, g) {
+		t.Errorf("expected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	errs, err := rs.ValidateBytes([]byte(`{ "url": "ftp://example.com" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected the pattern to be parsed intact, got errors: %v", errs)
+	}
+
+	if _, err := ParseJSONC([]byte(`{ "type": "string" /* unterminated }`)); err == nil {
+		t.Errorf("expected an unterminated comment to be an error")
+	}
+}
+
+func TestParseAllowTrailingCommas(t *testing.T) {
+	data := `{
+		"type": "object",
+		"properties": {
+			"tags": { "type": "array", "items": { "enum": ["a,]", "b,}",], }, },
+			"n": { "enum": [1, 2, [3,],], },
+		},
+		"required": ["tags",],
+	}`
+
+	if _, err := ParseWithOptions([]byte(data), ParseOptions{}); err == nil {
+		t.Errorf("expected trailing commas to be an error by default")
+	}
+	rs, err := ParseWithOptions([]byte(data), ParseOptions{AllowTrailingCommas: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		doc   string
+		valid bool
+	}{
+		{`{ "tags": ["a,]", "b,}"], "n": [3] }`, true},
+		{`{ "tags": ["a"] }`, false},
+		{`{ "n": 1 }`, false},
+	}
+	for i, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (len(errs) == 0) != c.valid {
+			t.Errorf("case %d: expected %s to be valid: %t, got errors: %v", i, c.doc, c.valid, errs)
+		}
+	}
+
+	for _, str := range []string{`[,]`, `{ "type": "string",, }`} {
+		if _, err := ParseWithOptions([]byte(str), ParseOptions{AllowTrailingCommas: true}); err == nil {
+			t.Errorf("expected %s to be an error", str)
+		}
+	}
+}
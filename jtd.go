@@ -0,0 +1,518 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qri-io/jsonpointer"
+)
+
+// JTDSchema is a JSON Type Definition schema, the simpler schema language
+// of RFC 8927. It's validated separately from JSON Schema, but reports the
+// same ValErrors: PropertyPath is the instance path of an error, and
+// SchemaPath the JTD schema path as a URI fragment, eg:
+// "#/properties/name/type"
+// https://tools.ietf.org/html/rfc8927
+type JTDSchema struct {
+	// Definitions holds the schemas "ref" can name, only the root schema
+	// has definitions
+	Definitions map[string]*JTDSchema
+	// Nullable accepts null in addition to what the schema's form accepts
+	Nullable bool
+	// Metadata holds annotations that don't affect validation
+	Metadata map[string]interface{}
+
+	// Ref names the definition the instance must be valid against
+	Ref string
+	// Type is the type of the instance, one of "boolean", "string",
+	// "timestamp", "float32", "float64", "int8", "uint8", "int16",
+	// "uint16", "int32", or "uint32"
+	Type string
+	// Enum lists the strings the instance can be
+	Enum []string
+	// Elements is the schema of the elements of an array instance
+	Elements *JTDSchema
+	// Properties and OptionalProperties are the schemas of the properties
+	// of an object instance, properties are required. Other properties are
+	// only allowed with AdditionalProperties
+	Properties           map[string]*JTDSchema
+	OptionalProperties   map[string]*JTDSchema
+	AdditionalProperties bool
+	// Values is the schema of the property values of an object instance
+	Values *JTDSchema
+	// Discriminator is the property of an object instance that selects the
+	// schema in Mapping the rest of the instance must be valid against
+	Discriminator string
+	Mapping       map[string]*JTDSchema
+
+	form jtdForm
+	// path is the location of the schema within its root
+	path string
+	root *JTDSchema
+}
+
+// jtdForm is the form of a JTDSchema, the kind of values it describes
+type jtdForm int
+
+const (
+	jtdEmpty jtdForm = iota
+	jtdRef
+	jtdType
+	jtdEnum
+	jtdElements
+	jtdProperties
+	jtdValues
+	jtdDiscriminator
+)
+
+// jtdForms maps keywords to the forms they declare
+var jtdForms = map[string]jtdForm{
+	"ref":                jtdRef,
+	"type":               jtdType,
+	"enum":               jtdEnum,
+	"elements":           jtdElements,
+	"properties":         jtdProperties,
+	"optionalProperties": jtdProperties,
+	"values":             jtdValues,
+	"discriminator":      jtdDiscriminator,
+}
+
+// jtdIntRanges gives the bounds of the integer types
+var jtdIntRanges = map[string][2]float64{
+	"int8":   {math.MinInt8, math.MaxInt8},
+	"uint8":  {0, math.MaxUint8},
+	"int16":  {math.MinInt16, math.MaxInt16},
+	"uint16": {0, math.MaxUint16},
+	"int32":  {math.MinInt32, math.MaxInt32},
+	"uint32": {0, math.MaxUint32},
+}
+
+// ParseJTD parses json byte data into a JTDSchema, checking it's a valid
+// schema as RFC 8927 defines, eg: that it doesn't mix forms, and that every
+// "ref" names a definition
+func ParseJTD(data []byte) (*JTDSchema, error) {
+	var raw interface{}
+	if err := DefaultDecoder.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
+	}
+	s := &JTDSchema{}
+	if err := s.parse(raw, "", s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// jtdSchemaError describes a schema that isn't valid JTD
+func jtdSchemaError(path, msg string) error {
+	return fmt.Errorf("invalid JTD schema at %q: %s", "#"+path, msg)
+}
+
+// parse populates s from the decoded json raw found at path within root
+func (s *JTDSchema) parse(raw interface{}, path string, root *JTDSchema) error {
+	s.path, s.root = path, root
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return jtdSchemaError(path, "schema must be an object")
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	formKey := ""
+	for _, key := range keys {
+		form, ok := jtdForms[key]
+		switch {
+		case ok && s.form != jtdEmpty && s.form != form:
+			return jtdSchemaError(path, fmt.Sprintf("%q and %q can't be used together", formKey, key))
+		case ok:
+			s.form, formKey = form, key
+		case key == "definitions" && s != root:
+			return jtdSchemaError(path, `only the root schema can have "definitions"`)
+		case key == "additionalProperties" && obj["properties"] == nil && obj["optionalProperties"] == nil:
+			return jtdSchemaError(path, `"additionalProperties" requires "properties" or "optionalProperties"`)
+		case key == "mapping" && obj["discriminator"] == nil:
+			return jtdSchemaError(path, `"mapping" requires "discriminator"`)
+		case key != "definitions" && key != "nullable" && key != "metadata" && key != "additionalProperties" && key != "mapping":
+			return jtdSchemaError(path, fmt.Sprintf("unknown keyword %q", key))
+		}
+	}
+
+	var err error
+	if v, ok := obj["nullable"]; ok {
+		if s.Nullable, ok = v.(bool); !ok {
+			return jtdSchemaError(path, `"nullable" must be a boolean`)
+		}
+	}
+	if v, ok := obj["metadata"]; ok {
+		if s.Metadata, ok = v.(map[string]interface{}); !ok {
+			return jtdSchemaError(path, `"metadata" must be an object`)
+		}
+	}
+	if v, ok := obj["definitions"]; ok {
+		if s.Definitions, err = parseJTDMap(v, path+"/definitions", root); err != nil {
+			return err
+		}
+	}
+
+	switch s.form {
+	case jtdRef:
+		if s.Ref, ok = obj["ref"].(string); !ok {
+			return jtdSchemaError(path, `"ref" must be a string`)
+		}
+	case jtdType:
+		s.Type, _ = obj["type"].(string)
+		if _, ok := jtdIntRanges[s.Type]; !ok && s.Type != "boolean" && s.Type != "string" && s.Type != "timestamp" && s.Type != "float32" && s.Type != "float64" {
+			return jtdSchemaError(path, fmt.Sprintf("unknown type %v", obj["type"]))
+		}
+	case jtdEnum:
+		vals, _ := obj["enum"].([]interface{})
+		if len(vals) == 0 {
+			return jtdSchemaError(path, `"enum" must be a non-empty array of strings`)
+		}
+		seen := map[string]bool{}
+		for _, v := range vals {
+			str, ok := v.(string)
+			if !ok {
+				return jtdSchemaError(path, `"enum" must be a non-empty array of strings`)
+			}
+			if seen[str] {
+				return jtdSchemaError(path, fmt.Sprintf("duplicate enum value %q", str))
+			}
+			seen[str] = true
+			s.Enum = append(s.Enum, str)
+		}
+	case jtdElements:
+		s.Elements = &JTDSchema{}
+		if err := s.Elements.parse(obj["elements"], path+"/elements", root); err != nil {
+			return err
+		}
+	case jtdProperties:
+		if v, ok := obj["properties"]; ok {
+			if s.Properties, err = parseJTDMap(v, path+"/properties", root); err != nil {
+				return err
+			}
+		}
+		if v, ok := obj["optionalProperties"]; ok {
+			if s.OptionalProperties, err = parseJTDMap(v, path+"/optionalProperties", root); err != nil {
+				return err
+			}
+		}
+		for key := range s.OptionalProperties {
+			if _, ok := s.Properties[key]; ok {
+				return jtdSchemaError(path, fmt.Sprintf("property %q is both required and optional", key))
+			}
+		}
+		if v, ok := obj["additionalProperties"]; ok {
+			if s.AdditionalProperties, ok = v.(bool); !ok {
+				return jtdSchemaError(path, `"additionalProperties" must be a boolean`)
+			}
+		}
+	case jtdValues:
+		s.Values = &JTDSchema{}
+		if err := s.Values.parse(obj["values"], path+"/values", root); err != nil {
+			return err
+		}
+	case jtdDiscriminator:
+		if s.Discriminator, ok = obj["discriminator"].(string); !ok {
+			return jtdSchemaError(path, `"discriminator" must be a string`)
+		}
+		if _, ok := obj["mapping"]; !ok {
+			return jtdSchemaError(path, `"discriminator" requires "mapping"`)
+		}
+		if s.Mapping, err = parseJTDMap(obj["mapping"], path+"/mapping", root); err != nil {
+			return err
+		}
+		for key, m := range s.Mapping {
+			mpath := path + "/mapping/" + pointerTokenEscaper.Replace(key)
+			if m.form != jtdProperties || m.Nullable {
+				return jtdSchemaError(mpath, "mapping values must be non-nullable properties schemas")
+			}
+			_, required := m.Properties[s.Discriminator]
+			_, optional := m.OptionalProperties[s.Discriminator]
+			if required || optional {
+				return jtdSchemaError(mpath, fmt.Sprintf("mapping values can't define the discriminator %q", s.Discriminator))
+			}
+		}
+	}
+
+	if s == root {
+		// refs of the root schema are checked once its definitions are known
+		return s.checkRefs()
+	}
+	return nil
+}
+
+// parseJTDMap parses an object of schemas found at path
+func parseJTDMap(raw interface{}, path string, root *JTDSchema) (map[string]*JTDSchema, error) {
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, jtdSchemaError(path, "must be an object of schemas")
+	}
+	schemas := make(map[string]*JTDSchema, len(obj))
+	for key, v := range obj {
+		sch := &JTDSchema{}
+		if err := sch.parse(v, path+"/"+pointerTokenEscaper.Replace(key), root); err != nil {
+			return nil, err
+		}
+		schemas[key] = sch
+	}
+	return schemas, nil
+}
+
+// checkRefs checks the refs of the root schema name definitions. Refs
+// within definitions can't be checked while definitions are being parsed
+func (s *JTDSchema) checkRefs() error {
+	var check func(sch *JTDSchema) error
+	check = func(sch *JTDSchema) error {
+		if sch.form == jtdRef {
+			if _, ok := s.Definitions[sch.Ref]; !ok {
+				return jtdSchemaError(sch.path, fmt.Sprintf("no definition named %q", sch.Ref))
+			}
+		}
+		for _, sub := range sch.subschemas() {
+			if err := check(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return check(s)
+}
+
+// subschemas lists the schemas directly within s
+func (s *JTDSchema) subschemas() []*JTDSchema {
+	subs := []*JTDSchema{}
+	for _, m := range []map[string]*JTDSchema{s.Definitions, s.Properties, s.OptionalProperties, s.Mapping} {
+		for _, sch := range m {
+			subs = append(subs, sch)
+		}
+	}
+	for _, sch := range []*JTDSchema{s.Elements, s.Values} {
+		if sch != nil {
+			subs = append(subs, sch)
+		}
+	}
+	return subs
+}
+
+// ValidateBytes performs JTD validation against a slice of json byte data
+func (s *JTDSchema) ValidateBytes(data []byte) ([]ValError, error) {
+	var doc interface{}
+	errs := []ValError{}
+	if err := DefaultDecoder.Unmarshal(data, &doc); err != nil {
+		return errs, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
+	}
+	s.Validate("/", doc, &errs)
+	return errs, nil
+}
+
+// Validate checks the decoded json value data found at propPath against s,
+// adding an error for each of the error indicators RFC 8927 defines
+func (s *JTDSchema) Validate(propPath string, data interface{}, errs *[]ValError) {
+	s.validate(propPath, data, errs, "")
+}
+
+// validate checks data against s. tag is the discriminator of a mapping
+// schema, which properties schemas allow in addition to their own
+func (s *JTDSchema) validate(propPath string, data interface{}, errs *[]ValError, tag string) {
+	if data == nil && s.Nullable {
+		return
+	}
+
+	switch s.form {
+	case jtdRef:
+		s.root.Definitions[s.Ref].validate(propPath, data, errs, "")
+	case jtdType:
+		if !jtdTypeMatches(s.Type, data) {
+			s.addError(errs, propPath, data, "type", fmt.Sprintf("type should be %s", s.Type))
+		}
+	case jtdEnum:
+		str, ok := data.(string)
+		for _, v := range s.Enum {
+			if ok && v == str {
+				return
+			}
+		}
+		allowed, _ := DefaultEncoder.Marshal(s.Enum)
+		s.addError(errs, propPath, data, "enum", fmt.Sprintf("should be one of %s", allowed))
+	case jtdElements:
+		arr, ok := data.([]interface{})
+		if !ok {
+			s.addError(errs, propPath, data, "elements", "type should be array")
+			return
+		}
+		jp, _ := jsonpointer.Parse(propPath)
+		for i, elem := range arr {
+			d, _ := jp.Descendant(strconv.Itoa(i))
+			s.Elements.validate(d.String(), elem, errs, "")
+		}
+	case jtdProperties:
+		obj, ok := data.(map[string]interface{})
+		if !ok {
+			keyword := "properties"
+			if s.Properties == nil {
+				keyword = "optionalProperties"
+			}
+			s.addError(errs, propPath, data, keyword, "type should be object")
+			return
+		}
+		jp, _ := jsonpointer.Parse(propPath)
+		for _, key := range sortedKeys(s.Properties) {
+			val, ok := obj[key]
+			if !ok {
+				s.addError(errs, propPath, data, "properties/"+pointerTokenEscaper.Replace(key), fmt.Sprintf("%q value is required", key))
+				continue
+			}
+			d, _ := jp.Descendant(pointerTokenEscaper.Replace(key))
+			s.Properties[key].validate(d.String(), val, errs, "")
+		}
+		for _, key := range sortedKeys(s.OptionalProperties) {
+			if val, ok := obj[key]; ok {
+				d, _ := jp.Descendant(pointerTokenEscaper.Replace(key))
+				s.OptionalProperties[key].validate(d.String(), val, errs, "")
+			}
+		}
+		if s.AdditionalProperties {
+			return
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			_, required := s.Properties[key]
+			_, optional := s.OptionalProperties[key]
+			if !required && !optional && key != tag {
+				d, _ := jp.Descendant(pointerTokenEscaper.Replace(key))
+				*errs = append(*errs, ValError{
+					PropertyPath: d.String(),
+					InvalidValue: obj[key],
+					SchemaPath:   "#" + s.path,
+					Keyword:      "additionalProperties",
+					Message:      fmt.Sprintf("additional property %q is not allowed", key),
+				})
+			}
+		}
+	case jtdValues:
+		obj, ok := data.(map[string]interface{})
+		if !ok {
+			s.addError(errs, propPath, data, "values", "type should be object")
+			return
+		}
+		jp, _ := jsonpointer.Parse(propPath)
+		for _, key := range sortedKeys(obj) {
+			d, _ := jp.Descendant(pointerTokenEscaper.Replace(key))
+			s.Values.validate(d.String(), obj[key], errs, "")
+		}
+	case jtdDiscriminator:
+		obj, ok := data.(map[string]interface{})
+		if !ok {
+			s.addError(errs, propPath, data, "discriminator", "type should be object")
+			return
+		}
+		val, ok := obj[s.Discriminator]
+		if !ok {
+			s.addError(errs, propPath, data, "discriminator", fmt.Sprintf("%q value is required", s.Discriminator))
+			return
+		}
+		jp, _ := jsonpointer.Parse(propPath)
+		d, _ := jp.Descendant(pointerTokenEscaper.Replace(s.Discriminator))
+		str, ok := val.(string)
+		if !ok {
+			s.addError(errs, d.String(), val, "discriminator", "type should be string")
+			return
+		}
+		m, ok := s.Mapping[str]
+		if !ok {
+			allowed, _ := DefaultEncoder.Marshal(sortedKeys(s.Mapping))
+			s.addError(errs, d.String(), val, "mapping", fmt.Sprintf("should be one of %s", allowed))
+			return
+		}
+		m.validate(propPath, data, errs, s.Discriminator)
+	}
+}
+
+// addError adds an error for the keyword of s, which may be followed by
+// further tokens of the schema path
+func (s *JTDSchema) addError(errs *[]ValError, propPath string, data interface{}, keyword, msg string) {
+	*errs = append(*errs, ValError{
+		PropertyPath: propPath,
+		InvalidValue: data,
+		SchemaPath:   "#" + s.path + "/" + keyword,
+		Keyword:      strings.SplitN(keyword, "/", 2)[0],
+		Message:      msg,
+	})
+}
+
+// sortedKeys gives the keys of an object in sorted order
+func sortedKeys(obj interface{}) []string {
+	keys := []string{}
+	switch m := obj.(type) {
+	case map[string]*JTDSchema:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	case map[string]interface{}:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jtdTypeMatches reports whether data is of the JTD type typ
+func jtdTypeMatches(typ string, data interface{}) bool {
+	switch typ {
+	case "boolean":
+		_, ok := data.(bool)
+		return ok
+	case "string":
+		_, ok := data.(string)
+		return ok
+	case "timestamp":
+		str, ok := data.(string)
+		return ok && isJTDTimestamp(str)
+	}
+
+	var num float64
+	switch v := data.(type) {
+	case float64:
+		num = v
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return false
+		}
+		num = f
+	default:
+		return false
+	}
+	bounds, ok := jtdIntRanges[typ]
+	if !ok {
+		// float32 and float64 accept any number
+		return true
+	}
+	return num == math.Trunc(num) && num >= bounds[0] && num <= bounds[1]
+}
+
+// isJTDTimestamp reports whether str is an RFC 3339 timestamp. Unlike
+// time.Parse it accepts leap seconds, eg: "1990-12-31T23:59:60Z"
+func isJTDTimestamp(str string) bool {
+	if _, err := time.Parse(time.RFC3339, str); err == nil {
+		return true
+	}
+	if len(str) > 19 && str[17:19] == "60" {
+		_, err := time.Parse(time.RFC3339, str[:17]+"59"+str[19:])
+		return err == nil
+	}
+	return false
+}
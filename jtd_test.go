@@ -0,0 +1,110 @@
+package jsonschema
+
+import (
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// jtdSuiteCase is a case of testdata/jtd/validation.json, in the format
+// of the JTD test suite
+type jtdSuiteCase struct {
+	Schema   interface{} `json:"schema"`
+	Instance interface{} `json:"instance"`
+	Errors   []struct {
+		InstancePath []string `json:"instancePath"`
+		SchemaPath   []string `json:"schemaPath"`
+	} `json:"errors"`
+}
+
+// jtdPointer joins tokens into a pointer as found in ValErrors
+func jtdPointer(tokens []string) string {
+	escaped := make([]string, len(tokens))
+	for i, t := range tokens {
+		escaped[i] = pointerTokenEscaper.Replace(t)
+	}
+	return "/" + strings.Join(escaped, "/")
+}
+
+func TestJTDValidation(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/jtd/validation.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]jtdSuiteCase{}
+	if err := DefaultDecoder.Unmarshal(data, &cases); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, c := range cases {
+		schema, _ := DefaultEncoder.Marshal(c.Schema)
+		s, err := ParseJTD(schema)
+		if err != nil {
+			t.Errorf("%s: %s", name, err.Error())
+			continue
+		}
+		errs := []ValError{}
+		s.Validate("/", c.Instance, &errs)
+
+		want := []string{}
+		for _, e := range c.Errors {
+			want = append(want, jtdPointer(e.InstancePath)+" "+strings.TrimSuffix("#"+jtdPointer(e.SchemaPath), "/"))
+		}
+		got := []string{}
+		for _, e := range errs {
+			got = append(got, e.PropertyPath+" "+e.SchemaPath)
+		}
+		sort.Strings(want)
+		sort.Strings(got)
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: expected errors:\n%s\ngot:\n%s", name, strings.Join(want, "\n"), strings.Join(got, "\n"))
+		}
+	}
+}
+
+func TestJTDInvalidSchemas(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/jtd/invalid_schemas.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]interface{}{}
+	if err := DefaultDecoder.Unmarshal(data, &cases); err != nil {
+		t.Fatal(err)
+	}
+	for name, schema := range cases {
+		data, _ := DefaultEncoder.Marshal(schema)
+		if _, err := ParseJTD(data); err == nil {
+			t.Errorf("%s: expected an error parsing %s", name, data)
+		}
+	}
+}
+
+func TestJTDErrors(t *testing.T) {
+	s, err := ParseJTD([]byte(`{
+		"properties": {
+			"name": { "type": "string" },
+			"role": { "enum": ["admin", "user"] }
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, err := s.ValidateBytes([]byte(`{"role": "guest", "age": 5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := strings.Join([]string{
+		`/: "name" value is required`,
+		`/age: additional property "age" is not allowed`,
+		`/role: should be one of ["admin","user"]`,
+	}, "\n")
+	if got := errorLines(errs); got != expect {
+		t.Errorf("expected errors:\n%s\ngot:\n%s", expect, got)
+	}
+
+	_, err = ParseJTD([]byte(`{"properties": {"a": {"type": "string", "enum": ["a"]}}}`))
+	if expect := `invalid JTD schema at "#/properties/a": "enum" and "type" can't be used together`; err == nil || err.Error() != expect {
+		t.Errorf("expected error %q, got: %v", expect, err)
+	}
+}
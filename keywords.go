@@ -1,9 +1,9 @@
 package jsonschema
 
 import (
-	"github.com/json-iterator/go"
+	"encoding/json"
 	"fmt"
-	"reflect"
+	"math/big"
 	"strconv"
 	"strings"
 )
@@ -20,7 +20,9 @@ var primitiveTypes = map[string]bool{
 }
 
 // DataType gives the primitive json type of a standard json-decoded value, plus the special case
-// "integer" for when numbers are whole
+// "integer" for when numbers are whole. json.Numbers, as decoded with
+// UseNumber, are numbers. Values implementing json.Marshaler,
+// like time.Time, have the type of the JSON they marshal to
 func DataType(data interface{}) string {
 	switch v := data.(type) {
 	case nil:
@@ -32,17 +34,66 @@ func DataType(data interface{}) string {
 			return "integer"
 		}
 		return "number"
+	case json.Number:
+		n, ok := new(big.Rat).SetString(string(v))
+		if !ok {
+			return "unknown"
+		}
+		if n.IsInt() {
+			return "integer"
+		}
+		return "number"
 	case string:
 		return "string"
 	case []interface{}:
 		return "array"
 	case map[string]interface{}:
 		return "object"
+	case json.Marshaler:
+		var decoded interface{}
+		if normalizeValue(v, &decoded) != nil {
+			return "unknown"
+		}
+		return DataType(decoded)
 	default:
 		return "unknown"
 	}
 }
 
+// normalizeValue converts a go value to its json-decoded form by
+// marshaling it to JSON and decoding the result into dst
+func normalizeValue(value interface{}, dst *interface{}) error {
+	data, err := DefaultEncoder.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return DefaultDecoder.Unmarshal(data, dst)
+}
+
+// isDecoded reports whether value is made up only of the types json
+// decodes into, so it needs no normalizing
+func isDecoded(value interface{}) bool {
+	switch v := value.(type) {
+	case nil, bool, float64, json.Number, string:
+		return true
+	case []interface{}:
+		for _, elem := range v {
+			if !isDecoded(elem) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		for _, val := range v {
+			if !isDecoded(val) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
 // Type specifies one of the six json primitive types.
 // The value of this keyword MUST be either a string or an array.
 // If it is an array, elements of the array MUST be strings and MUST be unique.
@@ -53,6 +104,9 @@ type Type struct {
 	BaseValidator
 	strVal bool // set to true if Type decoded from a string, false if an array
 	vals   []string
+	// draft4 is set in draft-04 schemas, where json.Numbers written with a
+	// fraction or exponent aren't integers
+	draft4 bool
 }
 
 // NewType creates a new Type Validator
@@ -68,14 +122,25 @@ func (t Type) String() string {
 	return strings.Join(t.vals, ",")
 }
 
-// Validate checks to see if input data satisfies the type constraint
-func (t Type) Validate(propPath string, data interface{}, errs *[]ValError) {
+// allows reports whether data is one of the types of t
+func (t Type) allows(data interface{}) bool {
 	jt := DataType(data)
+	if num, ok := data.(json.Number); ok && t.draft4 && strings.ContainsAny(string(num), ".eE") {
+		jt = "number"
+	}
 	for _, typestr := range t.vals {
 		if jt == typestr || jt == "integer" && typestr == "number" {
-			return
+			return true
 		}
 	}
+	return false
+}
+
+// Validate checks to see if input data satisfies the type constraint
+func (t Type) Validate(propPath string, data interface{}, errs *[]ValError) {
+	if t.allows(data) {
+		return
+	}
 	if len(t.vals) == 1 {
 		t.AddError(errs, propPath, data, fmt.Sprintf(`type should be %s`, t.vals[0]))
 		return
@@ -95,7 +160,7 @@ func (t Type) JSONProp(name string) interface{} {
 	if err != nil {
 		return nil
 	}
-	if idx > len(t.vals) || idx < 0 {
+	if idx >= len(t.vals) || idx < 0 {
 		return nil
 	}
 	return t.vals[idx]
@@ -104,11 +169,11 @@ func (t Type) JSONProp(name string) interface{} {
 // UnmarshalJSON implements the jsoniter.Unmarshaler interface for Type
 func (t *Type) UnmarshalJSON(data []byte) error {
 	var single string
-	if err := jsoniter.Unmarshal(data, &single); err == nil {
+	if err := DefaultDecoder.Unmarshal(data, &single); err == nil {
 		*t = Type{strVal: true, vals: []string{single}}
 	} else {
 		var set []string
-		if err := jsoniter.Unmarshal(data, &set); err == nil {
+		if err := DefaultDecoder.Unmarshal(data, &set); err == nil {
 			*t = Type{vals: set}
 		} else {
 			return err
@@ -126,9 +191,9 @@ func (t *Type) UnmarshalJSON(data []byte) error {
 // MarshalJSON implements the jsoniter.Marshaler interface for Type
 func (t Type) MarshalJSON() ([]byte, error) {
 	if t.strVal {
-		return jsoniter.Marshal(t.vals[0])
+		return DefaultEncoder.Marshal(t.vals[0])
 	}
-	return jsoniter.Marshal(t.vals)
+	return DefaultEncoder.Marshal(t.vals)
 }
 
 // Enum validates successfully against this keyword if its value is equal to one of the
@@ -158,6 +223,13 @@ func (e Enum) Path() string {
 
 // Validate implements the Validator interface for Enum
 func (e Enum) Validate(propPath string, data interface{}, errs *[]ValError) {
+	e.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for Enum. With
+// ValidateOptions.Suggestions set, an invalid string is given the closest
+// allowed string as a suggestion
+func (e Enum) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	for _, v := range e {
 		test := &[]ValError{}
 		v.Validate(propPath, data, test)
@@ -166,7 +238,74 @@ func (e Enum) Validate(propPath string, data interface{}, errs *[]ValError) {
 		}
 	}
 
-	AddError(errs, propPath, data, fmt.Sprintf("should be one of %s", e.String()))
+	msg := fmt.Sprintf("should be one of %s", e.String())
+	if str, ok := data.(string); ok && vc.Options.Suggestions {
+		if suggestion, ok := e.closest(str); ok {
+			*errs = append(*errs, ValError{
+				PropertyPath: propPath,
+				InvalidValue: data,
+				Message:      fmt.Sprintf("%s, did you mean %q?", msg, suggestion),
+				Params:       map[string]interface{}{"suggestion": suggestion},
+			})
+			return
+		}
+	}
+	AddError(errs, propPath, data, msg)
+}
+
+// closest finds the allowed string with the smallest edit distance to str.
+// ok is false if there are no allowed strings, or every one of them would
+// need to be entirely rewritten
+func (e Enum) closest(str string) (closest string, ok bool) {
+	best := -1
+	for _, c := range e {
+		var allowed string
+		if err := DefaultDecoder.Unmarshal(c, &allowed); err != nil {
+			continue
+		}
+		dist := editDistance(str, allowed)
+		if dist >= len([]rune(allowed)) {
+			continue
+		}
+		if best == -1 || dist < best {
+			best, closest = dist, allowed
+		}
+	}
+	return closest, best != -1
+}
+
+// editDistance gives the levenshtein distance between two strings, the
+// number of single rune insertions, deletions, and substitutions needed to
+// turn a into b
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(br)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
 }
 
 // JSONProp implements JSON property name indexing for Enum
@@ -175,7 +314,7 @@ func (e Enum) JSONProp(name string) interface{} {
 	if err != nil {
 		return nil
 	}
-	if idx > len(e) || idx < 0 {
+	if idx >= len(e) || idx < 0 {
 		return nil
 	}
 	return e[idx]
@@ -193,7 +332,7 @@ func (e Enum) JSONChildren() (res map[string]JSONPather) {
 // Const MAY be of any type, including null.
 // An instance validates successfully against this keyword if its
 // value is equal to the value of the keyword.
-type Const jsoniter.RawMessage
+type Const json.RawMessage
 
 // NewConst creates a new Const Validator
 func NewConst() Validator {
@@ -208,12 +347,12 @@ func (c Const) Path() string {
 // Validate implements the validate interface for Const
 func (c Const) Validate(propPath string, data interface{}, errs *[]ValError) {
 	var con interface{}
-	if err := jsoniter.Unmarshal(c, &con); err != nil {
+	if err := DefaultDecoder.Unmarshal(c, &con); err != nil {
 		AddError(errs, propPath, data, err.Error())
 		return
 	}
 
-	if !reflect.DeepEqual(con, data) {
+	if !equalJSON(con, data) {
 		AddError(errs, propPath, data, fmt.Sprintf(`must equal %s`, InvalidValueString(con)))
 	}
 }
@@ -236,5 +375,36 @@ func (c *Const) UnmarshalJSON(data []byte) error {
 
 // MarshalJSON implements jsoniter.Marshaler for Const
 func (c Const) MarshalJSON() ([]byte, error) {
-	return jsoniter.Marshal(jsoniter.RawMessage(c))
+	return DefaultEncoder.Marshal(json.RawMessage(c))
+}
+
+// Deprecated indicates applications should refrain from using the
+// instance, eg: a property slated for removal. It's an annotation unless
+// ValidateOptions.WarnDeprecated is set, in which case instances of a
+// deprecated schema produce a warning, which never invalidates them
+type Deprecated bool
+
+// NewDeprecated creates a new Deprecated validator
+func NewDeprecated() Validator {
+	return new(Deprecated)
+}
+
+// Validate implements the Validator interface for Deprecated. Without a
+// validation context no warning is produced
+func (d Deprecated) Validate(propPath string, data interface{}, errs *[]ValError) {}
+
+// ValidateContext implements the ContextValidator interface for Deprecated
+func (d Deprecated) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
+	if !bool(d) || !vc.Options.WarnDeprecated {
+		return
+	}
+	msg := "value is deprecated"
+	if _, ok := vc.Parent.(map[string]interface{}); ok {
+		msg = "property is deprecated"
+	}
+	*errs = append(*errs, ValError{
+		PropertyPath: propPath,
+		Message:      msg,
+		Severity:     SeverityWarning,
+	})
 }
@@ -1,9 +1,7 @@
 package jsonschema
 
 import (
-	"github.com/json-iterator/go"
 	"fmt"
-	"reflect"
 	"strconv"
 
 	"github.com/qri-io/jsonpointer"
@@ -31,22 +29,35 @@ func NewItems() Validator {
 
 // Validate implements the Validator interface for Items
 func (it Items) Validate(propPath string, data interface{}, errs *[]ValError) {
+	it.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for Items
+func (it Items) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	jp, err := jsonpointer.Parse(propPath)
 	if err != nil {
 		AddError(errs, propPath, nil, fmt.Sprintf("invalid property path: %s", err.Error()))
 	}
 
 	if arr, ok := data.([]interface{}); ok {
+		parent, parentPath := vc.enter(arr, propPath)
+		defer vc.leave(parent, parentPath)
 		if it.single {
 			for i, elem := range arr {
 				d, _ := jp.Descendant(strconv.Itoa(i))
-				it.Schemas[0].Validate(d.String(), elem, errs)
+				it.Schemas[0].ValidateContext(vc, d.String(), elem, errs)
+				vc.evaluateItem(propPath, i)
+				vc.reportProgress(propPath, i+1, len(arr))
 			}
 		} else {
 			for i, vs := range it.Schemas {
 				if i < len(arr) {
 					d, _ := jp.Descendant(strconv.Itoa(i))
-					vs.Validate(d.String(), arr[i], errs)
+					prev := vc.enterSchema(strconv.Itoa(i))
+					vs.ValidateContext(vc, d.String(), arr[i], errs)
+					vc.leaveSchema(prev)
+					vc.evaluateItem(propPath, i)
+					vc.reportProgress(propPath, i+1, len(arr))
 				}
 			}
 		}
@@ -59,7 +70,7 @@ func (it Items) JSONProp(name string) interface{} {
 	if err != nil {
 		return nil
 	}
-	if idx > len(it.Schemas) || idx < 0 {
+	if idx >= len(it.Schemas) || idx < 0 {
 		return nil
 	}
 	return it.Schemas[idx]
@@ -77,12 +88,12 @@ func (it Items) JSONChildren() (res map[string]JSONPather) {
 // UnmarshalJSON implements the jsoniter.Unmarshaler interface for Items
 func (it *Items) UnmarshalJSON(data []byte) error {
 	s := &Schema{}
-	if err := jsoniter.Unmarshal(data, s); err == nil {
+	if err := DefaultDecoder.Unmarshal(data, s); err == nil {
 		*it = Items{single: true, Schemas: []*Schema{s}}
 		return nil
 	}
 	ss := []*Schema{}
-	if err := jsoniter.Unmarshal(data, &ss); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, &ss); err != nil {
 		return err
 	}
 	*it = Items{Schemas: ss}
@@ -92,9 +103,9 @@ func (it *Items) UnmarshalJSON(data []byte) error {
 // MarshalJSON implements the jsoniter.Marshaler interface for Items
 func (it Items) MarshalJSON() ([]byte, error) {
 	if it.single {
-		return jsoniter.Marshal(it.Schemas[0])
+		return DefaultEncoder.Marshal(it.Schemas[0])
 	}
-	return jsoniter.Marshal([]*Schema(it.Schemas))
+	return DefaultEncoder.Marshal([]*Schema(it.Schemas))
 }
 
 // AdditionalItems determines how child instances validate for arrays, and does not directly validate the immediate
@@ -115,6 +126,11 @@ func NewAdditionalItems() Validator {
 
 // Validate implements the Validator interface for AdditionalItems
 func (a *AdditionalItems) Validate(propPath string, data interface{}, errs *[]ValError) {
+	a.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for AdditionalItems
+func (a *AdditionalItems) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	jp, err := jsonpointer.Parse(propPath)
 	if err != nil {
 		AddError(errs, propPath, nil, fmt.Sprintf("invalid property path: %s", err.Error()))
@@ -122,12 +138,16 @@ func (a *AdditionalItems) Validate(propPath string, data interface{}, errs *[]Va
 
 	if a.startIndex >= 0 {
 		if arr, ok := data.([]interface{}); ok {
+			parent, parentPath := vc.enter(arr, propPath)
+			defer vc.leave(parent, parentPath)
 			for i, elem := range arr {
 				if i < a.startIndex {
 					continue
 				}
 				d, _ := jp.Descendant(strconv.Itoa(i))
-				a.Schema.Validate(d.String(), elem, errs)
+				a.Schema.ValidateContext(vc, d.String(), elem, errs)
+				vc.evaluateItem(propPath, i)
+				vc.reportProgress(propPath, i+1, len(arr))
 			}
 		}
 	}
@@ -149,7 +169,7 @@ func (a *AdditionalItems) JSONChildren() (res map[string]JSONPather) {
 // UnmarshalJSON implements the jsoniter.Unmarshaler interface for AdditionalItems
 func (a *AdditionalItems) UnmarshalJSON(data []byte) error {
 	sch := &Schema{}
-	if err := jsoniter.Unmarshal(data, sch); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, sch); err != nil {
 		return err
 	}
 	// begin with -1 as default index to prevent AdditionalItems from evaluating
@@ -210,17 +230,36 @@ func NewUniqueItems() Validator {
 
 // Validate implements the Validator interface for UniqueItems
 func (u *UniqueItems) Validate(propPath string, data interface{}, errs *[]ValError) {
-	if arr, ok := data.([]interface{}); ok {
-		found := []interface{}{}
-		for _, elem := range arr {
-			for _, f := range found {
-				if reflect.DeepEqual(f, elem) {
-					AddError(errs, propPath, data, fmt.Sprintf("array items must be unique. duplicated entry: %v", elem))
-					return
-				}
-			}
-			found = append(found, elem)
+	u.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for UniqueItems.
+// Elements of a PreparedInstance are compared by their cached canonical form.
+// The error names the indices of the first duplicate pair found
+func (u *UniqueItems) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
+	arr, ok := data.([]interface{})
+	if !ok {
+		return
+	}
+	found := map[string]int{}
+	for i := range arr {
+		var str string
+		if vc.instance != nil {
+			str = vc.instance.canonicalElem(&arr[i])
+		} else {
+			str = canonicalJSON(arr[i])
 		}
+		if first, ok := found[str]; ok {
+			*errs = append(*errs, ValError{
+				PropertyPath: propPath,
+				InvalidValue: data,
+				Message:      fmt.Sprintf("items %d and %d are duplicates", first, i),
+				Params:       map[string]interface{}{"first": first, "duplicate": i},
+			})
+			return
+		}
+		found[str] = i
+		vc.reportProgress(propPath, i+1, len(arr))
 	}
 }
 
@@ -235,16 +274,41 @@ func NewContains() Validator {
 
 // Validate implements the Validator interface for Contains
 func (c *Contains) Validate(propPath string, data interface{}, errs *[]ValError) {
+	c.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for Contains.
+// When recording ValidateOptions.Evaluated every item is checked, as each
+// matching item counts as evaluated, and the matching indices are given by
+// Evaluated.Contains
+func (c *Contains) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	v := Schema(*c)
 	if arr, ok := data.([]interface{}); ok {
-		for _, elem := range arr {
+		jp, _ := jsonpointer.Parse(propPath)
+		parent, parentPath := vc.enter(arr, propPath)
+		defer vc.leave(parent, parentPath)
+		matched := false
+		for i, elem := range arr {
 			test := &[]ValError{}
-			v.Validate(propPath, elem, test)
-			if len(*test) == 0 {
+			mark := vc.evaluations()
+			d, _ := jp.Descendant(strconv.Itoa(i))
+			v.ValidateContext(vc, d.String(), elem, test)
+			vc.reportProgress(propPath, i+1, len(arr))
+			if len(*test) != 0 {
+				vc.discardEvaluations(mark)
+				continue
+			}
+			if vc.Options.Evaluated == nil {
 				return
 			}
+			vc.containsItem(propPath, i)
+			matched = true
+		}
+		if matched {
+			return
 		}
-		AddError(errs, propPath, data, fmt.Sprintf("must contain at least one of: %v", c))
+		AddError(errs, propPath, data, fmt.Sprintf("none of the %d items match the contains schema", len(arr)))
+		(*errs)[len(*errs)-1].Params = map[string]interface{}{"items": len(arr)}
 	}
 }
 
@@ -261,7 +325,7 @@ func (c Contains) JSONChildren() (res map[string]JSONPather) {
 // UnmarshalJSON implements the jsoniter.Unmarshaler interface for Contains
 func (c *Contains) UnmarshalJSON(data []byte) error {
 	var sch Schema
-	if err := jsoniter.Unmarshal(data, &sch); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, &sch); err != nil {
 		return err
 	}
 	*c = Contains(sch)
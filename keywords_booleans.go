@@ -1,8 +1,9 @@
 package jsonschema
 
 import (
-	"github.com/json-iterator/go"
+	"fmt"
 	"strconv"
+	"strings"
 )
 
 // AllOf MUST be a non-empty array. Each item of the array MUST be a valid JSON Schema.
@@ -16,8 +17,23 @@ func NewAllOf() Validator {
 
 // Validate implements the validator interface for AllOf
 func (a AllOf) Validate(propPath string, data interface{}, errs *[]ValError) {
-	for _, sch := range a {
-		sch.Validate(propPath, data, errs)
+	a.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for AllOf.
+// With ValidateOptions.ContradictoryAllOf set, branches requiring types
+// that can't all hold are reported as a single error
+func (a AllOf) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
+	if vc.Options.ContradictoryAllOf {
+		if reason, ok := contradictoryAllOf(a); ok {
+			AddError(errs, propPath, data, fmt.Sprintf("contradictory allOf: %s", reason))
+			return
+		}
+	}
+	for i, sch := range a {
+		prev := vc.enterSchema(strconv.Itoa(i))
+		sch.ValidateContext(vc, propPath, data, errs)
+		vc.leaveSchema(prev)
 	}
 }
 
@@ -27,7 +43,7 @@ func (a AllOf) JSONProp(name string) interface{} {
 	if err != nil {
 		return nil
 	}
-	if idx > len(a) || idx < 0 {
+	if idx >= len(a) || idx < 0 {
 		return nil
 	}
 	return a[idx]
@@ -54,23 +70,72 @@ func NewAnyOf() Validator {
 
 // Validate implements the validator interface for AnyOf
 func (a AnyOf) Validate(propPath string, data interface{}, errs *[]ValError) {
-	for _, sch := range a {
+	a.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for AnyOf.
+// With ValidateOptions.BestMatchErrors a failure reports the errors of
+// the closest branch. When recording ValidateOptions.Evaluated every
+// branch is checked, as each matching branch's evaluations count
+func (a AnyOf) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
+	var best []ValError
+	matched := false
+	for i, sch := range a {
 		test := &[]ValError{}
-		sch.Validate(propPath, data, test)
+		mark := vc.evaluations()
+		prev := vc.enterSchema(strconv.Itoa(i))
+		sch.ValidateContext(vc, propPath, data, test)
+		vc.leaveSchema(prev)
 		if len(*test) == 0 {
-			return
+			if vc.Options.Evaluated == nil {
+				return
+			}
+			matched = true
+			continue
 		}
+		vc.discardEvaluations(mark)
+		if best == nil || closerMatch(*test, best, propPath) {
+			best = *test
+		}
+	}
+	if matched {
+		return
+	}
+	if vc.Options.BestMatchErrors && best != nil {
+		*errs = append(*errs, best...)
+		return
 	}
 	AddError(errs, propPath, data, "did Not match any specified AnyOf schemas")
 }
 
+// closerMatch reports whether the errors a of one branch are a closer
+// match than the errors b of another. A branch that rejects the type of
+// the instance at propPath is further than one that doesn't, otherwise
+// fewer errors are closer
+func closerMatch(a, b []ValError, propPath string) bool {
+	if aType, bType := rejectsType(a, propPath), rejectsType(b, propPath); aType != bType {
+		return bType
+	}
+	return len(a) < len(b)
+}
+
+// rejectsType reports whether errs include a "type" error at propPath
+func rejectsType(errs []ValError, propPath string) bool {
+	for _, e := range errs {
+		if e.Keyword == "type" && e.PropertyPath == propPath {
+			return true
+		}
+	}
+	return false
+}
+
 // JSONProp implements JSON property name indexing for AnyOf
 func (a AnyOf) JSONProp(name string) interface{} {
 	idx, err := strconv.Atoi(name)
 	if err != nil {
 		return nil
 	}
-	if idx > len(a) || idx < 0 {
+	if idx >= len(a) || idx < 0 {
 		return nil
 	}
 	return a[idx]
@@ -96,11 +161,21 @@ func NewOneOf() Validator {
 
 // Validate implements the validator interface for OneOf
 func (o OneOf) Validate(propPath string, data interface{}, errs *[]ValError) {
+	o.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for OneOf
+func (o OneOf) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	matched := false
-	for _, sch := range o {
+	for i, sch := range o {
 		test := &[]ValError{}
-		sch.Validate(propPath, data, test)
-		if len(*test) == 0 {
+		mark := vc.evaluations()
+		prev := vc.enterSchema(strconv.Itoa(i))
+		sch.ValidateContext(vc, propPath, data, test)
+		vc.leaveSchema(prev)
+		if len(*test) != 0 {
+			vc.discardEvaluations(mark)
+		} else {
 			if matched {
 				AddError(errs, propPath, data, "matched more than one specified OneOf schemas")
 				return
@@ -119,7 +194,7 @@ func (o OneOf) JSONProp(name string) interface{} {
 	if err != nil {
 		return nil
 	}
-	if idx > len(o) || idx < 0 {
+	if idx >= len(o) || idx < 0 {
 		return nil
 	}
 	return o[idx]
@@ -146,13 +221,42 @@ func NewNot() Validator {
 
 // Validate implements the validator interface for Not
 func (n *Not) Validate(propPath string, data interface{}, errs *[]ValError) {
+	n.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for Not
+func (n *Not) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	sch := Schema(*n)
 	test := &[]ValError{}
-	sch.Validate(propPath, data, test)
+	mark := vc.evaluations()
+	sch.ValidateContext(vc, propPath, data, test)
+	vc.discardEvaluations(mark)
 	if len(*test) == 0 {
-		// TODO - make this error actually make sense
-		AddError(errs, propPath, data, "cannot match schema")
+		AddError(errs, propPath, data, fmt.Sprintf(`value must not %s (disallowed by "not")`, describeSchema(&sch)))
+	}
+}
+
+// describeSchema gives a short description of what a schema matches, for
+// use in error messages
+func describeSchema(sch *Schema) string {
+	if sch.Title != "" {
+		return fmt.Sprintf("match %q", sch.Title)
+	}
+	if t, ok := sch.Validators["type"].(*Type); ok && len(t.vals) > 0 {
+		types := make([]string, len(t.vals))
+		for i, name := range t.vals {
+			switch name {
+			case "null":
+				types[i] = name
+			case "integer", "object", "array":
+				types[i] = "an " + name
+			default:
+				types[i] = "a " + name
+			}
+		}
+		return "be " + strings.Join(types, " or ")
 	}
+	return "match schema"
 }
 
 // JSONProp implements JSON property name indexing for Not
@@ -172,7 +276,7 @@ func (n Not) JSONChildren() (res map[string]JSONPather) {
 // UnmarshalJSON implements the jsoniter.Unmarshaler interface for Not
 func (n *Not) UnmarshalJSON(data []byte) error {
 	var sch Schema
-	if err := jsoniter.Unmarshal(data, &sch); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, &sch); err != nil {
 		return err
 	}
 	*n = Not(sch)
@@ -181,5 +285,5 @@ func (n *Not) UnmarshalJSON(data []byte) error {
 
 // MarshalJSON implements jsoniter.Marshaller for Not
 func (n Not) MarshalJSON() ([]byte, error) {
-	return jsoniter.Marshal(Schema(n))
+	return DefaultEncoder.Marshal(Schema(n))
 }
@@ -0,0 +1,120 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestNotMessage(t *testing.T) {
+	cases := []struct {
+		schema, input string
+		errors        []string
+	}{
+		{`{ "not": { "type": "integer" } }`, `5`, []string{`/: 5 value must not be an integer (disallowed by "not")`}},
+		{`{ "not": { "type": ["string", "null"] } }`, `null`, []string{`/: value must not be a string or null (disallowed by "not")`}},
+		{`{ "not": { "title": "Admin", "required": ["admin"] } }`, `{"admin": true}`, []string{`/: {"admin":true} value must not match "Admin" (disallowed by "not")`}},
+		{`{ "not": { "minimum": 3 } }`, `4`, []string{`/: 4 value must not match schema (disallowed by "not")`}},
+		{`{ "not": { "type": "integer" } }`, `"5"`, nil},
+		// false schemas fail every value
+		{`false`, `5`, []string{`/: 5 no value is allowed here`}},
+		{`{ "items": false }`, `["a"]`, []string{`/0: "a" no value is allowed here`}},
+		{`{ "properties": { "a": false } }`, `{"a": 1}`, []string{`/a: 1 property "a" is not allowed`}},
+		{`{ "patternProperties": { "^a": false } }`, `{"ab": 1}`, []string{`/ab: 1 property "ab" is not allowed`}},
+	}
+
+	for i, c := range cases {
+		errs, err := Must(c.schema).ValidateBytes([]byte(c.input))
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err.Error())
+			continue
+		}
+		if len(errs) != len(c.errors) {
+			t.Errorf("case %d: error length mismatch. expected: %d, got: %v", i, len(c.errors), errs)
+			continue
+		}
+		for j, e := range errs {
+			if e.Error() != c.errors[j] {
+				t.Errorf("case %d: validation error %d mismatch. expected: %s, got: %s", i, j, c.errors[j], e.Error())
+			}
+		}
+	}
+}
+
+func TestAnyOfBestMatchErrors(t *testing.T) {
+	rs := Must(`{
+		"anyOf": [
+			{ "type": "string" },
+			{
+				"type": "object",
+				"properties": { "kind": { "const": "circle" }, "radius": { "type": "number" } },
+				"required": ["kind", "radius"]
+			},
+			{
+				"type": "object",
+				"properties": { "kind": { "const": "rect" }, "width": { "type": "number" }, "height": { "type": "number" } },
+				"required": ["kind", "width", "height"]
+			}
+		]
+	}`)
+
+	cases := []struct {
+		input  string
+		best   bool
+		errors []string
+	}{
+		{`{ "kind": "circle", "radius": "2" }`, false, []string{`/: did Not match any specified AnyOf schemas`}},
+		{`{ "kind": "circle", "radius": "2" }`, true, []string{`/radius: type should be number`}},
+		{`{ "kind": "rect", "width": 1 }`, true, []string{`/: "height" value is required`}},
+		{`5`, true, []string{`/: type should be string`}},
+		{`"a"`, true, nil},
+	}
+
+	for i, c := range cases {
+		errs, err := rs.ValidateBytesWithOptions([]byte(c.input), ValidateOptions{BestMatchErrors: c.best})
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err.Error())
+			continue
+		}
+		if len(errs) != len(c.errors) {
+			t.Errorf("case %d: error length mismatch. expected: %d, got: %v", i, len(c.errors), errs)
+			continue
+		}
+		// objects are compared by message, their key order isn't stable
+		for j, e := range errs {
+			if got := e.PropertyPath + ": " + e.Message; got != c.errors[j] {
+				t.Errorf("case %d: validation error %d mismatch. expected: %s, got: %s", i, j, c.errors[j], got)
+			}
+		}
+	}
+}
+
+func TestContradictoryAllOf(t *testing.T) {
+	rs := Must(`{
+		"properties": {
+			"a": { "allOf": [{ "type": "object", "required": ["x"] }, { "type": "string", "minLength": 3 }] },
+			"b": { "allOf": [{ "type": "string" }, { "minLength": 3 }] }
+		}
+	}`)
+	doc := []byte(`{"a": 5, "b": "ab"}`)
+
+	errs, err := rs.ValidateBytes(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 3 {
+		t.Errorf("expected an error per failing branch by default, got: %v", errs)
+	}
+
+	errs, err = rs.ValidateBytesWithOptions(doc, ValidateOptions{ContradictoryAllOf: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "/a: contradictory allOf: branch 0 requires object, branch 1 requires string\n/b: min length of 3 characters required: ab"
+	if got := errorLines(errs); got != expect {
+		t.Errorf("expected errors:\n%s\ngot:\n%s", expect, got)
+	}
+	for _, e := range errs {
+		if e.PropertyPath == "/a" && e.SchemaPath != "#/properties/a/allOf" {
+			t.Errorf("expected the error at the allOf, got: %s", e.SchemaPath)
+		}
+	}
+}
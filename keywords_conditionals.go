@@ -1,9 +1,5 @@
 package jsonschema
 
-import (
-	"github.com/json-iterator/go"
-)
-
 // If MUST be a valid JSON Schema.
 // Instances that successfully validate against this keyword's subschema MUST also be valid against the subschema value of the "Then" keyword, if present.
 // Instances that fail to validate against this keyword's subschema MUST also be valid against the subschema value of the "Elsee" keyword.
@@ -21,20 +17,33 @@ func NewIf() Validator {
 
 // Validate implements the Validator interface for If
 func (i *If) Validate(propPath string, data interface{}, errs *[]ValError) {
+	i.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for If
+func (i *If) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	test := &[]ValError{}
-	i.Schema.Validate(propPath, data, test)
+	mark := vc.evaluations()
+	i.Schema.ValidateContext(vc, propPath, data, test)
+	if len(*test) != 0 {
+		vc.discardEvaluations(mark)
+	}
 	if len(*test) == 0 {
 		if i.Then != nil {
 			s := Schema(*i.Then)
 			sch := &s
-			sch.Validate(propPath, data, errs)
+			prev := vc.enterSibling("then")
+			sch.ValidateContext(vc, propPath, data, errs)
+			vc.leaveSchema(prev)
 			return
 		}
 	} else {
 		if i.Else != nil {
 			s := Schema(*i.Else)
 			sch := &s
-			sch.Validate(propPath, data, errs)
+			prev := vc.enterSibling("else")
+			sch.ValidateContext(vc, propPath, data, errs)
+			vc.leaveSchema(prev)
 			return
 		}
 	}
@@ -53,7 +62,7 @@ func (i If) JSONChildren() (res map[string]JSONPather) {
 // UnmarshalJSON implements the jsoniter.Unmarshaler interface for If
 func (i *If) UnmarshalJSON(data []byte) error {
 	var sch Schema
-	if err := jsoniter.Unmarshal(data, &sch); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, &sch); err != nil {
 		return err
 	}
 	*i = If{Schema: sch}
@@ -62,7 +71,7 @@ func (i *If) UnmarshalJSON(data []byte) error {
 
 // MarshalJSON implements jsoniter.Marshaler for If
 func (i If) MarshalJSON() ([]byte, error) {
-	return jsoniter.Marshal(i.Schema)
+	return DefaultEncoder.Marshal(i.Schema)
 }
 
 // Then MUST be a valid JSON Schema.
@@ -91,7 +100,7 @@ func (t Then) JSONChildren() (res map[string]JSONPather) {
 // UnmarshalJSON implements the jsoniter.Unmarshaler interface for Then
 func (t *Then) UnmarshalJSON(data []byte) error {
 	var sch Schema
-	if err := jsoniter.Unmarshal(data, &sch); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, &sch); err != nil {
 		return err
 	}
 	*t = Then(sch)
@@ -100,7 +109,7 @@ func (t *Then) UnmarshalJSON(data []byte) error {
 
 // MarshalJSON implements jsoniter.Marshaler for Then
 func (t Then) MarshalJSON() ([]byte, error) {
-	return jsoniter.Marshal(Schema(t))
+	return DefaultEncoder.Marshal(Schema(t))
 }
 
 // Else MUST be a valid JSON Schema.
@@ -129,7 +138,7 @@ func (e Else) JSONChildren() (res map[string]JSONPather) {
 // UnmarshalJSON implements the jsoniter.Unmarshaler interface for Else
 func (e *Else) UnmarshalJSON(data []byte) error {
 	var sch Schema
-	if err := jsoniter.Unmarshal(data, &sch); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, &sch); err != nil {
 		return err
 	}
 	*e = Else(sch)
@@ -138,5 +147,5 @@ func (e *Else) UnmarshalJSON(data []byte) error {
 
 // MarshalJSON implements jsoniter.Marshaler for Else
 func (e Else) MarshalJSON() ([]byte, error) {
-	return jsoniter.Marshal(Schema(e))
+	return DefaultEncoder.Marshal(Schema(e))
 }
@@ -0,0 +1,64 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestIfThenElseCombinations(t *testing.T) {
+	cases := []struct {
+		schema string
+		doc    string
+		valid  bool
+	}{
+		// "if" on its own never fails
+		{`{"if": {"type": "integer"}}`, `1`, true},
+		{`{"if": {"type": "integer"}}`, `"a"`, true},
+		{`{"if": false}`, `1`, true},
+
+		// "then" applies only when "if" matches
+		{`{"if": {"type": "integer"}, "then": {"minimum": 10}}`, `11`, true},
+		{`{"if": {"type": "integer"}, "then": {"minimum": 10}}`, `1`, false},
+		{`{"if": {"type": "integer"}, "then": {"minimum": 10}}`, `"a"`, true},
+		{`{"if": false, "then": false}`, `1`, true},
+		{`{"if": true, "then": false}`, `1`, false},
+
+		// "else" applies only when "if" fails
+		{`{"if": {"type": "integer"}, "else": {"type": "string"}}`, `1`, true},
+		{`{"if": {"type": "integer"}, "else": {"type": "string"}}`, `"a"`, true},
+		{`{"if": {"type": "integer"}, "else": {"type": "string"}}`, `null`, false},
+		{`{"if": true, "else": false}`, `1`, true},
+		{`{"if": false, "else": false}`, `1`, false},
+
+		// with both, exactly one branch applies
+		{`{"if": {"type": "integer"}, "then": {"minimum": 10}, "else": {"type": "string"}}`, `11`, true},
+		{`{"if": {"type": "integer"}, "then": {"minimum": 10}, "else": {"type": "string"}}`, `1`, false},
+		{`{"if": {"type": "integer"}, "then": {"minimum": 10}, "else": {"type": "string"}}`, `"a"`, true},
+		{`{"if": {"type": "integer"}, "then": {"minimum": 10}, "else": {"type": "string"}}`, `null`, false},
+
+		// "then" and "else" without "if" are ignored
+		{`{"then": false}`, `1`, true},
+		{`{"else": false}`, `1`, true},
+		{`{"then": false, "else": false}`, `1`, true},
+
+		// errors of "if" itself are never reported
+		{`{"if": {"required": ["a"]}, "then": {"required": ["b"]}}`, `{}`, true},
+		{`{"if": {"required": ["a"]}, "then": {"required": ["b"]}}`, `{"a": 1}`, false},
+		{`{"if": {"required": ["a"]}, "then": {"required": ["b"]}}`, `{"a": 1, "b": 2}`, true},
+	}
+
+	for i, c := range cases {
+		rs := &RootSchema{}
+		if err := rs.UnmarshalJSON([]byte(c.schema)); err != nil {
+			t.Errorf("case %d schema is invalid: %s", i, err.Error())
+			continue
+		}
+		errs, err := rs.ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err)
+			continue
+		}
+		if valid := len(errs) == 0; valid != c.valid {
+			t.Errorf("case %d: expected %s to be valid against %s: %t, got errors: %v", i, c.doc, c.schema, c.valid, errs)
+		}
+	}
+}
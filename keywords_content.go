@@ -0,0 +1,167 @@
+package jsonschema
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ContentEncoding names the encoding used to store binary content in a
+// string instance, eg: "base64". It's an annotation unless
+// ValidateOptions.AssertContent is set, in which case a string instance is
+// valid if it decodes with the named encoding. Unknown encodings always
+// pass
+type ContentEncoding string
+
+// NewContentEncoding allocates a new ContentEncoding validator
+func NewContentEncoding() Validator {
+	return new(ContentEncoding)
+}
+
+// Validate implements the Validator interface for ContentEncoding. Without
+// a validation context content is never asserted
+func (c ContentEncoding) Validate(propPath string, data interface{}, errs *[]ValError) {}
+
+// ValidateContext implements the ContextValidator interface for ContentEncoding
+func (c ContentEncoding) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
+	if str, ok := data.(string); ok && vc.Options.AssertContent {
+		if _, err := decodeContent(string(c), str); err != nil {
+			AddError(errs, propPath, data, fmt.Sprintf("content is not valid %s: %s", c, err.Error()))
+		}
+	}
+}
+
+// ContentMediaType names the media type of the content of a string
+// instance, eg: "application/json". It's an annotation unless
+// ValidateOptions.AssertContent is set, in which case a string instance is
+// valid if its content, decoded per "contentEncoding", parses as the
+// media type. Only JSON media types are checked
+type ContentMediaType struct {
+	MediaType string
+	encoding  ContentEncoding
+}
+
+// NewContentMediaType allocates a new ContentMediaType validator
+func NewContentMediaType() Validator {
+	return &ContentMediaType{}
+}
+
+// Validate implements the Validator interface for ContentMediaType.
+// Without a validation context content is never asserted
+func (c *ContentMediaType) Validate(propPath string, data interface{}, errs *[]ValError) {}
+
+// ValidateContext implements the ContextValidator interface for ContentMediaType
+func (c *ContentMediaType) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
+	str, ok := data.(string)
+	if !ok || !vc.Options.AssertContent || !isJSONMediaType(c.MediaType) {
+		return
+	}
+	content, err := decodeContent(string(c.encoding), str)
+	if err != nil {
+		// reported by contentEncoding
+		return
+	}
+	var doc interface{}
+	if err := DefaultDecoder.Unmarshal(content, &doc); err != nil {
+		AddError(errs, propPath, data, fmt.Sprintf("content is not valid %s", c.MediaType))
+	}
+}
+
+// UnmarshalJSON implements the jsoniter.Unmarshaler interface for ContentMediaType
+func (c *ContentMediaType) UnmarshalJSON(data []byte) error {
+	return DefaultDecoder.Unmarshal(data, &c.MediaType)
+}
+
+// MarshalJSON implements jsoniter.Marshaler for ContentMediaType
+func (c ContentMediaType) MarshalJSON() ([]byte, error) {
+	return DefaultEncoder.Marshal(c.MediaType)
+}
+
+// ContentSchema describes the structure of the content of a string
+// instance, after decoding per "contentEncoding" and parsing per
+// "contentMediaType". It's an annotation unless
+// ValidateOptions.AssertContent is set, in which case the content must
+// validate against the schema. "contentSchema" is ignored without a JSON
+// "contentMediaType"
+type ContentSchema struct {
+	Schema    *Schema
+	encoding  ContentEncoding
+	mediaType string
+}
+
+// NewContentSchema allocates a new ContentSchema validator
+func NewContentSchema() Validator {
+	return &ContentSchema{}
+}
+
+// Validate implements the Validator interface for ContentSchema. Without a
+// validation context content is never asserted
+func (c *ContentSchema) Validate(propPath string, data interface{}, errs *[]ValError) {}
+
+// ValidateContext implements the ContextValidator interface for ContentSchema
+func (c *ContentSchema) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
+	str, ok := data.(string)
+	if !ok || !vc.Options.AssertContent || !isJSONMediaType(c.mediaType) {
+		return
+	}
+	content, err := decodeContent(string(c.encoding), str)
+	if err != nil {
+		return
+	}
+	var doc interface{}
+	if err := DefaultDecoder.Unmarshal(content, &doc); err != nil {
+		// reported by contentMediaType
+		return
+	}
+	c.Schema.ValidateContext(vc, propPath, doc, errs)
+}
+
+// JSONProp implements JSON property name indexing for ContentSchema
+func (c *ContentSchema) JSONProp(name string) interface{} {
+	return c.Schema.JSONProp(name)
+}
+
+// JSONChildren implements the JSONContainer interface for ContentSchema
+func (c *ContentSchema) JSONChildren() (res map[string]JSONPather) {
+	if c.Schema.Ref != "" {
+		return map[string]JSONPather{"$ref": c.Schema}
+	}
+	return c.Schema.JSONChildren()
+}
+
+// UnmarshalJSON implements the jsoniter.Unmarshaler interface for ContentSchema
+func (c *ContentSchema) UnmarshalJSON(data []byte) error {
+	sch := &Schema{}
+	if err := DefaultDecoder.Unmarshal(data, sch); err != nil {
+		return err
+	}
+	*c = ContentSchema{Schema: sch}
+	return nil
+}
+
+// MarshalJSON implements jsoniter.Marshaler for ContentSchema
+func (c ContentSchema) MarshalJSON() ([]byte, error) {
+	return DefaultEncoder.Marshal(c.Schema)
+}
+
+// decodeContent decodes str according to a content encoding
+func decodeContent(encoding, str string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "base64":
+		return base64.StdEncoding.DecodeString(str)
+	case "base32":
+		return base32.StdEncoding.DecodeString(str)
+	case "base16":
+		return hex.DecodeString(str)
+	}
+	return []byte(str), nil
+}
+
+// isJSONMediaType reports whether a media type is JSON, including
+// structured syntax suffixes like "+json"
+func isJSONMediaType(mediaType string) bool {
+	mediaType = strings.ToLower(strings.TrimSpace(strings.Split(mediaType, ";")[0]))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
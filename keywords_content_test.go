@@ -0,0 +1,65 @@
+package jsonschema
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestContentKeywords(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"payload": {
+				"type": "string",
+				"contentEncoding": "base64",
+				"contentMediaType": "application/json",
+				"contentSchema": {
+					"type": "object",
+					"properties": { "id": { "type": "integer" } },
+					"required": ["id"]
+				}
+			}
+		}
+	}`)
+
+	encode := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	cases := []struct {
+		payload string
+		errors  []string
+	}{
+		{encode(`{"id": 1}`), nil},
+		{encode(`{"id": "one"}`), []string{`/payload/id: "one" type should be integer`}},
+		{encode(`{}`), []string{`/payload: {} "id" value is required`}},
+		{encode(`{`), []string{`/payload: "ew==" content is not valid application/json`}},
+		{`not base64!`, []string{`/payload: "not base64!" content is not valid base64: illegal base64 data at input byte 3`}},
+	}
+
+	for i, c := range cases {
+		data := []byte(`{"payload": "` + c.payload + `"}`)
+
+		errs, err := rs.ValidateBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != 0 {
+			t.Errorf("case %d: expected content keywords to be annotations by default, got: %v", i, errs)
+		}
+
+		errs, err = rs.ValidateBytesWithOptions(data, ValidateOptions{AssertContent: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != len(c.errors) {
+			t.Errorf("case %d: error length mismatch. expected: %d, got: %v", i, len(c.errors), errs)
+			continue
+		}
+		for j, e := range errs {
+			if e.Error() != c.errors[j] {
+				t.Errorf("case %d: validation error %d mismatch. expected: %s, got: %s", i, j, c.errors[j], e.Error())
+			}
+		}
+	}
+}
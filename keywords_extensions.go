@@ -0,0 +1,49 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MatchesField is an extension keyword that isn't part of JSON Schema. An
+// instance held by an object is valid if it's equal to the property of the
+// same object named by the keyword, eg: a "confirmPassword" property with
+// { "matchesField": "password" }. Values that aren't held by an object
+// always pass. MatchesField is only recognized once RegisterMatchesField
+// has been called
+type MatchesField string
+
+// RegisterMatchesField adds the "matchesField" keyword to the keywords
+// recognized when parsing schemas
+func RegisterMatchesField() {
+	RegisterKeyword("matchesField", newMatchesField)
+}
+
+// newMatchesField is the KeywordFactory for MatchesField
+func newMatchesField(raw json.RawMessage) (Validator, error) {
+	var field string
+	if err := DefaultDecoder.Unmarshal(raw, &field); err != nil {
+		return nil, err
+	}
+	return MatchesField(field), nil
+}
+
+// Validate implements the Validator interface for MatchesField. Without a
+// validation context the parent object isn't known, so it always passes
+func (m MatchesField) Validate(propPath string, data interface{}, errs *[]ValError) {}
+
+// ValidateContext implements the ContextValidator interface for MatchesField
+func (m MatchesField) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
+	obj, ok := vc.Parent.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if other, ok := obj[string(m)]; !ok || !equalJSON(other, data) {
+		*errs = append(*errs, ValError{
+			PropertyPath: propPath,
+			InvalidValue: data,
+			Message:      fmt.Sprintf("must match %q", string(m)),
+			Params:       map[string]interface{}{"field": string(m)},
+		})
+	}
+}
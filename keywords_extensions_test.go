@@ -0,0 +1,57 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestMatchesField(t *testing.T) {
+	data := `{
+		"type": "object",
+		"properties": {
+			"password": { "type": "string" },
+			"confirmPassword": { "type": "string", "matchesField": "password" },
+			"accounts": {
+				"type": "array",
+				"items": { "matchesField": "password" }
+			}
+		}
+	}`
+
+	if _, ok := keywordFactories["matchesField"]; ok {
+		t.Errorf("expected matchesField to be off by default")
+	}
+
+	RegisterMatchesField()
+	defer delete(keywordFactories, "matchesField")
+	rs := Must(data)
+
+	cases := []struct {
+		doc    string
+		expect []string
+	}{
+		{`{ "password": "a", "confirmPassword": "a" }`, nil},
+		{`{ "password": "a", "confirmPassword": "b" }`, []string{`/confirmPassword: "b" must match "password"`}},
+		{`{ "confirmPassword": "b" }`, []string{`/confirmPassword: "b" must match "password"`}},
+		// array elements aren't held by an object
+		{`{ "password": "a", "accounts": ["b"] }`, nil},
+	}
+
+	for i, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != len(c.expect) {
+			t.Errorf("case %d: expected %v, got: %v", i, c.expect, errs)
+			continue
+		}
+		for j, e := range errs {
+			if e.Error() != c.expect[j] {
+				t.Errorf("case %d: expected %q, got: %q", i, c.expect[j], e.Error())
+			}
+			if e.Keyword != "matchesField" || e.Params["field"] != "password" {
+				t.Errorf("case %d: expected matchesField keyword and field param, got: %#v", i, e)
+			}
+		}
+	}
+}
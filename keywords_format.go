@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -17,7 +18,6 @@ const (
 	unescapedTilda        = `\~[^01]`
 	endingTilda           = `\~$`
 	schemePrefix          = `^[^\:]+\:`
-	uriTemplate           = `\{[^\{\}\\]*\}`
 )
 
 var (
@@ -26,7 +26,6 @@ var (
 	unescaptedTildaPattern = regexp.MustCompile(unescapedTilda)
 	endingTildaPattern     = regexp.MustCompile(endingTilda)
 	schemePrefixPattern    = regexp.MustCompile(schemePrefix)
-	uriTemplatePattern     = regexp.MustCompile(uriTemplate)
 
 	disallowedIdnChars = map[string]bool{"\u0020": true, "\u002D": true, "\u00A2": true, "\u00A3": true, "\u00A4": true, "\u00A5": true, "\u034F": true, "\u0640": true, "\u07FA": true, "\u180B": true, "\u180C": true, "\u180D": true, "\u200B": true, "\u2060": true, "\u2104": true, "\u2108": true, "\u2114": true, "\u2117": true, "\u2118": true, "\u211E": true, "\u211F": true, "\u2123": true, "\u2125": true, "\u2282": true, "\u2283": true, "\u2284": true, "\u2285": true, "\u2286": true, "\u2287": true, "\u2288": true, "\u2616": true, "\u2617": true, "\u2619": true, "\u262F": true, "\u2638": true, "\u266C": true, "\u266D": true, "\u266F": true, "\u2752": true, "\u2756": true, "\u2758": true, "\u275E": true, "\u2761": true, "\u2775": true, "\u2794": true, "\u2798": true, "\u27AF": true, "\u27B1": true, "\u27BE": true, "\u3004": true, "\u3012": true, "\u3013": true, "\u3020": true, "\u302E": true, "\u302F": true, "\u3031": true, "\u3032": true, "\u3035": true, "\u303B": true, "\u3164": true, "\uFFA0": true}
 )
@@ -61,6 +60,12 @@ func NewFormat() Validator {
 func (f Format) Validate(propPath string, data interface{}, errs *[]ValError) {
 	var err error
 	if str, ok := data.(string); ok {
+		if custom, ok := customFormats[string(f)]; ok {
+			if !custom.validate(str) {
+				AddError(errs, propPath, data, fmt.Sprintf("invalid %s", f))
+			}
+			return
+		}
 		switch f {
 		case "date-time":
 			err = isValidDateTime(str)
@@ -96,6 +101,8 @@ func (f Format) Validate(propPath string, data interface{}, errs *[]ValError) {
 			err = isValidURITemplate(str)
 		case "uri":
 			err = isValidURI(str)
+		case "uuid":
+			err = isValidUUID(str)
 		default:
 			err = nil
 		}
@@ -213,14 +220,192 @@ func isValidIPv6(ipv6 string) error {
 // according to [RFC3987].
 // https://tools.ietf.org/html/rfc3987
 func isValidIriRef(iriRef string) error {
-	return isValidURIRef(iriRef)
+	return checkIRI(iriRef, false)
 }
 
 // A string instance is a valid against "iri" if it is a valid IRI,
 // according to [RFC3987].
 // https://tools.ietf.org/html/rfc3987
 func isValidIri(iri string) error {
-	return isValidURI(iri)
+	return checkIRI(iri, true)
+}
+
+// checkIRI checks a string against the IRI-reference grammar of RFC 3987,
+// requiring a scheme when absolute is set. Unlike URIs, IRIs may contain
+// non-ASCII characters without percent-encoding them
+func checkIRI(iri string, absolute bool) error {
+	if !utf8.ValidString(iri) {
+		return fmt.Errorf("iri isn't valid UTF-8")
+	}
+	rest := iri
+	if i := strings.IndexAny(rest, ":/?#"); i >= 0 && rest[i] == ':' {
+		if !isIRIScheme(rest[:i]) {
+			return fmt.Errorf("invalid iri scheme %q", rest[:i])
+		}
+		rest = rest[i+1:]
+	} else if absolute {
+		return fmt.Errorf("iri missing scheme prefix")
+	}
+
+	if i := strings.IndexByte(rest, '#'); i >= 0 {
+		if err := checkIRIChars(rest[i+1:], ":@/?", false); err != nil {
+			return fmt.Errorf("invalid iri fragment: %s", err.Error())
+		}
+		rest = rest[:i]
+	}
+	if i := strings.IndexByte(rest, '?'); i >= 0 {
+		if err := checkIRIChars(rest[i+1:], ":@/?", true); err != nil {
+			return fmt.Errorf("invalid iri query: %s", err.Error())
+		}
+		rest = rest[:i]
+	}
+	if strings.HasPrefix(rest, "//") {
+		rest = rest[2:]
+		end := strings.IndexByte(rest, '/')
+		if end < 0 {
+			end = len(rest)
+		}
+		if err := checkIRIAuthority(rest[:end]); err != nil {
+			return err
+		}
+		rest = rest[end:]
+	}
+	if err := checkIRIChars(rest, ":@/", false); err != nil {
+		return fmt.Errorf("invalid iri path: %s", err.Error())
+	}
+	return nil
+}
+
+// isIRIScheme reports whether str is a valid scheme: a letter followed by
+// letters, digits, "+", "-", or "."
+func isIRIScheme(str string) bool {
+	for i, r := range str {
+		if !isASCIILetter(r) && (i == 0 || !(r >= '0' && r <= '9') && r != '+' && r != '-' && r != '.') {
+			return false
+		}
+	}
+	return str != ""
+}
+
+// checkIRIAuthority checks the "[ userinfo "@" ] host [ ":" port ]"
+// authority of an IRI
+func checkIRIAuthority(authority string) error {
+	hostport := authority
+	if i := strings.IndexByte(authority, '@'); i >= 0 {
+		if err := checkIRIChars(authority[:i], ":", false); err != nil {
+			return fmt.Errorf("invalid iri userinfo: %s", err.Error())
+		}
+		hostport = authority[i+1:]
+	}
+
+	host, port := hostport, ""
+	if strings.HasPrefix(hostport, "[") {
+		end := strings.IndexByte(hostport, ']')
+		if end < 0 {
+			return fmt.Errorf("iri host is missing a closing ']'")
+		}
+		host, port = hostport[1:end], hostport[end+1:]
+		if port != "" && port[0] != ':' {
+			return fmt.Errorf("invalid iri host %q", hostport)
+		}
+		if err := checkIPLiteral(host); err != nil {
+			return err
+		}
+	} else {
+		if i := strings.IndexByte(hostport, ':'); i >= 0 {
+			host, port = hostport[:i], hostport[i:]
+		}
+		if err := checkIRIChars(host, "", false); err != nil {
+			return fmt.Errorf("invalid iri host: %s", err.Error())
+		}
+	}
+
+	for _, r := range strings.TrimPrefix(port, ":") {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("invalid iri port %q", port[1:])
+		}
+	}
+	return nil
+}
+
+// checkIPLiteral checks the contents of a bracketed IRI host, which is
+// either an IPv6 address or a "v" prefixed future address format
+func checkIPLiteral(host string) error {
+	if len(host) > 0 && (host[0] == 'v' || host[0] == 'V') {
+		dot := strings.IndexByte(host, '.')
+		if dot < 2 || dot == len(host)-1 {
+			return fmt.Errorf("invalid iri IP literal %q", host)
+		}
+		for _, r := range host[1:dot] {
+			if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+				return fmt.Errorf("invalid iri IP literal %q", host)
+			}
+		}
+		for _, r := range host[dot+1:] {
+			if r >= utf8.RuneSelf || !isIRIUnreserved(r) && !strings.ContainsRune(subDelims+":", r) {
+				return fmt.Errorf("invalid iri IP literal %q", host)
+			}
+		}
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !strings.Contains(host, ":") {
+		return fmt.Errorf("invalid iri IPv6 address %q", host)
+	}
+	return nil
+}
+
+// subDelims are the "sub-delims" characters of RFC 3986
+const subDelims = "!$&'()*+,;="
+
+// checkIRIChars checks that str only contains unreserved characters,
+// including non-ASCII ones, percent-encoded octets, sub-delims, and the
+// characters in extra. private allows private use characters, which are
+// only valid in a query
+func checkIRIChars(str, extra string, private bool) error {
+	for i := 0; i < len(str); {
+		r, size := utf8.DecodeRuneInString(str[i:])
+		switch {
+		case r == '%':
+			if i+2 >= len(str) || !isHexDigit(str[i+1]) || !isHexDigit(str[i+2]) {
+				return fmt.Errorf("invalid percent-encoding at %q", str[i:])
+			}
+			size = 3
+		case isIRIUnreserved(r), strings.ContainsRune(subDelims, r), strings.ContainsRune(extra, r):
+		case private && isIRIPrivate(r):
+		default:
+			return fmt.Errorf("invalid character %q", r)
+		}
+		i += size
+	}
+	return nil
+}
+
+// isIRIUnreserved reports whether r is an "iunreserved" character: an
+// ASCII letter or digit, "-", ".", "_", "~", or a non-ASCII "ucschar"
+func isIRIUnreserved(r rune) bool {
+	switch {
+	case isASCIILetter(r), r >= '0' && r <= '9', r == '-', r == '.', r == '_', r == '~':
+		return true
+	case r >= 0xA0 && r <= 0xD7FF, r >= 0xF900 && r <= 0xFDCF, r >= 0xFDF0 && r <= 0xFFEF:
+		return true
+	case r >= 0x10000 && r <= 0xEFFFD:
+		// excludes the last two code points of each plane
+		return r&0xFFFF <= 0xFFFD
+	}
+	return false
+}
+
+// isIRIPrivate reports whether r is an "iprivate" private use character
+func isIRIPrivate(r rune) bool {
+	return r >= 0xE000 && r <= 0xF8FF || r >= 0xF0000 && r <= 0xFFFFD || r >= 0x100000 && r <= 0x10FFFD
+}
+
+func isASCIILetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
 }
 
 // A string instance is a valid against "json-pointer" if it is a
@@ -295,6 +480,9 @@ func isValidTime(time string) error {
 // according to [RFC3986].
 // https://tools.ietf.org/html/rfc3986
 func isValidURIRef(uriRef string) error {
+	if err := checkASCII(uriRef); err != nil {
+		return err
+	}
 	if _, err := url.Parse(uriRef); err != nil {
 		return fmt.Errorf("uri incorrectly Formatted: %s", err.Error())
 	}
@@ -310,18 +498,113 @@ func isValidURIRef(uriRef string) error {
 // Template specification.
 // https://tools.ietf.org/html/rfc6570
 func isValidURITemplate(uriTemplate string) error {
-	arbitraryValue := "aaa"
-	uriRef := uriTemplatePattern.ReplaceAllString(uriTemplate, arbitraryValue)
-	if strings.Contains(uriRef, "{") || strings.Contains(uriRef, "}") {
-		return fmt.Errorf("invalid uri template")
+	rest := uriTemplate
+	for rest != "" {
+		start := strings.IndexAny(rest, "{}")
+		if start < 0 {
+			start = len(rest)
+		}
+		if err := checkTemplateLiterals(rest[:start]); err != nil {
+			return err
+		}
+		rest = rest[start:]
+		if rest == "" {
+			break
+		}
+		if rest[0] == '}' {
+			return fmt.Errorf("invalid uri template: unopened '}'")
+		}
+		end := strings.IndexAny(rest[1:], "{}")
+		if end < 0 || rest[1+end] == '{' {
+			return fmt.Errorf("invalid uri template: unclosed '{'")
+		}
+		if err := checkTemplateExpression(rest[1 : 1+end]); err != nil {
+			return fmt.Errorf("invalid uri template expression {%s}: %s", rest[1:1+end], err.Error())
+		}
+		rest = rest[2+end:]
+	}
+	return nil
+}
+
+// checkTemplateLiterals checks the text between uri template expressions,
+// which may contain any character allowed in an IRI, but only
+// percent-encoded "%"
+func checkTemplateLiterals(literals string) error {
+	for i := 0; i < len(literals); {
+		r, size := utf8.DecodeRuneInString(literals[i:])
+		switch {
+		case r == '%':
+			if i+2 >= len(literals) || !isHexDigit(literals[i+1]) || !isHexDigit(literals[i+2]) {
+				return fmt.Errorf("invalid uri template: invalid percent-encoding at %q", literals[i:])
+			}
+			size = 3
+		case r == utf8.RuneError && size == 1, r <= ' ', r == 0x7F, strings.ContainsRune("\"'<>\\^`|", r):
+			return fmt.Errorf("invalid uri template: invalid character %q", r)
+		case r >= utf8.RuneSelf && !isIRIUnreserved(r) && !isIRIPrivate(r):
+			return fmt.Errorf("invalid uri template: invalid character %q", r)
+		}
+		i += size
+	}
+	return nil
+}
+
+// checkTemplateExpression checks the contents of a uri template expression:
+// an optional operator followed by a comma separated list of variables,
+// each of which may have a ":" prefix length or "*" explode modifier
+func checkTemplateExpression(expr string) error {
+	if expr != "" && strings.IndexByte("+#./;?&", expr[0]) >= 0 {
+		expr = expr[1:]
+	} else if expr != "" && strings.IndexByte("=,!@|", expr[0]) >= 0 {
+		return fmt.Errorf("operator %q is reserved", expr[0])
 	}
-	return isValidURIRef(uriRef)
+
+	for _, varspec := range strings.Split(expr, ",") {
+		name := varspec
+		if strings.HasSuffix(varspec, "*") {
+			name = varspec[:len(varspec)-1]
+		} else if i := strings.IndexByte(varspec, ':'); i >= 0 {
+			name = varspec[:i]
+			if n, err := strconv.Atoi(varspec[i+1:]); err != nil || varspec[i+1] == '0' || n < 1 || n > 9999 || strings.ContainsAny(varspec[i+1:], "+-") {
+				return fmt.Errorf("invalid prefix length %q", varspec[i+1:])
+			}
+		}
+		if err := checkTemplateVarname(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTemplateVarname checks a variable name: letters, digits, "_", and
+// percent-encoded octets, optionally separated by single "."
+func checkTemplateVarname(name string) error {
+	if name == "" {
+		return fmt.Errorf("missing variable name")
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case isASCIILetter(rune(c)), c >= '0' && c <= '9', c == '_':
+		case c == '%':
+			if i+2 >= len(name) || !isHexDigit(name[i+1]) || !isHexDigit(name[i+2]) {
+				return fmt.Errorf("invalid percent-encoding in variable name %q", name)
+			}
+			i += 2
+		case c == '.' && i > 0 && i < len(name)-1 && name[i+1] != '.':
+		default:
+			return fmt.Errorf("invalid variable name %q", name)
+		}
+	}
+	return nil
 }
 
 // A string instance is a valid against "uri" if it is a valid URI,
 // according to [RFC3986].
 // https://tools.ietf.org/html/rfc3986
 func isValidURI(uri string) error {
+	if err := checkASCII(uri); err != nil {
+		return err
+	}
 	if _, err := url.Parse(uri); err != nil {
 		return fmt.Errorf("uri incorrectly Formatted: %s", err.Error())
 	}
@@ -330,3 +613,37 @@ func isValidURI(uri string) error {
 	}
 	return nil
 }
+
+// checkASCII errors for strings with non-ASCII characters, which URIs must
+// percent-encode. IRIs are the unicode counterpart to URIs
+func checkASCII(uri string) error {
+	for _, r := range uri {
+		if r >= utf8.RuneSelf {
+			return fmt.Errorf("uri contains non-ASCII character %q", r)
+		}
+	}
+	return nil
+}
+
+// A string instance is valid against "uuid" if it is a valid string
+// representation of a UUID according to [RFC4122], a format added by
+// draft 2019-09
+// https://tools.ietf.org/html/rfc4122#section-3
+func isValidUUID(uuid string) error {
+	if len(uuid) != 36 {
+		return fmt.Errorf("uuid must be 36 characters long")
+	}
+	for i := 0; i < len(uuid); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if uuid[i] != '-' {
+				return fmt.Errorf("uuid missing hyphen at position %d", i)
+			}
+		default:
+			if !isHexDigit(uuid[i]) {
+				return fmt.Errorf("invalid uuid character %q", uuid[i])
+			}
+		}
+	}
+	return nil
+}
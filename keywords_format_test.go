@@ -0,0 +1,130 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestIRIFormats(t *testing.T) {
+	cases := []struct {
+		value       string
+		iri, iriRef bool
+	}{
+		{"http://example.com/a?b=c#d", true, true},
+		{"http://ƒøø.ßår/?∂éœ=πîx#πîüx", true, true},
+		{"https://例え.テスト/パス?クエリ=値", true, true},
+		{"http://[2001:db8::7]:8080/ü", true, true},
+		{"http://[v1.fe80::a+en1]/", true, true},
+		{"urn:ïsbn:0451450523", true, true},
+		{"mailto:jöhn@example.com", true, true},
+		{"/pâth/tö/résource", false, true},
+		{"#ƒrägmênt", false, true},
+		{"//hôst/", false, true},
+		{"âππ", false, true},
+		// private use characters are only allowed in the query
+		{"http://example.com/?", true, true},
+		{"http://example.com/", false, false},
+		{"http://example.com/a b", false, false},
+		{"http://example.com/%zz", false, false},
+		{"http://example.com:8o/", false, false},
+		{"http://[2001:db8::7/", false, false},
+		{"http://2001:0db8:85a3:0000:0000:8a2e:0370:7334", false, false},
+		{"1http://example.com", false, false},
+		{`\\WINDOWS\filëßåré`, false, false},
+		{"http://example.com/￾", false, false},
+	}
+
+	for i, c := range cases {
+		if got := isValidIri(c.value) == nil; got != c.iri {
+			t.Errorf("case %d: expected %q to be a valid iri: %t", i, c.value, c.iri)
+		}
+		if got := isValidIriRef(c.value) == nil; got != c.iriRef {
+			t.Errorf("case %d: expected %q to be a valid iri-reference: %t", i, c.value, c.iriRef)
+		}
+	}
+}
+
+func TestURIRejectsNonASCII(t *testing.T) {
+	for _, str := range []string{"http://ƒøø.ßår/", "http://example.com/?q=π", "/pâth"} {
+		if err := isValidURIRef(str); err == nil {
+			t.Errorf("expected %q to be an invalid uri-reference", str)
+		}
+		if err := isValidIriRef(str); err != nil {
+			t.Errorf("expected %q to be a valid iri-reference, got: %s", str, err)
+		}
+	}
+}
+
+func TestURITemplateFormat(t *testing.T) {
+	cases := []struct {
+		template string
+		valid    bool
+	}{
+		{"http://example.com/dictionary", true},
+		{"http://example.com/{var}", true},
+		{"http://example.com/{+path}/here", true},
+		{"http://example.com/{#section}", true},
+		{"http://example.com{.ext}", true},
+		{"http://example.com{/seg1,seg2}", true},
+		{"http://example.com/map{;x,y}", true},
+		{"http://example.com/search{?x,y}", true},
+		{"http://example.com/search?fixed=yes{&x}", true},
+		{"http://example.com/{term:1}/{term}", true},
+		{"http://example.com/{list*}", true},
+		{"http://example.com/{keys:9999}", true},
+		{"{a.b_c,%20d}", true},
+		{"/pâth/{värs}", false},
+		{"/pâth/{vars}", true},
+
+		{"{", false},
+		{"}", false},
+		{"http://example.com/{term", false},
+		{"http://example.com/term}", false},
+		{"http://example.com/{{term}}", false},
+		{"{}", false},
+		{"{+}", false},
+		{"{?x,}", false},
+		{"{=reserved}", false},
+		{"{|reserved}", false},
+		{"{a..b}", false},
+		{"{.a.}", false},
+		{"{var:0}", false},
+		{"{var:10000}", false},
+		{"{var:}", false},
+		{"{var*:3}", false},
+		{"{va r}", false},
+		{"{%2}", false},
+		{"http://example.com/a b", false},
+		{"http://example.com/<{x}>", false},
+		{"http://example.com/100%", false},
+	}
+
+	for i, c := range cases {
+		err := isValidURITemplate(c.template)
+		if (err == nil) != c.valid {
+			t.Errorf("case %d: expected %q to be a valid uri template: %t, got error: %v", i, c.template, c.valid, err)
+		}
+	}
+}
+
+func TestUUIDFormat(t *testing.T) {
+	cases := []struct {
+		uuid  string
+		valid bool
+	}{
+		{"2eb8aa08-aa98-11ea-b4aa-73b441d16380", true},
+		{"2EB8AA08-AA98-11EA-B4AA-73B441D16380", true},
+		{"00000000-0000-0000-0000-000000000000", true},
+		{"2eb8aa08aa9811eab4aa73b441d16380", false},
+		{"2eb8aa08-aa98-11ea-b4aa-73b441d1638", false},
+		{"2eb8aa08-aa98-11ea-b4aa-73b441d1638g", false},
+		{"2eb8aa0-8aa98-11ea-b4aa-73b441d16380", false},
+		{"{2eb8aa08-aa98-11ea-b4aa-73b441d16380}", false},
+	}
+
+	for i, c := range cases {
+		err := isValidUUID(c.uuid)
+		if (err == nil) != c.valid {
+			t.Errorf("case %d: expected %q to be a valid uuid: %t, got error: %v", i, c.uuid, c.valid, err)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package jsonschema
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"strconv"
 )
 
 // MultipleOf MUST be a number, strictly greater than 0.
@@ -16,14 +19,79 @@ func NewMultipleOf() Validator {
 
 // Validate implements the Validator interface for MultipleOf
 func (m MultipleOf) Validate(propPath string, data interface{}, errs *[]ValError) {
-	if num, ok := data.(float64); ok {
-		div := num / float64(m)
-		if float64(int(div)) != div {
+	if num, ok := numberString(data); ok {
+		if !isMultipleOf(num, float64(m)) {
 			AddError(errs, propPath, data, fmt.Sprintf("must be a multiple of %f", m))
 		}
 	}
 }
 
+// numberString gives the decimal representation of a numeric instance,
+// either a float64 or a json.Number, as decoded with UseNumber
+func numberString(data interface{}) (string, bool) {
+	switch v := data.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case json.Number:
+		return string(v), true
+	}
+	return "", false
+}
+
+// compareNumber compares a numeric instance to bound, giving -1, 0, or 1.
+// json.Numbers are compared exactly to the shortest decimal representation
+// of bound, so integers too large for a float64 aren't rounded onto the
+// bound, and decimals like 1.1 equal a bound written the same way
+func compareNumber(data interface{}, bound float64) (int, bool) {
+	switch v := data.(type) {
+	case float64:
+		switch {
+		case v < bound:
+			return -1, true
+		case v > bound:
+			return 1, true
+		}
+		return 0, true
+	case json.Number:
+		n, ok := new(big.Rat).SetString(string(v))
+		if !ok {
+			return 0, false
+		}
+		b, ok := new(big.Rat).SetString(strconv.FormatFloat(bound, 'g', -1, 64))
+		if !ok {
+			return 0, false
+		}
+		return n.Cmp(b), true
+	}
+	return 0, false
+}
+
+// isMultipleOf checks divisibility using exact rational arithmetic on the
+// decimal representation of num and the shortest decimal representation
+// of div, so values like 0.3 are multiples of 0.1 despite their binary
+// representations, and large integers don't overflow
+func isMultipleOf(num string, div float64) bool {
+	n, ok := new(big.Rat).SetString(num)
+	if !ok {
+		return false
+	}
+	d, ok := new(big.Rat).SetString(strconv.FormatFloat(div, 'g', -1, 64))
+	if !ok || d.Sign() == 0 {
+		return false
+	}
+	return n.Quo(n, d).IsInt()
+}
+
+// addBoundError records a numeric instance that violates a bound, with
+// the comparison it failed and the bound as error params
+func addBoundError(errs *[]ValError, propPath string, num interface{}, comparison string, bound float64) {
+	AddError(errs, propPath, num, fmt.Sprintf("must be %s %v", comparison, bound))
+	(*errs)[len(*errs)-1].Params = map[string]interface{}{
+		"comparison": comparison,
+		"limit":      bound,
+	}
+}
+
 // Maximum MUST be a number, representing an inclusive upper limit
 // for a numeric instance.
 // If the instance is a number, then this keyword validates only if the instance is less than or exactly equal to "Maximum".
@@ -36,9 +104,9 @@ func NewMaximum() Validator {
 
 // Validate implements the Validator interface for Maximum
 func (m Maximum) Validate(propPath string, data interface{}, errs *[]ValError) {
-	if num, ok := data.(float64); ok {
-		if num > float64(m) {
-			AddError(errs, propPath, data, fmt.Sprintf("must be less than or equal to %f", m))
+	if c, ok := compareNumber(data, float64(m)); ok {
+		if c > 0 {
+			addBoundError(errs, propPath, data, "<=", float64(m))
 		}
 	}
 }
@@ -55,9 +123,9 @@ func NewExclusiveMaximum() Validator {
 
 // Validate implements the Validator interface for ExclusiveMaximum
 func (m ExclusiveMaximum) Validate(propPath string, data interface{}, errs *[]ValError) {
-	if num, ok := data.(float64); ok {
-		if num >= float64(m) {
-			AddError(errs, propPath, data, fmt.Sprintf("must be less than %f", m))
+	if c, ok := compareNumber(data, float64(m)); ok {
+		if c >= 0 {
+			addBoundError(errs, propPath, data, "<", float64(m))
 		}
 	}
 }
@@ -73,9 +141,9 @@ func NewMinimum() Validator {
 
 // Validate implements the Validator interface for Minimum
 func (m Minimum) Validate(propPath string, data interface{}, errs *[]ValError) {
-	if num, ok := data.(float64); ok {
-		if num < float64(m) {
-			AddError(errs, propPath, data, fmt.Sprintf("must be greater than or equal to %f", m))
+	if c, ok := compareNumber(data, float64(m)); ok {
+		if c < 0 {
+			addBoundError(errs, propPath, data, ">=", float64(m))
 		}
 	}
 }
@@ -91,9 +159,42 @@ func NewExclusiveMinimum() Validator {
 
 // Validate implements the Validator interface for ExclusiveMinimum
 func (m ExclusiveMinimum) Validate(propPath string, data interface{}, errs *[]ValError) {
-	if num, ok := data.(float64); ok {
-		if num <= float64(m) {
-			AddError(errs, propPath, data, fmt.Sprintf("must be greater than %f", m))
+	if c, ok := compareNumber(data, float64(m)); ok {
+		if c <= 0 {
+			addBoundError(errs, propPath, data, ">", float64(m))
+		}
+	}
+}
+
+// exclusiveBound is the draft-04 form of "exclusiveMinimum" and
+// "exclusiveMaximum", a boolean making the sibling "minimum" or "maximum"
+// exclusive. It only has an effect in draft-04 schemas, and marshals back
+// to its boolean
+type exclusiveBound struct {
+	exclusive bool
+	max       bool
+	// limit is the value of the sibling bound, nil without one
+	limit  *float64
+	draft4 bool
+}
+
+// Validate implements the Validator interface for exclusiveBound. Values
+// beyond the limit are reported by the sibling bound, so only values equal
+// to it fail
+func (b exclusiveBound) Validate(propPath string, data interface{}, errs *[]ValError) {
+	if !b.draft4 || !b.exclusive || b.limit == nil {
+		return
+	}
+	if c, ok := compareNumber(data, *b.limit); ok && c == 0 {
+		if b.max {
+			addBoundError(errs, propPath, data, "<", *b.limit)
+		} else {
+			addBoundError(errs, propPath, data, ">", *b.limit)
 		}
 	}
 }
+
+// MarshalJSON implements the json.Marshaler interface for exclusiveBound
+func (b exclusiveBound) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.exclusive)
+}
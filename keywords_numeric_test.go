@@ -0,0 +1,263 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"github.com/json-iterator/go"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMultipleOf(t *testing.T) {
+	cases := []struct {
+		schema, doc string
+		valid       bool
+	}{
+		{`{"multipleOf": 0.1}`, `0.3`, true},
+		{`{"multipleOf": 0.1}`, `0.35`, false},
+		{`{"multipleOf": 0.0001}`, `0.0075`, true},
+		{`{"multipleOf": 0.0001}`, `0.00751`, false},
+		{`{"multipleOf": 0.01}`, `19.99`, true},
+		{`{"multipleOf": 5}`, `100000000000000000000`, true},
+		{`{"multipleOf": 7}`, `100000000000000000000`, false},
+		{`{"multipleOf": 1000000000000}`, `5000000000000000000000`, true},
+		{`{"multipleOf": 2}`, `-8`, true},
+		{`{"multipleOf": 2}`, `0`, true},
+	}
+
+	for i, c := range cases {
+		errs, err := Must(c.schema).ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err.Error())
+			continue
+		}
+		if valid := len(errs) == 0; valid != c.valid {
+			t.Errorf("case %d: %s multipleOf check expected valid: %t, got: %v", i, c.doc, c.valid, errs)
+		}
+	}
+}
+
+func TestBoundMessages(t *testing.T) {
+	cases := []struct {
+		schema, doc string
+		message     string
+		comparison  string
+		limit       float64
+	}{
+		{`{"minimum": 0, "exclusiveMinimum": -5}`, `-1`, "must be >= 0", ">=", 0},
+		{`{"exclusiveMinimum": 0}`, `0`, "must be > 0", ">", 0},
+		{`{"maximum": 2.5}`, `3`, "must be <= 2.5", "<=", 2.5},
+		{`{"exclusiveMaximum": 10}`, `10`, "must be < 10", "<", 10},
+	}
+
+	for i, c := range cases {
+		errs, err := Must(c.schema).ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != 1 {
+			t.Errorf("case %d: expected 1 error, got: %v", i, errs)
+			continue
+		}
+		e := errs[0]
+		if e.Message != c.message {
+			t.Errorf("case %d: message mismatch. expected: %s, got: %s", i, c.message, e.Message)
+		}
+		if e.Params["comparison"] != c.comparison || e.Params["limit"] != c.limit {
+			t.Errorf("case %d: params mismatch. expected: %s %v, got: %v", i, c.comparison, c.limit, e.Params)
+		}
+	}
+
+	if errs, _ := Must(`{"minimum": 0}`).ValidateBytes([]byte(`0`)); len(errs) != 0 {
+		t.Errorf("expected a value equal to an inclusive bound to pass, got: %v", errs)
+	}
+}
+
+func TestDraft4ExclusiveBounds(t *testing.T) {
+	cases := []struct {
+		schema, doc string
+		expect      string
+	}{
+		{`{"$schema": "http://json-schema.org/draft-04/schema#", "minimum": 1, "exclusiveMinimum": true}`, `1`, "/: must be > 1"},
+		{`{"$schema": "http://json-schema.org/draft-04/schema#", "minimum": 1, "exclusiveMinimum": true}`, `0`, "/: must be >= 1"},
+		{`{"$schema": "http://json-schema.org/draft-04/schema#", "minimum": 1, "exclusiveMinimum": true}`, `1.5`, ""},
+		{`{"$schema": "http://json-schema.org/draft-04/schema#", "minimum": 1, "exclusiveMinimum": false}`, `1`, ""},
+		{`{"$schema": "http://json-schema.org/draft-04/schema#", "exclusiveMaximum": true}`, `1`, ""},
+		{`{"id": "http://example.com/bounds.json", "properties": {"a": {"maximum": 3, "exclusiveMaximum": true}}}`, `{"a": 3}`, "/a: must be < 3"},
+		// other drafts give the boolean form no meaning
+		{`{"minimum": 1, "exclusiveMinimum": true}`, `1`, ""},
+		{`{"$schema": "http://json-schema.org/draft-07/schema#", "id": "http://example.com/bounds.json", "maximum": 3, "exclusiveMaximum": true}`, `3`, ""},
+	}
+	for i, c := range cases {
+		errs, err := Must(c.schema).ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := errorLines(errs); got != c.expect {
+			t.Errorf("case %d: expected errors:\n%s\ngot:\n%s", i, c.expect, got)
+		}
+	}
+
+	// the boolean form marshals back as it was written
+	schemas := []string{
+		`{"id":"http://example.com/bounds.json","exclusiveMinimum":true,"minimum":1}`,
+		`{"exclusiveMaximum":false,"exclusiveMinimum":false}`,
+		`{"exclusiveMaximum":true}`,
+	}
+	for i, schema := range schemas {
+		data, err := jsoniter.Marshal(Must(schema))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var expect, got interface{}
+		if err := jsoniter.Unmarshal([]byte(schema), &expect); err != nil {
+			t.Fatal(err)
+		}
+		if err := jsoniter.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expect, got) {
+			t.Errorf("case %d: expected %s, got: %s", i, schema, data)
+		}
+	}
+
+	// and keeps its meaning through a projection
+	projected, err := Must(`{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"properties": { "a": { "minimum": 3, "exclusiveMinimum": true }, "b": {} }
+	}`).Project([]string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, _ := projected.ValidateBytes([]byte(`{"a": 3}`))
+	if got := errorLines(errs); got != "/a: must be > 3" {
+		t.Errorf("expected the projection to keep the exclusive bound, got errors:\n%s", got)
+	}
+}
+
+func TestDraft4Integers(t *testing.T) {
+	draft4 := Must(`{"$schema": "http://json-schema.org/draft-04/schema#", "type": "integer"}`)
+	draft7 := Must(`{"$schema": "http://json-schema.org/draft-07/schema#", "type": "integer"}`)
+	cases := []struct {
+		rs     *RootSchema
+		doc    interface{}
+		expect string
+	}{
+		{draft4, json.Number("1"), ""},
+		{draft4, json.Number("1.0"), "/: type should be integer"},
+		{draft4, json.Number("1e2"), "/: type should be integer"},
+		// decoded float64 values don't say how they were written
+		{draft4, 1.0, ""},
+		{draft7, json.Number("1.0"), ""},
+	}
+	for i, c := range cases {
+		errs := []ValError{}
+		c.rs.Validate("/", c.doc, &errs)
+		if got := errorLines(errs); got != c.expect {
+			t.Errorf("case %d: expected errors:\n%s\ngot:\n%s", i, c.expect, got)
+		}
+	}
+}
+
+func TestUseNumberInstances(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"count": {"type": "integer", "minimum": 1, "multipleOf": 3},
+			"ratio": {"type": "number", "exclusiveMaximum": 1},
+			"score": {"minimum": 1.1},
+			"id": {"maximum": 9007199254740992},
+			"version": {"const": 2},
+			"tags": {"uniqueItems": true}
+		}
+	}`)
+	decoder := jsoniter.Config{UseNumber: true}.Froze()
+
+	cases := []struct {
+		doc  string
+		errs []string
+	}{
+		{`{"count": 9, "ratio": 0.3, "id": 9007199254740992, "version": 2, "tags": [1, 2]}`, nil},
+		{`{"count": 0}`, []string{"/count: must be >= 1"}},
+		{`{"count": 7}`, []string{"/count: must be a multiple of 3.000000"}},
+		{`{"count": 4.5}`, []string{"/count: type should be integer", "/count: must be a multiple of 3.000000"}},
+		{`{"ratio": 1}`, []string{"/ratio: must be < 1"}},
+		{`{"score": 1.1}`, nil},
+		{`{"score": 1.09}`, []string{"/score: must be >= 1.1"}},
+		{`{"id": 9007199254740993}`, []string{"/id: must be <= 9.007199254740992e+15"}},
+		{`{"version": 2.0}`, nil},
+		{`{"tags": [1, 1.0]}`, []string{"/tags: items 0 and 1 are duplicates"}},
+		{`{"tags": [0, -0]}`, []string{"/tags: items 0 and 1 are duplicates"}},
+		{`{"tags": [9007199254740992, 9007199254740993]}`, nil},
+		{`{"version": 2.000000000000000001}`, []string{"/version: must equal 2"}},
+	}
+
+	for i, c := range cases {
+		var doc interface{}
+		if err := decoder.UnmarshalFromString(c.doc, &doc); err != nil {
+			t.Fatal(err)
+		}
+		want := append([]string{}, c.errs...)
+		sort.Strings(want)
+
+		errs := []ValError{}
+		rs.Validate("/", doc, &errs)
+		if got := errorLines(errs); got != strings.Join(want, "\n") {
+			t.Errorf("case %d: expected errors:\n%s\ngot:\n%s", i, strings.Join(want, "\n"), got)
+		}
+
+		errs, err := rs.ValidateValue(doc)
+		if err != nil {
+			t.Errorf("case %d: error validating value: %s", i, err.Error())
+			continue
+		}
+		if got := errorLines(errs); got != strings.Join(want, "\n") {
+			t.Errorf("case %d: ValidateValue expected errors:\n%s\ngot:\n%s", i, strings.Join(want, "\n"), got)
+		}
+	}
+}
+
+// errorLines gives the sorted paths and messages of errs, one per line
+func errorLines(errs []ValError) string {
+	lines := []string{}
+	for _, e := range errs {
+		lines = append(lines, e.PropertyPath+": "+e.Message)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+func TestExactNumberEquality(t *testing.T) {
+	decoder := jsoniter.Config{UseNumber: true}.Froze()
+	cases := []struct {
+		schema, doc string
+		expect      string
+	}{
+		{`{"const": 9007199254740992}`, `9007199254740993`, "/: must equal 9007199254740992"},
+		{`{"const": 9007199254740992}`, `9007199254740992.0`, ""},
+		{`{"enum": [9007199254740992, "a"]}`, `9007199254740993`, `/: should be one of [9007199254740992, "a"]`},
+		{`{"uniqueItems": true}`, `[9007199254740992, 9007199254740993]`, ""},
+		{`{"uniqueItems": true}`, `[0, -0]`, "/: items 0 and 1 are duplicates"},
+		{`{"uniqueItems": true}`, `[1, 1e0, 10e-1]`, "/: items 0 and 1 are duplicates"},
+	}
+	for i, c := range cases {
+		var doc interface{}
+		if err := decoder.UnmarshalFromString(c.doc, &doc); err != nil {
+			t.Fatal(err)
+		}
+		errs := []ValError{}
+		Must(c.schema).Validate("/", doc, &errs)
+		if got := errorLines(errs); got != c.expect {
+			t.Errorf("case %d: expected errors:\n%s\ngot:\n%s", i, c.expect, got)
+		}
+	}
+
+	// -0 decoded as a float64 equals 0
+	errs := []ValError{}
+	Must(`{"uniqueItems": true}`).Validate("/", []interface{}{0.0, math.Copysign(0, -1)}, &errs)
+	if got := errorLines(errs); got != "/: items 0 and 1 are duplicates" {
+		t.Errorf("expected 0 and -0 to be duplicates, got errors:\n%s", got)
+	}
+}
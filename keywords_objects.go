@@ -1,11 +1,11 @@
 package jsonschema
 
 import (
-	"github.com/json-iterator/go"
 	"fmt"
 	"github.com/qri-io/jsonpointer"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 // MaxProperties MUST be a non-negative integer.
@@ -38,6 +38,15 @@ func NewMinProperties() Validator {
 
 // Validate implements the validator interface for minProperties
 func (m minProperties) Validate(propPath string, data interface{}, errs *[]ValError) {
+	m.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for
+// minProperties, which is skipped by ValidateOptions.Partial
+func (m minProperties) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
+	if vc.Options.Partial {
+		return
+	}
 	if obj, ok := data.(map[string]interface{}); ok {
 		if len(obj) < int(m) {
 			AddError(errs, propPath, data, fmt.Sprintf("%d object Properties below %d minimum", len(obj), m))
@@ -57,10 +66,33 @@ func NewRequired() Validator {
 
 // Validate implements the validator interface for Required
 func (r Required) Validate(propPath string, data interface{}, errs *[]ValError) {
+	r.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for Required.
+// Missing properties are reported at the path of the object, or at the
+// path the property should have with ValidateOptions.RequiredChildPath.
+// ValidateOptions.Partial skips the check, and names are matched as
+// ValidateOptions.CaseInsensitiveProperties configures
+func (r Required) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
+	if vc.Options.Partial {
+		return
+	}
 	if obj, ok := data.(map[string]interface{}); ok {
 		for _, key := range r {
-			if val, ok := obj[key]; val == nil && !ok {
-				AddError(errs, propPath, data, fmt.Sprintf(`"%s" value is required`, key))
+			if _, ok := lookupProperty(obj, key, vc.Options.CaseInsensitiveProperties); !ok {
+				msg := fmt.Sprintf(`"%s" value is required`, key)
+				if !vc.Options.RequiredChildPath {
+					AddError(errs, propPath, data, msg)
+					continue
+				}
+				jp, err := jsonpointer.Parse(propPath)
+				if err != nil {
+					AddError(errs, propPath, nil, "invalid property path")
+					return
+				}
+				d, _ := jp.Descendant(key)
+				AddError(errs, d.String(), nil, msg)
 			}
 		}
 	}
@@ -72,7 +104,7 @@ func (r Required) JSONProp(name string) interface{} {
 	if err != nil {
 		return nil
 	}
-	if idx > len(r) || idx < 0 {
+	if idx >= len(r) || idx < 0 {
 		return nil
 	}
 	return r[idx]
@@ -93,6 +125,12 @@ func NewProperties() Validator {
 
 // Validate implements the validator interface for Properties
 func (p Properties) Validate(propPath string, data interface{}, errs *[]ValError) {
+	p.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for Properties.
+// Names are matched as ValidateOptions.CaseInsensitiveProperties configures
+func (p Properties) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	jp, err := jsonpointer.Parse(propPath)
 	if err != nil {
 		AddError(errs, propPath, nil, "invalid property path")
@@ -100,15 +138,57 @@ func (p Properties) Validate(propPath string, data interface{}, errs *[]ValError
 	}
 
 	if obj, ok := data.(map[string]interface{}); ok {
+		parent, parentPath := vc.enter(obj, propPath)
+		defer vc.leave(parent, parentPath)
+		if vc.Options.CaseInsensitiveProperties {
+			for name, sch := range p {
+				if key, ok := lookupProperty(obj, name, true); ok && sch != nil {
+					d, _ := jp.Descendant(key)
+					prev := vc.enterSchema(name)
+					validateProperty(vc, sch, key, d.String(), obj[key], errs)
+					vc.leaveSchema(prev)
+					vc.evaluateProperty(propPath, key)
+				}
+			}
+			return
+		}
 		for key, val := range obj {
 			if p[key] != nil {
 				d, _ := jp.Descendant(key)
-				p[key].Validate(d.String(), val, errs)
+				prev := vc.enterSchema(key)
+				validateProperty(vc, p[key], key, d.String(), val, errs)
+				vc.leaveSchema(prev)
+				vc.evaluateProperty(propPath, key)
 			}
 		}
 	}
 }
 
+// validateProperty validates the value of the property key against sch,
+// naming the property when sch is false
+func validateProperty(vc *ValidationContext, sch *Schema, key, propPath string, val interface{}, errs *[]ValError) {
+	if sch.schemaType == schemaTypeFalse {
+		falseSchemaError(vc, propPath, val, errs, fmt.Sprintf("property %q is not allowed", key))
+		return
+	}
+	sch.ValidateContext(vc, propPath, val, errs)
+}
+
+// lookupProperty finds the key of obj that matches the property name. With
+// fold set a key that matches regardless of case will do, the first of them
+// in sorted order unless one matches exactly
+func lookupProperty(obj map[string]interface{}, name string, fold bool) (key string, ok bool) {
+	if _, ok := obj[name]; ok || !fold {
+		return name, ok
+	}
+	for k := range obj {
+		if strings.EqualFold(k, name) && (!ok || k < key) {
+			key, ok = k, true
+		}
+	}
+	return key, ok
+}
+
 // JSONProp implements JSON property name indexing for Properties
 func (p Properties) JSONProp(name string) interface{} {
 	return p[name]
@@ -147,6 +227,11 @@ type patternSchema struct {
 
 // Validate implements the validator interface for PatternProperties
 func (p PatternProperties) Validate(propPath string, data interface{}, errs *[]ValError) {
+	p.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for PatternProperties
+func (p PatternProperties) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	jp, err := jsonpointer.Parse(propPath)
 	if err != nil {
 		AddError(errs, propPath, nil, "invalid property path")
@@ -154,11 +239,16 @@ func (p PatternProperties) Validate(propPath string, data interface{}, errs *[]V
 	}
 
 	if obj, ok := data.(map[string]interface{}); ok {
+		parent, parentPath := vc.enter(obj, propPath)
+		defer vc.leave(parent, parentPath)
 		for key, val := range obj {
 			for _, ptn := range p {
 				if ptn.re.Match([]byte(key)) {
 					d, _ := jp.Descendant(key)
-					ptn.schema.Validate(d.String(), val, errs)
+					prev := vc.enterSchema(ptn.key)
+					validateProperty(vc, ptn.schema, key, d.String(), val, errs)
+					vc.leaveSchema(prev)
+					vc.evaluateProperty(propPath, key)
 				}
 			}
 		}
@@ -179,8 +269,8 @@ func (p PatternProperties) JSONProp(name string) interface{} {
 // JSONChildren implements the JSONContainer interface for PatternProperties
 func (p PatternProperties) JSONChildren() (res map[string]JSONPather) {
 	res = map[string]JSONPather{}
-	for i, pp := range p {
-		res[strconv.Itoa(i)] = pp.schema
+	for _, pp := range p {
+		res[pp.key] = pp.schema
 	}
 	return
 }
@@ -188,7 +278,7 @@ func (p PatternProperties) JSONChildren() (res map[string]JSONPather) {
 // UnmarshalJSON implements the jsoniter.Unmarshaler interface for PatternProperties
 func (p *PatternProperties) UnmarshalJSON(data []byte) error {
 	var props map[string]*Schema
-	if err := jsoniter.Unmarshal(data, &props); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, &props); err != nil {
 		return err
 	}
 
@@ -217,7 +307,7 @@ func (p PatternProperties) MarshalJSON() ([]byte, error) {
 	for _, prop := range p {
 		obj[prop.key] = prop.schema
 	}
-	return jsoniter.Marshal(obj)
+	return DefaultEncoder.Marshal(obj)
 }
 
 // AdditionalProperties determines how child instances validate for objects, and does not directly validate the immediate instance itself.
@@ -238,6 +328,11 @@ func NewAdditionalProperties() Validator {
 
 // Validate implements the validator interface for AdditionalProperties
 func (ap AdditionalProperties) Validate(propPath string, data interface{}, errs *[]ValError) {
+	ap.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for AdditionalProperties
+func (ap AdditionalProperties) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	jp, err := jsonpointer.Parse(propPath)
 	if err != nil {
 		AddError(errs, propPath, nil, "invalid property path")
@@ -245,6 +340,8 @@ func (ap AdditionalProperties) Validate(propPath string, data interface{}, errs
 	}
 
 	if obj, ok := data.(map[string]interface{}); ok {
+		parent, parentPath := vc.enter(obj, propPath)
+		defer vc.leave(parent, parentPath)
 	KEYS:
 		for key, val := range obj {
 			if ap.Properties != nil {
@@ -252,6 +349,11 @@ func (ap AdditionalProperties) Validate(propPath string, data interface{}, errs
 					if propKey == key {
 						continue KEYS
 					}
+					if vc.Options.CaseInsensitiveProperties && strings.EqualFold(propKey, key) {
+						if match, _ := lookupProperty(obj, propKey, true); match == key {
+							continue KEYS
+						}
+					}
 				}
 			}
 			if ap.patterns != nil {
@@ -263,7 +365,12 @@ func (ap AdditionalProperties) Validate(propPath string, data interface{}, errs
 			}
 			// c := len(*errs)
 			d, _ := jp.Descendant(key)
-			ap.Schema.Validate(d.String(), val, errs)
+			vc.evaluateProperty(propPath, key)
+			if ap.Schema.schemaType == schemaTypeFalse {
+				AddError(errs, d.String(), val, fmt.Sprintf("additional property %q is not allowed", key))
+				continue
+			}
+			ap.Schema.ValidateContext(vc, d.String(), val, errs)
 			// if len(*errs) > c {
 			// 	// fmt.Sprintf("object key %s AdditionalProperties error: %s", key, err.Error())
 			// 	return
@@ -275,7 +382,7 @@ func (ap AdditionalProperties) Validate(propPath string, data interface{}, errs
 // UnmarshalJSON implements the jsoniter.Unmarshaler interface for AdditionalProperties
 func (ap *AdditionalProperties) UnmarshalJSON(data []byte) error {
 	sch := &Schema{}
-	if err := jsoniter.Unmarshal(data, sch); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, sch); err != nil {
 		return err
 	}
 	// fmt.Println("unmarshal:", sch.Ref)
@@ -298,7 +405,7 @@ func (ap *AdditionalProperties) JSONChildren() (res map[string]JSONPather) {
 
 // MarshalJSON implements jsoniter.Marshaler for AdditionalProperties
 func (ap AdditionalProperties) MarshalJSON() ([]byte, error) {
-	return jsoniter.Marshal(ap.Schema)
+	return DefaultEncoder.Marshal(ap.Schema)
 }
 
 // Dependencies : [CREF1]
@@ -321,6 +428,11 @@ func NewDependencies() Validator {
 
 // Validate implements the validator interface for Dependencies
 func (d Dependencies) Validate(propPath string, data interface{}, errs *[]ValError) {
+	d.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for Dependencies
+func (d Dependencies) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	jp, err := jsonpointer.Parse(propPath)
 	if err != nil {
 		AddError(errs, propPath, nil, "invalid property path")
@@ -331,7 +443,11 @@ func (d Dependencies) Validate(propPath string, data interface{}, errs *[]ValErr
 		for key, val := range d {
 			if obj[key] != nil {
 				d, _ := jp.Descendant(key)
-				val.Validate(d.String(), obj, errs)
+				start := len(*errs)
+				prev := vc.enterSchema(key)
+				val.ValidateContext(vc, d.String(), obj, errs)
+				vc.locateErrors(*errs, start)
+				vc.leaveSchema(prev)
 			}
 		}
 	}
@@ -362,9 +478,14 @@ type Dependency struct {
 
 // Validate implements the validator interface for Dependency
 func (d Dependency) Validate(propPath string, data interface{}, errs *[]ValError) {
+	d.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for Dependency
+func (d Dependency) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	if obj, ok := data.(map[string]interface{}); ok {
 		if d.schema != nil {
-			d.schema.Validate(propPath, data, errs)
+			d.schema.ValidateContext(vc, propPath, data, errs)
 		} else if len(d.props) > 0 {
 			for _, k := range d.props {
 				if obj[k] == nil {
@@ -378,12 +499,12 @@ func (d Dependency) Validate(propPath string, data interface{}, errs *[]ValError
 // UnmarshalJSON implements the jsoniter.Unmarshaler interface for Dependencies
 func (d *Dependency) UnmarshalJSON(data []byte) error {
 	props := []string{}
-	if err := jsoniter.Unmarshal(data, &props); err == nil {
+	if err := DefaultDecoder.Unmarshal(data, &props); err == nil {
 		*d = Dependency{props: props}
 		return nil
 	}
 	sch := &Schema{}
-	err := jsoniter.Unmarshal(data, sch)
+	err := DefaultDecoder.Unmarshal(data, sch)
 
 	if err == nil {
 		*d = Dependency{schema: sch}
@@ -394,9 +515,9 @@ func (d *Dependency) UnmarshalJSON(data []byte) error {
 // MarshalJSON implements jsoniter.Marshaler for Dependency
 func (d Dependency) MarshalJSON() ([]byte, error) {
 	if d.schema != nil {
-		return jsoniter.Marshal(d.schema)
+		return DefaultEncoder.Marshal(d.schema)
 	}
-	return jsoniter.Marshal(d.props)
+	return DefaultEncoder.Marshal(d.props)
 }
 
 // PropertyNames checks if every property name in the instance validates against the provided schema
@@ -412,6 +533,11 @@ func NewPropertyNames() Validator {
 
 // Validate implements the validator interface for PropertyNames
 func (p PropertyNames) Validate(propPath string, data interface{}, errs *[]ValError) {
+	p.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for PropertyNames
+func (p PropertyNames) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	jp, err := jsonpointer.Parse(propPath)
 	if err != nil {
 		AddError(errs, propPath, nil, "invalid property path")
@@ -420,10 +546,12 @@ func (p PropertyNames) Validate(propPath string, data interface{}, errs *[]ValEr
 
 	sch := Schema(p)
 	if obj, ok := data.(map[string]interface{}); ok {
+		parent, parentPath := vc.enter(obj, propPath)
+		defer vc.leave(parent, parentPath)
 		for key := range obj {
 			// TODO - adjust error message & prop path
 			d, _ := jp.Descendant(key)
-			sch.Validate(d.String(), key, errs)
+			sch.ValidateContext(vc, d.String(), key, errs)
 		}
 	}
 }
@@ -441,7 +569,7 @@ func (p PropertyNames) JSONChildren() (res map[string]JSONPather) {
 // UnmarshalJSON implements the jsoniter.Unmarshaler interface for PropertyNames
 func (p *PropertyNames) UnmarshalJSON(data []byte) error {
 	var sch Schema
-	if err := jsoniter.Unmarshal(data, &sch); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, &sch); err != nil {
 		return err
 	}
 	*p = PropertyNames(sch)
@@ -450,5 +578,5 @@ func (p *PropertyNames) UnmarshalJSON(data []byte) error {
 
 // MarshalJSON implements jsoniter.Marshaler for PropertyNames
 func (p PropertyNames) MarshalJSON() ([]byte, error) {
-	return jsoniter.Marshal(Schema(p))
+	return DefaultEncoder.Marshal(Schema(p))
 }
@@ -0,0 +1,242 @@
+package jsonschema
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestPropertyNamesAdditionalProperties(t *testing.T) {
+	rs := Must(`{
+		"properties": { "ok_key": {} },
+		"propertyNames": { "pattern": "^[a-z_]+$" },
+		"additionalProperties": false
+	}`)
+
+	cases := []struct {
+		input  string
+		errors []string
+	}{
+		{`{"ok_key": 1}`, nil},
+		// a key that's both malformed and undeclared fails both keywords independently
+		{`{"ok_key": 1, "Bad-Key": 2}`, []string{
+			`/Bad-Key: "Bad-Key" regexp pattrn ^[a-z_]+$ mismatch on string: Bad-Key`,
+			`/Bad-Key: 2 additional property "Bad-Key" is not allowed`,
+		}},
+		{`{"other": 3}`, []string{`/other: 3 additional property "other" is not allowed`}},
+	}
+
+	for i, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.input))
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err.Error())
+			continue
+		}
+		if len(errs) != len(c.errors) {
+			t.Errorf("case %d: error length mismatch. expected: %d, got: %v", i, len(c.errors), errs)
+			continue
+		}
+
+		// validators run in no particular order
+		got := make([]string, len(errs))
+		for j, e := range errs {
+			got[j] = e.Error()
+		}
+		sort.Strings(got)
+		for j := range got {
+			if got[j] != c.errors[j] {
+				t.Errorf("case %d: validation error %d mismatch. expected: %s, got: %s", i, j, c.errors[j], got[j])
+			}
+		}
+	}
+
+	errs, err := rs.ValidateBytes([]byte(`{"Bad-Key": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keywords := map[string]bool{}
+	for _, e := range errs {
+		keywords[e.Keyword] = true
+	}
+	if !keywords["pattern"] || !keywords["additionalProperties"] {
+		t.Errorf("expected errors from both propertyNames and additionalProperties, got: %v", errs)
+	}
+}
+
+func TestRequiredChildPath(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"address": { "type": "object", "required": ["zip", "city"] }
+		},
+		"required": ["address"]
+	}`)
+
+	cases := []struct {
+		doc    string
+		child  bool
+		expect []string
+	}{
+		{`{ "address": {} }`, false, []string{`/address: {} "city" value is required`, `/address: {} "zip" value is required`}},
+		{`{ "address": {} }`, true, []string{`/address/city: "city" value is required`, `/address/zip: "zip" value is required`}},
+		{`{}`, true, []string{`/address: "address" value is required`}},
+	}
+
+	for i, c := range cases {
+		errs, err := rs.ValidateBytesWithOptions([]byte(c.doc), ValidateOptions{RequiredChildPath: c.child})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, len(errs))
+		for j, e := range errs {
+			got[j] = e.Error()
+		}
+		sort.Strings(got)
+		if len(got) != len(c.expect) {
+			t.Errorf("case %d: expected %v, got: %v", i, c.expect, got)
+			continue
+		}
+		for j := range got {
+			if got[j] != c.expect[j] {
+				t.Errorf("case %d: expected %q, got: %q", i, c.expect[j], got[j])
+			}
+		}
+	}
+}
+
+func TestPropertiesPrecedence(t *testing.T) {
+	cases := []struct {
+		schema string
+		input  string
+		errors []string
+	}{
+		// keys matched by "properties" and a pattern are validated by both
+		{`{"properties": {"foo": {"type": "string"}}, "patternProperties": {"^f": {"maxLength": 3}}, "additionalProperties": false}`,
+			`{"foo": "ab"}`, nil},
+		{`{"properties": {"foo": {"type": "string"}}, "patternProperties": {"^f": {"maxLength": 3}}, "additionalProperties": false}`,
+			`{"foo": "abcd"}`, []string{"/foo maxLength"}},
+		{`{"properties": {"foo": {"type": "string"}}, "patternProperties": {"^f": {"maxLength": 3}}, "additionalProperties": false}`,
+			`{"foo": 1}`, []string{"/foo type"}},
+		// a key matching only a pattern isn't additional
+		{`{"properties": {"foo": {}}, "patternProperties": {"^x-": {"type": "integer"}}, "additionalProperties": false}`,
+			`{"x-a": 1}`, nil},
+		{`{"properties": {"foo": {}}, "patternProperties": {"^x-": {"type": "integer"}}, "additionalProperties": false}`,
+			`{"x-a": "s"}`, []string{"/x-a type"}},
+		{`{"patternProperties": {"^x-": {}}, "additionalProperties": false}`,
+			`{"x-a": 1, "bar": 2}`, []string{"/bar additionalProperties"}},
+		// keys matched by neither are validated by an "additionalProperties" schema
+		{`{"properties": {"foo": {}}, "patternProperties": {"^x-": {}}, "additionalProperties": {"type": "boolean"}}`,
+			`{"foo": 1, "x-a": 2, "bar": true}`, nil},
+		{`{"properties": {"foo": {}}, "patternProperties": {"^x-": {}}, "additionalProperties": {"type": "boolean"}}`,
+			`{"foo": 1, "x-a": 2, "bar": 3}`, []string{"/bar type"}},
+		// patterns aren't anchored
+		{`{"patternProperties": {"b": {}}, "additionalProperties": false}`,
+			`{"abc": 1}`, nil},
+	}
+
+	for i, c := range cases {
+		rs := Must(c.schema)
+		errs, err := rs.ValidateBytes([]byte(c.input))
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err.Error())
+			continue
+		}
+		got := make([]string, len(errs))
+		for j, e := range errs {
+			got[j] = e.PropertyPath + " " + e.Keyword
+		}
+		sort.Strings(got)
+		if len(got) != len(c.errors) {
+			t.Errorf("case %d: error mismatch. expected: %v, got: %v", i, c.errors, got)
+			continue
+		}
+		for j := range got {
+			if got[j] != c.errors[j] {
+				t.Errorf("case %d: validation error %d mismatch. expected: %s, got: %s", i, j, c.errors[j], got[j])
+			}
+		}
+	}
+}
+
+func TestAdditionalPropertiesSchemaPaths(t *testing.T) {
+	cases := []struct {
+		schema string
+		input  string
+		errors []string
+	}{
+		{`{"additionalProperties": {"type": "number"}}`, `{"a": 1, "b": 2.5}`, nil},
+		{`{"additionalProperties": {"type": "number"}}`, `{"a": "x"}`, []string{`/a: "x" type should be number`}},
+		{`{"properties": {"name": {"type": "string"}}, "additionalProperties": {"type": "number"}}`,
+			`{"name": "n", "extra": "x", "count": 3}`, []string{`/extra: "x" type should be number`}},
+		{`{"additionalProperties": {"type": "number"}}`, `{"a": "x", "b": true}`, []string{
+			`/a: "x" type should be number`,
+			`/b: true type should be number`,
+		}},
+		// nested objects report the full path to the extra property
+		{`{"properties": {"tags": {"additionalProperties": {"type": "number"}}}}`,
+			`{"tags": {"size": "big"}}`, []string{`/tags/size: "big" type should be number`}},
+		{`{"additionalProperties": {"additionalProperties": {"maximum": 3}}}`,
+			`{"a": {"b": 4}}`, []string{`/a/b: 4 must be <= 3`}},
+	}
+
+	for i, c := range cases {
+		errs, err := Must(c.schema).ValidateBytes([]byte(c.input))
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err.Error())
+			continue
+		}
+		got := make([]string, len(errs))
+		for j, e := range errs {
+			got[j] = e.Error()
+		}
+		sort.Strings(got)
+		if len(got) != len(c.errors) {
+			t.Errorf("case %d: error mismatch. expected: %v, got: %v", i, c.errors, got)
+			continue
+		}
+		for j := range got {
+			if got[j] != c.errors[j] {
+				t.Errorf("case %d: validation error %d mismatch. expected: %s, got: %s", i, j, c.errors[j], got[j])
+			}
+		}
+		for _, e := range errs {
+			if e.Keyword == "" || e.InvalidValue == nil {
+				t.Errorf("case %d: expected error to have a keyword and invalid value, got: %#v", i, e)
+			}
+		}
+	}
+}
+
+func TestCaseInsensitiveProperties(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"firstName": { "type": "string", "minLength": 2 }
+		},
+		"required": ["firstName"],
+		"additionalProperties": false
+	}`)
+
+	cases := []struct {
+		doc    string
+		fold   bool
+		expect string
+	}{
+		{`{ "FirstName": "Jo" }`, true, ``},
+		{`{ "FirstName": "Jo" }`, false, "/: \"firstName\" value is required\n/FirstName: additional property \"FirstName\" is not allowed"},
+		{`{ "FIRSTNAME": "J" }`, true, `/FIRSTNAME: min length of 2 characters required: J`},
+		// an exact match wins over other keys
+		{`{ "firstName": "Jo", "FIRSTNAME": "J" }`, true, `/FIRSTNAME: additional property "FIRSTNAME" is not allowed`},
+		// otherwise the first key in sorted order wins
+		{`{ "firstname": "Jo", "FIRSTNAME": "J" }`, true, "/FIRSTNAME: min length of 2 characters required: J\n/firstname: additional property \"firstname\" is not allowed"},
+	}
+
+	for i, c := range cases {
+		errs, err := rs.ValidateBytesWithOptions([]byte(c.doc), ValidateOptions{CaseInsensitiveProperties: c.fold})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := errorLines(errs); got != c.expect {
+			t.Errorf("case %d: expected errors:\n%s\ngot:\n%s", i, c.expect, got)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package jsonschema
 
 import (
-	"github.com/json-iterator/go"
 	"fmt"
 	"regexp"
 	"unicode/utf8"
@@ -19,8 +18,13 @@ func NewMaxLength() Validator {
 
 // Validate implements the Validator interface for MaxLength
 func (m MaxLength) Validate(propPath string, data interface{}, errs *[]ValError) {
+	m.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for MaxLength
+func (m MaxLength) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	if str, ok := data.(string); ok {
-		if utf8.RuneCountInString(str) > int(m) {
+		if stringLength(vc, str) > int(m) {
 			AddError(errs, propPath, data, fmt.Sprintf("max length of %d characters exceeded: %s", m, str))
 		}
 	}
@@ -39,13 +43,34 @@ func NewMinLength() Validator {
 
 // Validate implements the Validator interface for MinLength
 func (m MinLength) Validate(propPath string, data interface{}, errs *[]ValError) {
+	m.ValidateContext(newValidationContext(nil), propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for MinLength
+func (m MinLength) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
 	if str, ok := data.(string); ok {
-		if utf8.RuneCountInString(str) < int(m) {
+		if stringLength(vc, str) < int(m) {
 			AddError(errs, propPath, data, fmt.Sprintf("min length of %d characters required: %s", m, str))
 		}
 	}
 }
 
+// stringLength gives the length of str in code points, or in UTF-16 code
+// units with ValidateOptions.StringLengthUTF16
+func stringLength(vc *ValidationContext, str string) int {
+	n := utf8.RuneCountInString(str)
+	if vc.Options.StringLengthUTF16 {
+		for _, r := range str {
+			// characters outside the basic multilingual plane are encoded as
+			// a surrogate pair
+			if r > 0xFFFF {
+				n++
+			}
+		}
+	}
+	return n
+}
+
 // Pattern MUST be a string. This string SHOULD be a valid regular expression,
 // according to the ECMA 262 regular expression dialect.
 // A string instance is considered valid if the regular expression matches the instance successfully.
@@ -70,7 +95,7 @@ func (p Pattern) Validate(propPath string, data interface{}, errs *[]ValError) {
 // UnmarshalJSON implements the jsoniter.Unmarshaler interface for Pattern
 func (p *Pattern) UnmarshalJSON(data []byte) error {
 	var str string
-	if err := jsoniter.Unmarshal(data, &str); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, &str); err != nil {
 		return err
 	}
 
@@ -87,5 +112,5 @@ func (p *Pattern) UnmarshalJSON(data []byte) error {
 func (p Pattern) MarshalJSON() ([]byte, error) {
 	re := regexp.Regexp(p)
 	rep := &re
-	return jsoniter.Marshal(rep.String())
+	return DefaultEncoder.Marshal(rep.String())
 }
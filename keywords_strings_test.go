@@ -0,0 +1,66 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestLengthCountsCodePoints(t *testing.T) {
+	cases := []struct {
+		schema, input string
+		valid         bool
+	}{
+		// "é" is 2 bytes, "😀" is 4
+		{`{ "maxLength": 4 }`, `"café"`, true},
+		{`{ "maxLength": 3 }`, `"café"`, false},
+		{`{ "minLength": 5 }`, `"café"`, false},
+		{`{ "maxLength": 1 }`, `"😀"`, true},
+		{`{ "minLength": 2 }`, `"😀"`, false},
+		{`{ "minLength": 3, "maxLength": 3 }`, `"a😀é"`, true},
+		{`{ "maxLength": 5 }`, `"naïve"`, true},
+		// "e" followed by a combining acute accent is 2 code points
+		{`{ "maxLength": 1 }`, `"é"`, false},
+	}
+
+	for i, c := range cases {
+		errs, err := Must(c.schema).ValidateBytes([]byte(c.input))
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err.Error())
+			continue
+		}
+		if (len(errs) == 0) != c.valid {
+			t.Errorf("case %d: expected %s to be valid against %s: %t, got errors: %v", i, c.input, c.schema, c.valid, errs)
+		}
+	}
+}
+
+func TestStringLengthUTF16(t *testing.T) {
+	cases := []struct {
+		schema, input string
+		points, utf16 bool
+	}{
+		// "😀" and "𝄞" are outside the basic multilingual plane
+		{`{ "maxLength": 1 }`, `"😀"`, true, false},
+		{`{ "minLength": 2 }`, `"😀"`, false, true},
+		{`{ "maxLength": 3 }`, `"a𝄞"`, true, true},
+		{`{ "maxLength": 2 }`, `"a𝄞"`, true, false},
+		// "é" and "中" are in the basic multilingual plane, so they count
+		// once either way
+		{`{ "minLength": 2, "maxLength": 2 }`, `"é中"`, true, true},
+	}
+
+	for i, c := range cases {
+		for _, utf16 := range []bool{false, true} {
+			errs, err := Must(c.schema).ValidateBytesWithOptions([]byte(c.input), ValidateOptions{StringLengthUTF16: utf16})
+			if err != nil {
+				t.Fatal(err)
+			}
+			expect := c.points
+			if utf16 {
+				expect = c.utf16
+			}
+			if (len(errs) == 0) != expect {
+				t.Errorf("case %d, utf16 %t: expected %s to be valid against %s: %t, got errors: %v", i, utf16, c.input, c.schema, expect, errs)
+			}
+		}
+	}
+}
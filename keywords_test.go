@@ -0,0 +1,216 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestEnumSuggestions(t *testing.T) {
+	rs := Must(`{ "enum": ["active", "inactive", "pending", 1] }`)
+
+	cases := []struct {
+		doc        string
+		message    string
+		suggestion interface{}
+	}{
+		{`"activ"`, `should be one of ["active", "inactive", "pending", 1], did you mean "active"?`, "active"},
+		{`"Pendin"`, `should be one of ["active", "inactive", "pending", 1], did you mean "pending"?`, "pending"},
+		{`"zzzzzzzzz"`, `should be one of ["active", "inactive", "pending", 1]`, nil},
+		{`2`, `should be one of ["active", "inactive", "pending", 1]`, nil},
+	}
+
+	for i, c := range cases {
+		errs, err := rs.ValidateBytesWithOptions([]byte(c.doc), ValidateOptions{Suggestions: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != 1 {
+			t.Errorf("case %d: expected 1 error, got: %v", i, errs)
+			continue
+		}
+		if errs[0].Message != c.message {
+			t.Errorf("case %d: expected message %q, got: %q", i, c.message, errs[0].Message)
+		}
+		if errs[0].Params["suggestion"] != c.suggestion {
+			t.Errorf("case %d: expected suggestion %v, got: %v", i, c.suggestion, errs[0].Params["suggestion"])
+		}
+	}
+
+	errs, _ := rs.ValidateBytes([]byte(`"activ"`))
+	if len(errs) != 1 || errs[0].Message != `should be one of ["active", "inactive", "pending", 1]` {
+		t.Errorf("expected no suggestion without the option, got: %v", errs)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b   string
+		expect int
+	}{
+		{"", "", 0},
+		{"activ", "active", 1},
+		{"kitten", "sitting", 3},
+		{"héllo", "hello", 1},
+		{"", "abc", 3},
+	}
+	for _, c := range cases {
+		if got := editDistance(c.a, c.b); got != c.expect {
+			t.Errorf("%q, %q: expected %d, got: %d", c.a, c.b, c.expect, got)
+		}
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	rs := Must(`{
+		"properties": {
+			"oldField": { "type": "string", "deprecated": true },
+			"newField": { "type": "string", "deprecated": false },
+			"list": { "items": { "deprecated": true } }
+		}
+	}`)
+	doc := []byte(`{ "oldField": "a", "newField": "b", "list": [1] }`)
+
+	errs, err := rs.ValidateBytes(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected deprecated to be an annotation by default, got: %v", errs)
+	}
+
+	errs, err = rs.ValidateBytesWithOptions(doc, ValidateOptions{WarnDeprecated: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := sortedErrorStrings(errs)
+	expect := []string{"/list/0: value is deprecated", "/oldField: property is deprecated"}
+	if len(got) != len(expect) || got[0] != expect[0] || got[1] != expect[1] {
+		t.Errorf("expected: %v, got: %v", expect, got)
+	}
+	for _, e := range errs {
+		if e.Severity != SeverityWarning || e.Keyword != "deprecated" || e.Error() != e.PropertyPath+": "+e.Message {
+			t.Errorf("expected a deprecated warning without a value, got: %#v", e)
+		}
+	}
+
+	if errs, _ := rs.ValidateBytesWithOptions([]byte(`{ "newField": "b" }`), ValidateOptions{WarnDeprecated: true}); len(errs) != 0 {
+		t.Errorf("expected no warnings without deprecated properties, got: %v", errs)
+	}
+}
+
+func TestWrongTypeSkipsTypedKeywords(t *testing.T) {
+	cases := []struct {
+		schema, doc string
+		expect      string
+	}{
+		{`{"type": "string", "minLength": 3}`, `5`, "/: type should be string"},
+		{`{"type": "string", "minLength": 3, "multipleOf": 2}`, `"ab"`, "/: min length of 3 characters required: ab"},
+		// keywords that apply to the instance's own type still run
+		{`{"type": "string", "minLength": 3, "multipleOf": 2}`, `5`, "/: must be a multiple of 2.000000\n/: type should be string"},
+		{`{"type": "integer", "minimum": 3, "maxLength": 1}`, `1.5`, "/: must be >= 3\n/: type should be integer"},
+		{`{"type": "object", "required": ["a"], "minItems": 2}`, `[1]`, "/: array length 1 below 2 minimum items\n/: type should be object"},
+		// keywords that apply to every type still run
+		{`{"type": "string", "minLength": 3, "enum": ["abc"]}`, `5`, "/: should be one of [\"abc\"]\n/: type should be string"},
+		{`{"type": ["string", "null"], "minLength": 3}`, `null`, ""},
+	}
+	for i, c := range cases {
+		errs, err := Must(c.schema).ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := errorLines(errs); got != c.expect {
+			t.Errorf("case %d: expected errors:\n%s\ngot:\n%s", i, c.expect, got)
+		}
+	}
+}
+
+func TestKeywordApplicability(t *testing.T) {
+	instances := map[string]string{
+		"null":    `null`,
+		"boolean": `true`,
+		"number":  `5.5`,
+		"integer": `5`,
+		"string":  `"a"`,
+		"array":   `[1, 1]`,
+		"object":  `{"a": 1}`,
+	}
+	cases := []struct {
+		schema string
+		types  []string
+	}{
+		{`{"multipleOf": 2}`, []string{"number", "integer"}},
+		{`{"maximum": 0}`, []string{"number", "integer"}},
+		{`{"exclusiveMaximum": 0}`, []string{"number", "integer"}},
+		{`{"minimum": 10}`, []string{"number", "integer"}},
+		{`{"exclusiveMinimum": 10}`, []string{"number", "integer"}},
+		{`{"maxLength": 0}`, []string{"string"}},
+		{`{"minLength": 10}`, []string{"string"}},
+		{`{"pattern": "^x$"}`, []string{"string"}},
+		{`{"format": "email"}`, []string{"string"}},
+		{`{"contentEncoding": "base64"}`, []string{"string"}},
+		{`{"contentMediaType": "application/json"}`, []string{"string"}},
+		{`{"items": {"type": "string"}}`, []string{"array"}},
+		{`{"items": [{}], "additionalItems": false}`, []string{"array"}},
+		{`{"maxItems": 0}`, []string{"array"}},
+		{`{"minItems": 10}`, []string{"array"}},
+		{`{"uniqueItems": true}`, []string{"array"}},
+		{`{"contains": {"type": "string"}}`, []string{"array"}},
+		{`{"maxProperties": 0}`, []string{"object"}},
+		{`{"minProperties": 10}`, []string{"object"}},
+		{`{"required": ["b"]}`, []string{"object"}},
+		{`{"properties": {"a": false}}`, []string{"object"}},
+		{`{"patternProperties": {"^a": false}}`, []string{"object"}},
+		{`{"additionalProperties": false}`, []string{"object"}},
+		{`{"dependencies": {"a": ["b"]}}`, []string{"object"}},
+		{`{"propertyNames": {"maxLength": 0}}`, []string{"object"}},
+	}
+
+	opts := ValidateOptions{AssertContent: true}
+	for _, c := range cases {
+		rs := Must(c.schema)
+		applies := map[string]bool{}
+		for _, typ := range c.types {
+			applies[typ] = true
+		}
+		for typ, doc := range instances {
+			errs, err := rs.ValidateBytesWithOptions([]byte(doc), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if applies[typ] && len(errs) == 0 {
+				t.Errorf("%s: expected %s instance %s to fail", c.schema, typ, doc)
+			} else if !applies[typ] && len(errs) != 0 {
+				t.Errorf("%s: expected %s instance %s to be ignored, got: %v", c.schema, typ, doc, errs)
+			}
+		}
+	}
+}
+
+func TestUniqueItemsIndices(t *testing.T) {
+	rs := Must(`{ "uniqueItems": true }`)
+	cases := []struct {
+		doc, expect string
+		first, dup  int
+	}{
+		{`[1, 2, 3, 2, 1]`, "/: items 1 and 3 are duplicates", 1, 3},
+		{`[{"a": 1, "b": 2}, [], {"b": 2, "a": 1}]`, "/: items 0 and 2 are duplicates", 0, 2},
+	}
+	for i, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pi, err := NewPreparedInstance([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, errs := range [][]ValError{errs, rs.ValidateInstance(pi)} {
+			if got := errorLines(errs); got != c.expect {
+				t.Errorf("case %d: expected errors:\n%s\ngot:\n%s", i, c.expect, got)
+				continue
+			}
+			if errs[0].Params["first"] != c.first || errs[0].Params["duplicate"] != c.dup {
+				t.Errorf("case %d: expected params first %d, duplicate %d, got: %v", i, c.first, c.dup, errs[0].Params)
+			}
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// SyntaxError is a json parse error at a position of a document. Line and
+// Column count from 1, columns count bytes
+type SyntaxError struct {
+	Offset int
+	Line   int
+	Column int
+	Msg    string
+}
+
+// Error implements the error interface for SyntaxError
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("error parsing JSON bytes at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// ValidateLenient performs schema validation against a slice of json byte
+// data that may be incomplete or broken, eg: a document being edited.
+// Valid documents are validated like ValidateBytes. When parsing fails
+// partway, the part of the document that parsed is validated, and the
+// parse error is returned as a *SyntaxError giving its position.
+// Containers that weren't closed hold the values parsed before the error,
+// values that were cut short are left out, eg: `{"a": 1, "b": [2, "x` is
+// validated as {"a": 1, "b": [2]}. This is best-effort and not spec
+// compliant, errors are only as meaningful as the part of the document
+// they describe, eg: properties that weren't typed yet are reported as
+// missing. Use it for live diagnostics, never to accept a document
+func (rs *RootSchema) ValidateLenient(data []byte) ([]ValError, error) {
+	var doc interface{}
+	errs := []ValError{}
+	if err := DefaultDecoder.Unmarshal(data, &doc); err == nil {
+		rs.Validate("/", doc, &errs)
+		return errs, nil
+	}
+
+	p := &lenientParser{data: data}
+	doc, ok := p.value()
+	if p.err == nil {
+		if p.skipSpace(); p.pos < len(p.data) {
+			p.fail("unexpected data after top-level value")
+		}
+	}
+	if p.err == nil {
+		// the document parsed leniently but not with DefaultDecoder
+		p.fail("invalid json")
+	}
+	if ok {
+		rs.Validate("/", doc, &errs)
+	}
+	return errs, p.err
+}
+
+// lenientParser parses as much of a json document as it can, recording the
+// first error it meets
+type lenientParser struct {
+	data []byte
+	pos  int
+	err  *SyntaxError
+}
+
+// fail records a parse error at the current position
+func (p *lenientParser) fail(msg string) {
+	if p.err != nil {
+		return
+	}
+	line := 1 + bytes.Count(p.data[:p.pos], []byte("\n"))
+	col := p.pos + 1 - (bytes.LastIndexByte(p.data[:p.pos], '\n') + 1)
+	p.err = &SyntaxError{Offset: p.pos, Line: line, Column: col, Msg: msg}
+}
+
+func (p *lenientParser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// value parses the value at the current position, reporting whether
+// there's anything worth validating. Objects and arrays cut short are,
+// scalars cut short aren't
+func (p *lenientParser) value() (interface{}, bool) {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		p.fail("unexpected end of input")
+		return nil, false
+	}
+	switch c := p.data[p.pos]; {
+	case c == '{':
+		return p.object(), true
+	case c == '[':
+		return p.array(), true
+	case c == '"':
+		return p.str()
+	case c == 't':
+		return true, p.literal("true")
+	case c == 'f':
+		return false, p.literal("false")
+	case c == 'n':
+		return nil, p.literal("null")
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.number()
+	default:
+		p.fail(fmt.Sprintf("invalid character %q", c))
+		return nil, false
+	}
+}
+
+func (p *lenientParser) object() map[string]interface{} {
+	obj := map[string]interface{}{}
+	p.pos++
+	if p.skipSpace(); p.pos < len(p.data) && p.data[p.pos] == '}' {
+		p.pos++
+		return obj
+	}
+	for {
+		if p.skipSpace(); p.pos >= len(p.data) || p.data[p.pos] != '"' {
+			p.expected("property name")
+			return obj
+		}
+		key, ok := p.str()
+		if !ok {
+			return obj
+		}
+		if p.skipSpace(); p.pos >= len(p.data) || p.data[p.pos] != ':' {
+			p.expected(`":"`)
+			return obj
+		}
+		p.pos++
+		val, ok := p.value()
+		if ok {
+			obj[key.(string)] = val
+		}
+		if p.err != nil || !p.more('}') {
+			return obj
+		}
+	}
+}
+
+func (p *lenientParser) array() []interface{} {
+	arr := []interface{}{}
+	p.pos++
+	if p.skipSpace(); p.pos < len(p.data) && p.data[p.pos] == ']' {
+		p.pos++
+		return arr
+	}
+	for {
+		val, ok := p.value()
+		if ok {
+			arr = append(arr, val)
+		}
+		if p.err != nil || !p.more(']') {
+			return arr
+		}
+	}
+}
+
+// more consumes the "," between members of a container or its closing
+// delimiter end, reporting whether more members follow
+func (p *lenientParser) more(end byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.data) && p.data[p.pos] == ',' {
+		p.pos++
+		return true
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == end {
+		p.pos++
+		return false
+	}
+	p.expected(fmt.Sprintf(`"," or %q`, string(end)))
+	return false
+}
+
+// expected records an error for a missing token
+func (p *lenientParser) expected(what string) {
+	if p.pos >= len(p.data) {
+		p.fail("unexpected end of input")
+		return
+	}
+	p.fail(fmt.Sprintf("expected %s, found %q", what, p.data[p.pos]))
+}
+
+func (p *lenientParser) str() (interface{}, bool) {
+	start := p.pos
+	for i := start + 1; i < len(p.data); i++ {
+		switch p.data[i] {
+		case '\\':
+			i++
+		case '"':
+			var str string
+			if err := DefaultDecoder.Unmarshal(p.data[start:i+1], &str); err != nil {
+				p.fail("invalid string")
+				return nil, false
+			}
+			p.pos = i + 1
+			return str, true
+		}
+	}
+	p.pos = len(p.data)
+	p.fail("unexpected end of input")
+	return nil, false
+}
+
+func (p *lenientParser) literal(lit string) bool {
+	if !bytes.HasPrefix(p.data[p.pos:], []byte(lit)) {
+		if bytes.HasPrefix([]byte(lit), p.data[p.pos:]) {
+			p.pos = len(p.data)
+			p.fail("unexpected end of input")
+		} else {
+			p.fail(fmt.Sprintf("invalid character %q", p.data[p.pos]))
+		}
+		return false
+	}
+	p.pos += len(lit)
+	return true
+}
+
+func (p *lenientParser) number() (interface{}, bool) {
+	start := p.pos
+	for p.pos < len(p.data) && bytes.IndexByte([]byte("+-.eE0123456789"), p.data[p.pos]) >= 0 {
+		p.pos++
+	}
+	num, err := strconv.ParseFloat(string(p.data[start:p.pos]), 64)
+	if err != nil {
+		p.pos = start
+		p.fail("invalid number")
+		return nil, false
+	}
+	return num, true
+}
@@ -0,0 +1,55 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateLenient(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": { "type": "string" },
+			"tags": { "type": "array", "items": { "type": "string" } }
+		}
+	}`)
+	cases := []struct {
+		doc  string
+		errs []string
+		err  string
+	}{
+		{`{"name": "a"}`, nil, ""},
+		{`{"name": 5, "tags": ["a", 2, "b`, []string{`/name: type should be string`, `/tags/1: type should be string`},
+			`error parsing JSON bytes at line 1, column 32: unexpected end of input`},
+		{"{\n  \"tags\": [\"a\"],\n  \"name\" 5\n}", []string{`/: "name" value is required`},
+			`error parsing JSON bytes at line 3, column 10: expected ":", found '5'`},
+		{`{"name": "a", "tags": [tru`, nil, `error parsing JSON bytes at line 1, column 27: unexpected end of input`},
+		{`{"name": "a"} x`, nil, `error parsing JSON bytes at line 1, column 15: unexpected data after top-level value`},
+		{`x`, nil, `error parsing JSON bytes at line 1, column 1: invalid character 'x'`},
+		{``, nil, `error parsing JSON bytes at line 1, column 1: unexpected end of input`},
+	}
+
+	for i, c := range cases {
+		errs, err := rs.ValidateLenient([]byte(c.doc))
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != c.err {
+			t.Errorf("case %d: expected error %q, got: %q", i, c.err, got)
+		}
+		if lines := errorLines(errs); lines != strings.Join(c.errs, "\n") {
+			t.Errorf("case %d: expected errors:\n%s\ngot:\n%s", i, strings.Join(c.errs, "\n"), lines)
+		}
+	}
+
+	_, err := rs.ValidateLenient([]byte("{\n\"name\": tru"))
+	se, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("expected a *SyntaxError, got: %#v", err)
+	}
+	if se.Offset != 13 || se.Line != 2 || se.Column != 12 {
+		t.Errorf("expected offset 13, line 2, column 12, got: %d, %d, %d", se.Offset, se.Line, se.Column)
+	}
+}
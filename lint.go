@@ -0,0 +1,168 @@
+package jsonschema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/qri-io/jsonpointer"
+)
+
+// Lint checks the schema for authoring mistakes, returning a description
+// of each problem found prefixed with the JSON pointer of the offending
+// subschema, in sorted order. Lint currently flags contradictory bounds:
+// minimum > maximum, minLength > maxLength, minItems > maxItems, and
+// minProperties > maxProperties, "const" or "enum" values that don't
+// match the "type" of the same schema, and "allOf" branches that require
+// types no instance can have at once
+func (rs *RootSchema) Lint() []string {
+	found := map[string]bool{}
+	walkJSONPath(&rs.Schema, jsonpointer.Pointer{}, func(ptr jsonpointer.Pointer, elem JSONPather) error {
+		sch := nodeSchema(elem)
+		if sch == nil {
+			return nil
+		}
+		path := ptr.String()
+		if path == "" {
+			// match the property path of the root in validation errors
+			path = "/"
+		}
+		for _, problem := range lintBounds(sch) {
+			found[fmt.Sprintf("%s: %s", path, problem)] = true
+		}
+		for _, problem := range lintValueTypes(sch) {
+			found[fmt.Sprintf("%s: %s", path, problem)] = true
+		}
+		if all, ok := sch.Validators["allOf"].(*AllOf); ok {
+			if reason, ok := contradictoryAllOf(*all); ok {
+				found[fmt.Sprintf("%s: allOf branches contradict each other: %s", path, reason)] = true
+			}
+		}
+		return nil
+	})
+
+	problems := make([]string, 0, len(found))
+	for p := range found {
+		problems = append(problems, p)
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// nodeSchema gives the schema represented by an element of the schema
+// tree, or nil if elem isn't a schema
+func nodeSchema(elem JSONPather) *Schema {
+	switch v := elem.(type) {
+	case *Schema:
+		return v
+	case *RootSchema:
+		return &v.Schema
+	case *Not:
+		return (*Schema)(v)
+	case *Contains:
+		return (*Schema)(v)
+	case *PropertyNames:
+		return (*Schema)(v)
+	case *Then:
+		return (*Schema)(v)
+	case *Else:
+		return (*Schema)(v)
+	case *If:
+		return &v.Schema
+	case *AdditionalItems:
+		return v.Schema
+	case *AdditionalProperties:
+		return v.Schema
+	case *ContentSchema:
+		return v.Schema
+	}
+	return nil
+}
+
+// lintBounds lists contradictory pairs of bounds declared by a single
+// schema
+func lintBounds(sch *Schema) (problems []string) {
+	var (
+		min, max         *float64
+		exclMin, exclMax bool
+	)
+	if v, ok := sch.Validators["minimum"].(*Minimum); ok {
+		f := float64(*v)
+		min = &f
+	}
+	if v, ok := sch.Validators["exclusiveMinimum"].(*ExclusiveMinimum); ok && (min == nil || float64(*v) >= *min) {
+		f := float64(*v)
+		min, exclMin = &f, true
+	}
+	if v, ok := sch.Validators["maximum"].(*Maximum); ok {
+		f := float64(*v)
+		max = &f
+	}
+	if v, ok := sch.Validators["exclusiveMaximum"].(*ExclusiveMaximum); ok && (max == nil || float64(*v) <= *max) {
+		f := float64(*v)
+		max, exclMax = &f, true
+	}
+	if min != nil && max != nil && (*min > *max || (*min == *max && (exclMin || exclMax))) {
+		problems = append(problems, fmt.Sprintf("%s %v and %s %v leave no numbers in range", boundName("minimum", exclMin), *min, boundName("maximum", exclMax), *max))
+	}
+
+	if lo, ok := sch.Validators["minLength"].(*MinLength); ok {
+		if hi, ok := sch.Validators["maxLength"].(*MaxLength); ok && int(*lo) > int(*hi) {
+			problems = append(problems, fmt.Sprintf("minLength %d is greater than maxLength %d", *lo, *hi))
+		}
+	}
+	if lo, ok := sch.Validators["minItems"].(*MinItems); ok {
+		if hi, ok := sch.Validators["maxItems"].(*MaxItems); ok && int(*lo) > int(*hi) {
+			problems = append(problems, fmt.Sprintf("minItems %d is greater than maxItems %d", *lo, *hi))
+		}
+	}
+	if lo, ok := sch.Validators["minProperties"].(*minProperties); ok {
+		if hi, ok := sch.Validators["maxProperties"].(*MaxProperties); ok && int(*lo) > int(*hi) {
+			problems = append(problems, fmt.Sprintf("minProperties %d is greater than maxProperties %d", *lo, *hi))
+		}
+	}
+	return problems
+}
+
+// lintValueTypes lists "const" and "enum" values that can never satisfy
+// the "type" of the same schema, eg: "enum": [1, 2] with "type": "string"
+func lintValueTypes(sch *Schema) (problems []string) {
+	t, ok := sch.Validators["type"].(*Type)
+	if !ok || len(t.vals) == 0 {
+		return nil
+	}
+	if c, ok := sch.Validators["const"].(*Const); ok {
+		if val, ok := mismatchedType(t, *c); ok {
+			problems = append(problems, fmt.Sprintf("const %s doesn't match type %s", val, t))
+		}
+	}
+	if e, ok := sch.Validators["enum"].(*Enum); ok {
+		vals := []string{}
+		for _, c := range *e {
+			if val, ok := mismatchedType(t, c); ok {
+				vals = append(vals, val)
+			}
+		}
+		if len(vals) == 1 {
+			problems = append(problems, fmt.Sprintf("enum value %s doesn't match type %s", vals[0], t))
+		} else if len(vals) > 1 {
+			problems = append(problems, fmt.Sprintf("enum values %s don't match type %s", strings.Join(vals, ", "), t))
+		}
+	}
+	return problems
+}
+
+// mismatchedType gives the canonical json of a const value if it doesn't
+// match t
+func mismatchedType(t *Type, c Const) (string, bool) {
+	var val interface{}
+	if err := DefaultDecoder.Unmarshal(c, &val); err != nil {
+		return "", false
+	}
+	errs := []ValError{}
+	t.Validate("/", val, &errs)
+	if len(errs) == 0 {
+		return "", false
+	}
+	return canonicalJSON(val), true
+}
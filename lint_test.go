@@ -0,0 +1,42 @@
+package jsonschema
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		schema   string
+		problems []string
+	}{
+		{`{ "minimum": 1, "maximum": 3, "minLength": 2 }`, nil},
+		{`{ "minimum": 5, "maximum": 3 }`, []string{"/: minimum 5 and maximum 3 leave no numbers in range"}},
+		{`{ "properties": { "name": { "minLength": 5, "maxLength": 2 } } }`, []string{"/properties/name: minLength 5 is greater than maxLength 2"}},
+		{`{ "items": { "minItems": 3, "maxItems": 1 } }`, []string{"/items: minItems 3 is greater than maxItems 1"}},
+		{`{ "items": [{}, { "exclusiveMinimum": 2, "maximum": 2 }] }`, []string{"/items/1: exclusiveMinimum 2 and maximum 2 leave no numbers in range"}},
+		{`{ "definitions": { "a": { "minProperties": 2, "maxProperties": 1 } }, "not": { "minLength": 3, "maxLength": 0 } }`, []string{
+			"/definitions/a: minProperties 2 is greater than maxProperties 1",
+			"/not: minLength 3 is greater than maxLength 0",
+		}},
+		{`{ "patternProperties": { "^x-": { "minItems": 2, "maxItems": 0 } } }`, []string{"/patternProperties/^x-: minItems 2 is greater than maxItems 0"}},
+		{`{ "type": "string", "enum": ["a", "b"], "const": "a" }`, nil},
+		{`{ "type": "number", "enum": [1, 2.5] }`, nil},
+		{`{ "type": "string", "enum": [1, 2, 3] }`, []string{"/: enum values 1, 2, 3 don't match type string"}},
+		{`{ "properties": { "a": { "type": ["integer", "null"], "enum": [1, null, 2.5, "x"] } } }`, []string{"/properties/a: enum values 2.5, \"x\" don't match type integer,null"}},
+		{`{ "items": { "type": "object", "enum": [{ "a": 1 }, [1]] } }`, []string{"/items: enum value [1] doesn't match type object"}},
+		{`{ "type": "boolean", "const": "true" }`, []string{`/: const "true" doesn't match type boolean`}},
+		{`{ "enum": [1, "a"], "const": null }`, nil},
+		{`{ "allOf": [{ "type": "object" }, { "type": "string" }] }`, []string{"/: allOf branches contradict each other: branch 0 requires object, branch 1 requires string"}},
+		{`{ "allOf": [{ "type": "number" }, { "minimum": 1 }, { "type": ["integer", "null"] }] }`, nil},
+		{`{ "properties": { "a": { "allOf": [{ "type": ["object", "array"] }, {}, { "type": "array" }, { "allOf": [{ "type": "boolean" }] }] } } }`, []string{"/properties/a: allOf branches contradict each other: branch 0 requires array or object, branch 3 requires boolean"}},
+		{`{ "allOf": [{ "type": ["string", "null"] }, { "type": ["number", "string"] }, { "type": ["null", "number"] }] }`, []string{"/: allOf branches contradict each other: branches 0 through 2 allow no common type"}},
+	}
+
+	for i, c := range cases {
+		got := Must(c.schema).Lint()
+		if fmt.Sprint(got) != fmt.Sprint(c.problems) && !(len(got) == 0 && len(c.problems) == 0) {
+			t.Errorf("case %d mismatch. expected: %v, got: %v", i, c.problems, got)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package jsonschema
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qri-io/jsonpointer"
+)
+
+// Loader fetches the raw JSON bytes of the schema document a reference
+// points to. The reference passed to Load never includes a fragment
+type Loader interface {
+	Load(ref string) ([]byte, error)
+}
+
+// FileLoader loads schema documents from the local filesystem.
+// Relative paths are resolved against BaseDir
+type FileLoader struct {
+	BaseDir string
+}
+
+// Load implements the Loader interface for FileLoader
+func (l FileLoader) Load(ref string) ([]byte, error) {
+	return ioutil.ReadFile(l.path(ref))
+}
+
+func (l FileLoader) path(ref string) string {
+	ref = strings.TrimPrefix(ref, "file://")
+	if filepath.IsAbs(ref) {
+		return filepath.Clean(ref)
+	}
+	return filepath.Join(l.BaseDir, filepath.FromSlash(ref))
+}
+
+// HTTPLoader loads schema documents over HTTP. Client defaults to
+// http.DefaultClient
+type HTTPLoader struct {
+	Client *http.Client
+	// Retry configures retrying failed requests, by default requests are
+	// made once
+	Retry RetryPolicy
+}
+
+// RetryPolicy configures retrying requests that fail with a network error
+// or a 5xx status, waiting between attempts with exponential backoff
+type RetryPolicy struct {
+	// MaxAttempts is the most requests made for a document, including the
+	// first. values less than one make a single attempt
+	MaxAttempts int
+	// Backoff is the wait before the first retry, doubling for each retry
+	// after it. defaults to 100ms
+	Backoff time.Duration
+	// MaxBackoff caps the wait between attempts, zero means no cap
+	MaxBackoff time.Duration
+}
+
+// statusError is a response with a status other than 2xx
+type statusError struct {
+	ref    string
+	code   int
+	status string
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("fetching %s: %s", e.ref, e.status)
+}
+
+// Load implements the Loader interface for HTTPLoader. Responses without a
+// 2xx status are an error
+func (l HTTPLoader) Load(ref string) ([]byte, error) {
+	return l.LoadContext(context.Background(), ref)
+}
+
+// LoadContext works like Load, giving up when ctx is done, including while
+// waiting to retry
+func (l HTTPLoader) LoadContext(ctx context.Context, ref string) ([]byte, error) {
+	wait := l.Retry.Backoff
+	if wait <= 0 {
+		wait = 100 * time.Millisecond
+	}
+	for attempt := 1; ; attempt++ {
+		data, err := l.get(ctx, ref)
+		if err == nil || attempt >= l.Retry.MaxAttempts || ctx.Err() != nil {
+			return data, err
+		}
+		if se, ok := err.(statusError); ok && se.code < 500 {
+			return nil, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		if wait *= 2; l.Retry.MaxBackoff > 0 && wait > l.Retry.MaxBackoff {
+			wait = l.Retry.MaxBackoff
+		}
+	}
+}
+
+// get makes a single request for a document
+func (l HTTPLoader) get(ctx context.Context, ref string) ([]byte, error) {
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ref, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, statusError{ref: ref, code: res.StatusCode, status: res.Status}
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if body, err = decodeBody(res.Header.Get("Content-Encoding"), body); err != nil {
+		return nil, fmt.Errorf("error decoding %s: %s", ref, err.Error())
+	}
+	return body, nil
+}
+
+// decodeBody decompresses a response body according to its content
+// encoding. Bodies starting with the gzip magic number are decompressed
+// even when the encoding is missing
+func decodeBody(encoding string, body []byte) ([]byte, error) {
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+	if encoding == "" && bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		encoding = "gzip"
+	}
+
+	switch encoding {
+	case "gzip", "x-gzip":
+		r, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	case "deflate":
+		// deflate is meant to be zlib wrapped, though some servers send a raw
+		// deflate stream
+		if r, err := zlib.NewReader(bytes.NewReader(body)); err == nil {
+			defer r.Close()
+			return ioutil.ReadAll(r)
+		}
+		r := flate.NewReader(bytes.NewReader(body))
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	}
+	return body, nil
+}
+
+// lazyRefCache holds the targets of references resolved during
+// validation with ValidateOptions.LazyRemoteRefs, by reference. Documents
+// are cached by URL so each is loaded once
+type lazyRefCache struct {
+	sync.Mutex
+	docs    map[string]*RootSchema
+	targets map[string]Validator
+}
+
+func newLazyRefCache() *lazyRefCache {
+	return &lazyRefCache{docs: map[string]*RootSchema{}, targets: map[string]Validator{}}
+}
+
+// lazyRefCaches holds a schema's lazy reference caches, one per loader
+type lazyRefCaches struct {
+	sync.Mutex
+	byLoader map[Loader]*lazyRefCache
+}
+
+// lazyRefsMu guards setting RootSchema.lazyRefs
+var lazyRefsMu sync.Mutex
+
+// lazyRefsFor gives the cache of lazily resolved references the schema
+// keeps for loader. Loaders that can't be compared, and so can't be told
+// apart, get a cache of their own each call
+func (rs *RootSchema) lazyRefsFor(loader Loader) *lazyRefCache {
+	if loader == nil {
+		loader = HTTPLoader{}
+	}
+	if !reflect.TypeOf(loader).Comparable() {
+		return newLazyRefCache()
+	}
+
+	lazyRefsMu.Lock()
+	if rs.lazyRefs == nil {
+		rs.lazyRefs = &lazyRefCaches{byLoader: map[Loader]*lazyRefCache{}}
+	}
+	caches := rs.lazyRefs
+	lazyRefsMu.Unlock()
+
+	caches.Lock()
+	defer caches.Unlock()
+	cache := caches.byLoader[loader]
+	if cache == nil {
+		cache = newLazyRefCache()
+		caches.byLoader[loader] = cache
+	}
+	return cache
+}
+
+// lazyRef resolves a reference to another document during validation,
+// loading the document if it hasn't been already. It gives nil without an
+// error if lazy references are off or ref is local to the document
+func (vc *ValidationContext) lazyRef(ref string) (Validator, error) {
+	doc, fragment := splitRef(ref)
+	if !vc.Options.LazyRemoteRefs || doc == "" {
+		return nil, nil
+	}
+
+	if vc.lazyRefs == nil {
+		vc.lazyRefs = newLazyRefCache()
+	}
+	cache := vc.lazyRefs
+	cache.Lock()
+	target, rs := cache.targets[ref], cache.docs[doc]
+	cache.Unlock()
+	if target != nil {
+		return target, nil
+	}
+
+	if rs == nil {
+		if known := knownDocument(doc); known != nil {
+			rs = &RootSchema{Schema: *known}
+		}
+	}
+	if rs == nil {
+		loader := vc.Options.RefLoader
+		if loader == nil {
+			loader = HTTPLoader{}
+		}
+		data, err := loader.Load(doc)
+		if err != nil {
+			return nil, err
+		}
+		rs = &RootSchema{}
+		if err := DefaultDecoder.Unmarshal(data, rs); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", doc, err.Error())
+		}
+	}
+
+	target, err := rs.resolveFragment(fragment)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Lock()
+	defer cache.Unlock()
+	if cached := cache.docs[doc]; cached != nil && cached != rs {
+		// another validation loaded the document first, use its copy
+		if target, err = cached.resolveFragment(fragment); err != nil {
+			return nil, err
+		}
+	} else {
+		cache.docs[doc] = rs
+	}
+	cache.targets[ref] = target
+	return target, nil
+}
+
+// ParseFile reads a schema from disk. Relative file references like
+// "common.json" or "common.json#/definitions/name" are resolved against the
+// directory of the file that contains them, loading and resolving each
+// referenced file in turn
+func ParseFile(path string) (*RootSchema, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseFile(abs, map[string]*RootSchema{})
+}
+
+// parseFile parses the file at abs, caching results by absolute path so
+// files that reference each other are only ever parsed once
+func parseFile(abs string, files map[string]*RootSchema) (*RootSchema, error) {
+	if rs, ok := files[abs]; ok {
+		return rs, nil
+	}
+
+	data, err := ioutil.ReadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	rs := &RootSchema{}
+	if err := DefaultDecoder.Unmarshal(data, rs); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %s", abs, err.Error())
+	}
+	rs.baseDir = filepath.Dir(abs)
+	files[abs] = rs
+
+	loader := FileLoader{BaseDir: rs.baseDir}
+	if err := walkJSON(&rs.Schema, func(elem JSONPather) error {
+		if sch, ok := elem.(*Schema); ok {
+			doc, fragment := splitRef(sch.Ref)
+			if doc == "" || !isFileRef(doc) {
+				return nil
+			}
+
+			ref, err := parseFile(loader.path(doc), files)
+			if err != nil {
+				return fmt.Errorf("error resolving %s: %s", sch.Ref, err.Error())
+			}
+			val, err := ref.resolveFragment(fragment)
+			if err != nil {
+				return fmt.Errorf("error resolving %s: %s", sch.Ref, err.Error())
+			}
+			sch.ref = val
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
+
+// splitRef separates a reference into its document and fragment components
+func splitRef(ref string) (doc, fragment string) {
+	if i := strings.Index(ref, "#"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// isFileRef reports whether a reference's document component is a local
+// file path rather than a network location
+func isFileRef(doc string) bool {
+	u, err := url.Parse(doc)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || u.Scheme == "file"
+}
+
+// resolveFragment evaluates a reference fragment against the root schema,
+// returning the validator it points to
+func (rs *RootSchema) resolveFragment(fragment string) (Validator, error) {
+	ptr, err := jsonpointer.Parse(fragment)
+	if err != nil {
+		return nil, fmt.Errorf("error evaluating json pointer: %s: %s", err.Error(), fragment)
+	}
+	res, err := rs.evalJSONValidatorPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if sch, ok := res.(*Schema); ok && sch == nil {
+		return nil, fmt.Errorf("%s is not a json pointer to a json schema", fragment)
+	}
+	if val, ok := res.(Validator); ok {
+		return val, nil
+	}
+	return nil, fmt.Errorf("%s is not a json pointer to a json schema", fragment)
+}
@@ -0,0 +1,389 @@
+package jsonschema
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseFile(t *testing.T) {
+	rs, err := ParseFile("testdata/files/person.json")
+	if err != nil {
+		t.Fatalf("unexpected error parsing file: %s", err.Error())
+	}
+
+	cases := []struct {
+		input  string
+		errors []string
+	}{
+		{`{"name": "Alice", "address": {"city": "Berlin"}}`, nil},
+		{`{"name": ""}`, []string{`/name: "" min length of 1 characters required: `}},
+		{`{"name": "Alice", "address": {}}`, []string{`/address: {} "city" value is required`}},
+		{`{"name": "Alice", "address": {"city": 5}}`, []string{`/address/city: 5 type should be string`}},
+	}
+
+	for i, c := range cases {
+		errors, err := rs.ValidateBytes([]byte(c.input))
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err.Error())
+			continue
+		}
+
+		if len(errors) != len(c.errors) {
+			t.Errorf("case %d: error length mismatch. expected: %d, got: %d", i, len(c.errors), len(errors))
+			t.Errorf("%v", errors)
+			continue
+		}
+
+		for j, e := range errors {
+			if e.Error() != c.errors[j] {
+				t.Errorf("case %d: validation error %d mismatch. expected: %s, got: %s", i, j, c.errors[j], e.Error())
+			}
+		}
+	}
+
+	if _, err := ParseFile("testdata/files/missing.json"); err == nil {
+		t.Errorf("expected error parsing a missing file")
+	}
+}
+
+func TestFileLoader(t *testing.T) {
+	l := FileLoader{BaseDir: "testdata/files"}
+	data, err := l.Load("nested/address.json")
+	if err != nil {
+		t.Fatalf("unexpected error loading file: %s", err.Error())
+	}
+	if len(data) == 0 {
+		t.Errorf("expected file contents, got none")
+	}
+}
+
+// mapLoader loads documents from a map, counting loads of each document
+type mapLoader struct {
+	lock  sync.Mutex
+	docs  map[string]string
+	loads map[string]int
+}
+
+func (l *mapLoader) Load(ref string) ([]byte, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.loads[ref]++
+	doc, ok := l.docs[ref]
+	if !ok {
+		return nil, fmt.Errorf("not found: %s", ref)
+	}
+	return []byte(doc), nil
+}
+
+func TestLazyRemoteRefs(t *testing.T) {
+	loader := &mapLoader{
+		docs: map[string]string{
+			"http://lazy.example.com/name.json":  `{ "definitions": { "short": { "type": "string", "maxLength": 3 } } }`,
+			"http://lazy.example.com/count.json": `{ "type": "integer" }`,
+		},
+		loads: map[string]int{},
+	}
+	rs := Must(`{
+		"properties": {
+			"name": { "$ref": "http://lazy.example.com/name.json#/definitions/short" },
+			"count": { "$ref": "http://lazy.example.com/count.json" },
+			"missing": { "$ref": "http://lazy.example.com/missing.json" }
+		}
+	}`)
+	opts := ValidateOptions{LazyRemoteRefs: true, RefLoader: loader}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs, err := rs.ValidateBytesWithOptions([]byte(`{ "name": "abcd" }`), opts)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(errs) != 1 || errs[0].Error() != `/name: "abcd" max length of 3 characters exceeded: abcd` {
+				t.Errorf("expected one maxLength error, got: %v", errs)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if errs, _ := rs.ValidateBytesWithOptions([]byte(`{ "name": "ab" }`), opts); len(errs) != 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+	if loader.loads["http://lazy.example.com/count.json"] != 0 {
+		t.Errorf("expected unused references not to be loaded")
+	}
+
+	errs, _ := rs.ValidateBytesWithOptions([]byte(`{ "missing": 1 }`), opts)
+	expect := `/missing: 1 http://lazy.example.com/missing.json reference could not be loaded: not found: http://lazy.example.com/missing.json`
+	if len(errs) != 1 || errs[0].Error() != expect || errs[0].Keyword != "$ref" {
+		t.Errorf("expected a load error, got: %v", errs)
+	}
+
+	// without the option references to other documents stay unresolved
+	errs, _ = rs.ValidateBytes([]byte(`{ "count": 1 }`))
+	if len(errs) != 1 {
+		t.Errorf("expected an unresolved reference error, got: %v", errs)
+	}
+}
+
+func TestLazyRemoteRefsPerLoader(t *testing.T) {
+	ref := "http://lazy.example.com/scoped.json"
+	strLoader := &mapLoader{docs: map[string]string{ref: `{ "type": "string" }`}, loads: map[string]int{}}
+	numLoader := &mapLoader{docs: map[string]string{ref: `{ "type": "number" }`}, loads: map[string]int{}}
+	schema := `{ "properties": { "val": { "$ref": "http://lazy.example.com/scoped.json" } } }`
+
+	cases := []struct {
+		rs     *RootSchema
+		loader *mapLoader
+		doc    string
+		errs   int
+	}{
+		{Must(schema), strLoader, `{ "val": "a" }`, 0},
+		{Must(schema), numLoader, `{ "val": "a" }`, 1},
+		{Must(schema), numLoader, `{ "val": 1 }`, 0},
+	}
+	for i, c := range cases {
+		errs, err := c.rs.ValidateBytesWithOptions([]byte(c.doc), ValidateOptions{LazyRemoteRefs: true, RefLoader: c.loader})
+		if err != nil {
+			t.Fatalf("case %d: %s", i, err)
+		}
+		if len(errs) != c.errs {
+			t.Errorf("case %d: expected %d errors, got: %v", i, c.errs, errs)
+		}
+	}
+	if strLoader.loads[ref] != 1 || numLoader.loads[ref] != 2 {
+		t.Errorf("expected each schema to load the document from its own loader, got %d and %d loads", strLoader.loads[ref], numLoader.loads[ref])
+	}
+
+	// one schema validated with two loaders keeps a cache for each
+	rs := Must(schema)
+	for _, loader := range []*mapLoader{strLoader, numLoader, strLoader, numLoader} {
+		errs, _ := rs.ValidateBytesWithOptions([]byte(`{ "val": 1 }`), ValidateOptions{LazyRemoteRefs: true, RefLoader: loader})
+		if expect := map[*mapLoader]int{strLoader: 1, numLoader: 0}[loader]; len(errs) != expect {
+			t.Errorf("expected %d errors, got: %v", expect, errs)
+		}
+	}
+	if strLoader.loads[ref] != 2 || numLoader.loads[ref] != 3 {
+		t.Errorf("expected one load per loader, got %d and %d loads", strLoader.loads[ref], numLoader.loads[ref])
+	}
+}
+
+func TestHTTPLoader(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/schema.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{ "type": "string" }`))
+	}))
+	defer s.Close()
+
+	data, err := HTTPLoader{}.Load(s.URL + "/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{ "type": "string" }` {
+		t.Errorf("unexpected response body: %s", string(data))
+	}
+
+	if _, err := (HTTPLoader{Client: s.Client()}).Load(s.URL + "/missing.json"); err == nil || !strings.Contains(err.Error(), "404 Not Found") {
+		t.Errorf("expected a not found error, got: %v", err)
+	}
+}
+
+func TestFetchRemoteReferencesRetry(t *testing.T) {
+	attempts := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{ "type": "string" }`))
+	}))
+	defer s.Close()
+
+	prev := DefaultSchemaPoolConfig
+	defer func() { DefaultSchemaPoolConfig = prev }()
+	DefaultSchemaPoolConfig = SchemaPoolConfig{RetryPolicy: RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}}
+
+	rs := Must(fmt.Sprintf(`{ "$ref": "%s/flaky.json" }`, s.URL))
+	if err := rs.FetchRemoteReferences(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got: %d", attempts)
+	}
+	if errs, _ := rs.ValidateBytes([]byte(`1`)); len(errs) != 1 || errs[0].Message != "type should be string" {
+		t.Errorf("expected the fetched schema to apply, got: %v", errs)
+	}
+}
+
+func TestFetchRemoteReferencesGiveUp(t *testing.T) {
+	attempts := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer s.Close()
+
+	prev := DefaultSchemaPoolConfig
+	defer func() { DefaultSchemaPoolConfig = prev }()
+
+	DefaultSchemaPoolConfig = SchemaPoolConfig{RetryPolicy: RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}}
+	rs := Must(fmt.Sprintf(`{ "$ref": "%s/down.json" }`, s.URL))
+	if err := rs.FetchRemoteReferences(); err == nil || !strings.Contains(err.Error(), "502 Bad Gateway") {
+		t.Errorf("expected a bad gateway error, got: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got: %d", attempts)
+	}
+
+	// the context deadline cuts waiting for a retry short
+	DefaultSchemaPoolConfig = SchemaPoolConfig{RetryPolicy: RetryPolicy{MaxAttempts: 5, Backoff: time.Hour}}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := rs.FetchRemoteReferencesContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got: %v", err)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Errorf("expected retries to stop at the deadline")
+	}
+}
+
+func TestHTTPLoaderCompression(t *testing.T) {
+	doc := []byte(`{ "type": "string", "maxLength": 3 }`)
+	var gz, zl, raw bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write(doc)
+	w.Close()
+	zw := zlib.NewWriter(&zl)
+	zw.Write(doc)
+	zw.Close()
+	fw, _ := flate.NewWriter(&raw, flate.DefaultCompression)
+	fw.Write(doc)
+	fw.Close()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			t.Errorf("expected gzip to be accepted, got: %q", r.Header.Get("Accept-Encoding"))
+		}
+		switch r.URL.Path {
+		case "/gzip.json":
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(gz.Bytes())
+		case "/sniffed.json":
+			w.Write(gz.Bytes())
+		case "/zlib.json":
+			w.Header().Set("Content-Encoding", "deflate")
+			w.Write(zl.Bytes())
+		case "/flate.json":
+			w.Header().Set("Content-Encoding", "deflate")
+			w.Write(raw.Bytes())
+		default:
+			w.Write(doc)
+		}
+	}))
+	defer s.Close()
+
+	for _, p := range []string{"/gzip.json", "/sniffed.json", "/zlib.json", "/flate.json", "/plain.json"} {
+		data, err := HTTPLoader{}.Load(s.URL + p)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", p, err)
+			continue
+		}
+		if !bytes.Equal(data, doc) {
+			t.Errorf("%s: expected decompressed body %s, got: %q", p, doc, data)
+		}
+	}
+
+	rs := Must(fmt.Sprintf(`{ "$ref": "%s/gzip.json" }`, s.URL))
+	if err := rs.FetchRemoteReferences(); err != nil {
+		t.Fatal(err)
+	}
+	if errs, _ := rs.ValidateBytes([]byte(`"abcd"`)); len(errs) != 1 {
+		t.Errorf("expected the gzipped schema to apply, got: %v", errs)
+	}
+}
+
+func TestRefOnlyRoot(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{ "type": "object", "properties": { "n": { "type": "integer" } }, "required": ["n"] }`))
+	}))
+	defer s.Close()
+
+	roots := []string{
+		`{ "$ref": "#/definitions/Thing", "definitions": { "Thing": { "type": "object", "properties": { "n": { "type": "integer" } }, "required": ["n"] } } }`,
+		fmt.Sprintf(`{ "$ref": "%s/thing.json" }`, s.URL),
+	}
+	for i, root := range roots {
+		rs := Must(root)
+		if err := rs.FetchRemoteReferences(); err != nil {
+			t.Fatalf("case %d: %s", i, err)
+		}
+		if got := rs.TopLevelType(); got != "object" {
+			t.Errorf("case %d: expected top level type object, got: %s", i, got)
+		}
+		if errs, _ := rs.ValidateBytes([]byte(`{ "n": 1 }`)); len(errs) != 0 {
+			t.Errorf("case %d: unexpected errors: %v", i, errs)
+		}
+		errs, _ := rs.ValidateBytes([]byte(`{ "n": "one" }`))
+		if len(errs) != 1 || errs[0].PropertyPath != "/n" || errs[0].Message != "type should be integer" {
+			t.Errorf("case %d: expected the referenced schema to apply, got: %v", i, errs)
+		}
+		if errs, _ := rs.ValidateBytes([]byte(`[]`)); len(errs) != 1 {
+			t.Errorf("case %d: expected a type error, got: %v", i, errs)
+		}
+	}
+}
+
+func TestFetchRemoteFragmentRef(t *testing.T) {
+	prevPool, prevConfig := DefaultSchemaPool, DefaultSchemaPoolConfig
+	defer func() { DefaultSchemaPool, DefaultSchemaPoolConfig = prevPool, prevConfig }()
+	DefaultSchemaPool = Definitions{}
+	DefaultSchemaPoolConfig = SchemaPoolConfig{Loader: FileLoader{BaseDir: "testdata/files"}}
+
+	rs := Must(`{
+		"properties": {
+			"first": { "$ref": "common.json#/definitions/name" },
+			"short": { "$ref": "other.json#/definitions/name" },
+			"other": { "$ref": "other.json" }
+		}
+	}`)
+	if err := rs.FetchRemoteReferences(); err != nil {
+		t.Fatal(err)
+	}
+	errs, err := rs.ValidateBytes([]byte(`{ "first": "", "short": "abcd", "other": 1 }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := strings.Join([]string{
+		`/first: min length of 1 characters required: `,
+		`/short: max length of 3 characters exceeded: abcd`,
+	}, "\n")
+	if got := errorLines(errs); got != expect {
+		t.Errorf("expected errors:\n%s\ngot:\n%s", expect, got)
+	}
+	if DefaultSchemaPool["other.json"] == nil {
+		t.Errorf("expected the fetched document to be added to the pool")
+	}
+
+	rs = Must(`{ "$ref": "other.json#/definitions/missing" }`)
+	if err := rs.FetchRemoteReferences(); err == nil || !strings.Contains(err.Error(), "error resolving other.json#/definitions/missing") {
+		t.Errorf("expected an error resolving a missing fragment, got: %v", err)
+	}
+}
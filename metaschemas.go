@@ -0,0 +1,79 @@
+package jsonschema
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sync"
+)
+
+// metaSchemaFiles holds the standard meta-schemas, which ship with the
+// package
+//
+//go:embed metaschemas/*.json
+var metaSchemaFiles embed.FS
+
+// LoadStandardMetaSchemas registers the draft-04, draft-06, and draft-07
+// meta-schemas in pool by their ids, eg:
+// "http://json-schema.org/draft-07/schema#", so schemas of the pool can
+// reference them and be validated against them without fetching them,
+// eg: LoadStandardMetaSchemas(DefaultSchemaPool)
+func LoadStandardMetaSchemas(pool Definitions) error {
+	files, err := metaSchemaFiles.ReadDir("metaschemas")
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		data, err := metaSchemaFiles.ReadFile(path.Join("metaschemas", f.Name()))
+		if err != nil {
+			return err
+		}
+		rs := &RootSchema{}
+		if err := rs.UnmarshalJSON(data); err != nil {
+			return fmt.Errorf("error parsing meta-schema %s: %s", f.Name(), err.Error())
+		}
+		if err := pool.Add(rs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// standardMetaSchemas holds the parsed standard meta-schemas references
+// resolve to, parsed the first time one is needed
+var standardMetaSchemas struct {
+	once sync.Once
+	pool Definitions
+}
+
+// knownDocument gives the schema of a document that can be referenced
+// without fetching it, from DefaultSchemaPool or the standard meta-schemas
+func knownDocument(uri string) *Schema {
+	if sch := DefaultSchemaPool.lookup(uri); sch != nil {
+		return sch
+	}
+	if DefaultSchemaPoolConfig.FetchMetaSchemas {
+		return nil
+	}
+	standardMetaSchemas.once.Do(func() {
+		standardMetaSchemas.pool = Definitions{}
+		// the embedded meta-schemas are known to parse
+		LoadStandardMetaSchemas(standardMetaSchemas.pool)
+	})
+	return standardMetaSchemas.pool.lookup(uri)
+}
+
+// knownRef resolves a reference into a known document, see knownDocument
+func knownRef(ref string) *Schema {
+	doc, fragment := splitRef(ref)
+	sch := knownDocument(doc)
+	if sch == nil || fragment == "" || fragment == "/" {
+		return sch
+	}
+	val, err := (&RootSchema{Schema: *sch}).resolveFragment(fragment)
+	if err != nil {
+		return nil
+	}
+	target, _ := val.(*Schema)
+	return target
+}
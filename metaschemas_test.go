@@ -0,0 +1,135 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadStandardMetaSchemas(t *testing.T) {
+	pool := Definitions{}
+	if err := LoadStandardMetaSchemas(pool); err != nil {
+		t.Fatal(err)
+	}
+
+	drafts := map[string]string{
+		"http://json-schema.org/draft-04/schema#": "testdata/draft4/*.json",
+		"http://json-schema.org/draft-06/schema#": "testdata/draft6/*.json",
+		"http://json-schema.org/draft-07/schema#": "testdata/draft7/*.json",
+	}
+	for id, pattern := range drafts {
+		if pool[id] == nil {
+			t.Errorf("expected meta-schema %s to be registered", id)
+			continue
+		}
+
+		// the schemas of the test suite are valid against their meta-schema
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range files {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sets := []struct {
+				Description string          `json:"description"`
+				Schema      json.RawMessage `json:"schema"`
+			}{}
+			if err := json.Unmarshal(data, &sets); err != nil {
+				t.Fatalf("%s: %s", path, err)
+			}
+			for _, set := range sets {
+				errs, err := pool.Validate(id, set.Schema)
+				if err != nil {
+					t.Errorf("%s: %s: %s", path, set.Description, err)
+					continue
+				}
+				if len(errs) != 0 {
+					t.Errorf("%s: %s: expected schema to be valid against %s, got: %v", path, set.Description, id, errs)
+				}
+			}
+		}
+	}
+
+	errs, err := pool.Validate("http://json-schema.org/draft-07/schema#", []byte(`{ "type": "strung", "minLength": -1 }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 2 {
+		t.Errorf("expected an invalid schema to fail meta-validation with 2 errors, got: %v", errs)
+	}
+}
+
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return nil, fmt.Errorf("offline")
+}
+
+func TestMetaSchemaRefsResolveOffline(t *testing.T) {
+	prevPool, prevConfig := DefaultSchemaPool, DefaultSchemaPoolConfig
+	defer func() { DefaultSchemaPool, DefaultSchemaPoolConfig = prevPool, prevConfig }()
+	DefaultSchemaPool = Definitions{}
+	transport := &countingTransport{}
+	DefaultSchemaPoolConfig = SchemaPoolConfig{Client: &http.Client{Transport: transport}}
+
+	schema := `{
+		"properties": {
+			"schema": { "$ref": "http://json-schema.org/draft-07/schema#" },
+			"types": { "$ref": "http://json-schema.org/draft-06/schema#/definitions/simpleTypes" }
+		}
+	}`
+	cases := []struct {
+		doc   string
+		valid bool
+	}{
+		{`{ "schema": { "type": "string" }, "types": "integer" }`, true},
+		{`{ "schema": { "type": 1 } }`, false},
+		{`{ "types": "integr" }`, false},
+	}
+
+	rs := Must(schema)
+	if err := rs.FetchRemoteReferences(); err != nil {
+		t.Fatal(err)
+	}
+	lazy := Must(schema)
+	loader := &mapLoader{docs: map[string]string{}, loads: map[string]int{}}
+	for i, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if valid := len(errs) == 0; valid != c.valid {
+			t.Errorf("case %d: expected valid: %t, got errors: %v", i, c.valid, errs)
+		}
+
+		errs, err = lazy.ValidateBytesWithOptions([]byte(c.doc), ValidateOptions{LazyRemoteRefs: true, RefLoader: loader})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if valid := len(errs) == 0; valid != c.valid {
+			t.Errorf("case %d: expected valid with lazy references: %t, got errors: %v", i, c.valid, errs)
+		}
+	}
+	if transport.requests != 0 || len(loader.loads) != 0 {
+		t.Errorf("expected meta-schemas to resolve without fetching, got %d requests and %d loads", transport.requests, len(loader.loads))
+	}
+
+	// resolved meta-schemas are added to the pool like fetched documents
+	DefaultSchemaPool = Definitions{}
+	DefaultSchemaPoolConfig.FetchMetaSchemas = true
+	if err := Must(schema).FetchRemoteReferences(); err != nil {
+		t.Fatal(err)
+	}
+	if transport.requests == 0 {
+		t.Error("expected FetchMetaSchemas to fetch the meta-schemas")
+	}
+}
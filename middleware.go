@@ -0,0 +1,108 @@
+package jsonschema
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MiddlewareOptions configures Middleware
+type MiddlewareOptions struct {
+	// MediaTypes lists the media types of request bodies that are
+	// validated, eg: "application/json". Requests with a body of any other
+	// media type are refused with 415 Unsupported Media Type. By default
+	// "application/json" and types with a "+json" suffix are validated
+	MediaTypes []string
+	// Validate configures validation of request bodies
+	Validate ValidateOptions
+	// ErrorHandler writes the response for requests with invalid bodies,
+	// defaults to WriteErrorResponse
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, res Result)
+}
+
+// Middleware wraps a http.Handler, validating the bodies of requests
+// against the schema before passing them on. Requests with an invalid body
+// are answered by WriteErrorResponse and go no further. The body of valid
+// requests is restored so handlers can read it. Requests without a body
+// pass through, see MiddlewareOptions for which media types are validated
+func Middleware(rs *RootSchema) func(http.Handler) http.Handler {
+	return MiddlewareWithOptions(rs, MiddlewareOptions{})
+}
+
+// MiddlewareWithOptions works like Middleware, configured by opts
+func MiddlewareWithOptions(rs *RootSchema, opts MiddlewareOptions) func(http.Handler) http.Handler {
+	onError := opts.ErrorHandler
+	if onError == nil {
+		onError = WriteErrorResponse
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !opts.validates(r.Header.Get("Content-Type")) {
+				http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+				return
+			}
+
+			body, err := ioutil.ReadAll(r.Body)
+			r.Body.Close()
+			if err != nil {
+				onError(w, r, Result{ParseError: err})
+				return
+			}
+			errs, err := rs.ValidateBytesWithOptions(body, opts.Validate)
+			res := Result{Valid: err == nil && IsValid(errs), Errors: errs, ParseError: err}
+			if !res.Valid {
+				onError(w, r, res)
+				return
+			}
+
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// validates reports whether bodies of the media type given by a
+// Content-Type header are validated
+func (opts *MiddlewareOptions) validates(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if len(opts.MediaTypes) == 0 {
+		return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+	}
+	for _, t := range opts.MediaTypes {
+		if strings.EqualFold(t, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteErrorResponse responds to a request with an invalid body with the
+// JSON list of its errors, with a status of 422 Unprocessable Entity.
+// Bodies that couldn't be read or parsed get a status of 400 Bad Request,
+// and a list holding only the parse error
+func WriteErrorResponse(w http.ResponseWriter, r *http.Request, res Result) {
+	status := http.StatusUnprocessableEntity
+	errs := res.Errors
+	if res.ParseError != nil {
+		status = http.StatusBadRequest
+		errs = []ValError{{Message: res.ParseError.Error()}}
+	}
+	data, err := DefaultEncoder.Marshal(errs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
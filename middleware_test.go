@@ -0,0 +1,71 @@
+package jsonschema
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	rs := Must(`{ "type": "object", "required": ["name"] }`)
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	})
+	handler := Middleware(rs)(echo)
+
+	cases := []struct {
+		contentType, body string
+		status            int
+		response          string
+	}{
+		{"application/json", `{"name":"a"}`, http.StatusOK, `{"name":"a"}`},
+		{"application/merge-patch+json; charset=utf-8", `{"name":"a"}`, http.StatusOK, `{"name":"a"}`},
+		{"application/json", `{}`, http.StatusUnprocessableEntity, `[{"propertyPath":"/","invalidValue":{},"schemaPath":"#/required","message":"\"name\" value is required","keyword":"required"}]`},
+		{"application/json", `{`, http.StatusBadRequest, ``},
+		{"text/plain", `{}`, http.StatusUnsupportedMediaType, ``},
+	}
+
+	for i, c := range cases {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(c.body))
+		req.Header.Set("Content-Type", c.contentType)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != c.status {
+			t.Errorf("case %d: expected status %d, got: %d", i, c.status, w.Code)
+		}
+		if got := strings.TrimSpace(w.Body.String()); c.response != "" && got != c.response {
+			t.Errorf("case %d: expected response:\n%s\ngot:\n%s", i, c.response, got)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected requests without a body to pass through, got status: %d", w.Code)
+	}
+}
+
+func TestMiddlewareErrorHandler(t *testing.T) {
+	rs := Must(`{ "type": "string" }`)
+	opts := MiddlewareOptions{
+		MediaTypes: []string{"text/json"},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, res Result) {
+			http.Error(w, res.Error(), http.StatusTeapot)
+		},
+	}
+	handler := MiddlewareWithOptions(rs, opts)(http.NotFoundHandler())
+
+	req := httptest.NewRequest("PUT", "/", strings.NewReader(`5`))
+	req.Header.Set("Content-Type", "text/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusTeapot {
+		t.Errorf("expected status %d, got: %d", http.StatusTeapot, w.Code)
+	}
+	if expect := `/: 5 type should be string`; strings.TrimSpace(w.Body.String()) != expect {
+		t.Errorf("expected response %q, got: %q", expect, w.Body.String())
+	}
+}
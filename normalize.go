@@ -0,0 +1,114 @@
+package jsonschema
+
+// linkedKeywords are groups of keywords that affect each other's
+// validation, eg: "additionalProperties" depends on "properties". Keywords
+// from the same group can't be merged from different schemas
+var linkedKeywords = [][]string{
+	{"properties", "patternProperties", "additionalProperties"},
+	{"items", "additionalItems"},
+	{"if", "then", "else"},
+	{"contentEncoding", "contentMediaType", "contentSchema"},
+}
+
+// Normalize simplifies the schema in place into a canonical form that
+// validates identically: allOf branches that are themselves only an allOf
+// are spliced into the parent allOf, a single-branch allOf is merged into
+// the schema holding it when their keywords don't overlap, and a "type"
+// array with one element becomes a string. References resolved before
+// normalizing keep their targets, though JSON pointers into merged allOf
+// branches no longer describe the normalized document
+func (rs *RootSchema) Normalize() {
+	walkJSON(&rs.Schema, func(elem JSONPather) error {
+		if sch := nodeSchema(elem); sch != nil {
+			normalizeSchema(sch)
+		}
+		return nil
+	})
+}
+
+// normalizeSchema applies normalization to a single schema
+func normalizeSchema(sch *Schema) {
+	for mergeAllOf(sch) {
+	}
+	if t, ok := sch.Validators["type"].(*Type); ok && len(t.vals) == 1 {
+		t.strVal = true
+	}
+}
+
+// mergeAllOf splices nested allOf branches into the allOf of sch, then
+// merges its only branch into sch if possible, reporting whether a branch
+// was merged
+func mergeAllOf(sch *Schema) bool {
+	all, ok := sch.Validators["allOf"].(*AllOf)
+	if !ok {
+		return false
+	}
+
+	flat := make(AllOf, 0, len(*all))
+	for _, branch := range *all {
+		if nested, ok := branch.Validators["allOf"].(*AllOf); ok && len(branch.Validators) == 1 && !hasSchemaFields(branch) {
+			flat = append(flat, *nested...)
+			continue
+		}
+		flat = append(flat, branch)
+	}
+	*all = flat
+
+	if len(flat) != 1 || !canMergeSchema(sch, flat[0]) {
+		return false
+	}
+	branch := flat[0]
+	delete(sch.Validators, "allOf")
+	for key, v := range branch.Validators {
+		sch.Validators[key] = v
+	}
+	if branch.Format != "" {
+		sch.Format = branch.Format
+	}
+	return true
+}
+
+// canMergeSchema reports whether the keywords of branch, an allOf branch of
+// sch, can be moved into sch without changing how sch validates
+func canMergeSchema(sch, branch *Schema) bool {
+	if branch.schemaType == schemaTypeTrue {
+		return true
+	}
+	if branch.schemaType == schemaTypeFalse || hasSchemaFields(branch) || (sch.Ref != "" && !sch.refSiblings) {
+		return false
+	}
+	if branch.Format != "" && sch.Format != "" {
+		return false
+	}
+	for key := range branch.Validators {
+		if key == "allOf" {
+			// merged on the next pass
+			continue
+		}
+		if _, ok := sch.Validators[key]; ok {
+			return false
+		}
+	}
+	for _, group := range linkedKeywords {
+		inSch, inBranch := false, false
+		for _, key := range group {
+			_, ok := sch.Validators[key]
+			inSch = inSch || ok
+			_, ok = branch.Validators[key]
+			inBranch = inBranch || ok
+		}
+		if inSch && inBranch {
+			return false
+		}
+	}
+	return true
+}
+
+// hasSchemaFields reports whether a schema declares any keywords other than
+// its validators and "format"
+func hasSchemaFields(sch *Schema) bool {
+	return sch.ID != "" || sch.Anchor != "" || sch.Title != "" || sch.Description != "" ||
+		sch.Default != nil || sch.Examples != nil || sch.Example != nil || sch.ReadOnly != nil || sch.WriteOnly != nil ||
+		sch.Comment != "" || sch.Ref != "" || sch.Definitions != nil || sch.Defs != nil ||
+		sch.extraDefinitions != nil
+}
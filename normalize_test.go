@@ -0,0 +1,106 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		schema, expect string
+	}{
+		{`{ "type": ["string"] }`, `{"type":"string"}`},
+		{`{ "allOf": [{ "minLength": 2 }], "type": "string" }`, `{"minLength":2,"type":"string"}`},
+		{`{ "allOf": [{ "allOf": [{ "minimum": 1 }, { "maximum": 3 }] }, { "type": "integer" }] }`, `{"allOf":[{"minimum":1},{"maximum":3},{"type":"integer"}]}`},
+		{`{ "allOf": [{ "allOf": [{ "allOf": [{ "type": ["integer"] }] }] }] }`, `{"type":"integer"}`},
+		{`{ "allOf": [true], "type": "null" }`, `{"type":"null"}`},
+		// overlapping keywords can't be merged
+		{`{ "allOf": [{ "type": "integer" }], "type": "number" }`, `{"allOf":[{"type":"integer"}],"type":"number"}`},
+		// additionalProperties would start to see the parent's properties
+		{`{ "allOf": [{ "additionalProperties": false }], "properties": { "a": {} } }`, `{"allOf":[{"additionalProperties":false}],"properties":{"a":{}}}`},
+		// annotations of the branch would be lost
+		{`{ "allOf": [{ "title": "a", "type": "string" }] }`, `{"allOf":[{"title":"a","type":"string"}]}`},
+		{`{ "properties": { "a": { "allOf": [{ "type": ["boolean"] }] } } }`, `{"properties":{"a":{"type":"boolean"}}}`},
+	}
+
+	for i, c := range cases {
+		rs := Must(c.schema)
+		rs.Normalize()
+		data, err := json.Marshal(rs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got, expect interface{}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(c.expect), &expect); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expect, got) {
+			t.Errorf("case %d: expected: %s, got: %s", i, c.expect, string(data))
+		}
+	}
+}
+
+func TestNormalizeValidatesIdentically(t *testing.T) {
+	schemas := []string{
+		`{ "allOf": [{ "allOf": [{ "type": ["integer", "string"] }, { "minimum": 0 }] }, { "maxLength": 3 }] }`,
+		`{ "allOf": [{ "properties": { "a": { "allOf": [{ "type": ["boolean"] }] } } }], "required": ["a"] }`,
+		`{ "allOf": [{ "items": { "type": "number" } }], "maxItems": 2 }`,
+		`{ "allOf": [{ "additionalProperties": { "type": "null" } }], "properties": { "a": {} } }`,
+		`{ "anyOf": [{ "allOf": [{ "type": ["string"] }] }, { "allOf": [{ "const": 1 }] }] }`,
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for i, s := range schemas {
+		orig, norm := Must(s), Must(s)
+		norm.Normalize()
+		for n := 0; n < 500; n++ {
+			doc := randomInstance(rnd, 3)
+			origErrs, normErrs := []ValError{}, []ValError{}
+			orig.Validate("/", doc, &origErrs)
+			norm.Validate("/", doc, &normErrs)
+			if (len(origErrs) == 0) != (len(normErrs) == 0) {
+				t.Errorf("schema %d: %v is valid against the original: %t, against the normalized schema: %t", i, doc, len(origErrs) == 0, len(normErrs) == 0)
+			}
+		}
+	}
+}
+
+// randomInstance generates a random json-decoded value, nested up to depth
+// levels deep
+func randomInstance(rnd *rand.Rand, depth int) interface{} {
+	kinds := 7
+	if depth <= 0 {
+		kinds = 5
+	}
+	switch rnd.Intn(kinds) {
+	case 0:
+		return nil
+	case 1:
+		return rnd.Intn(2) == 0
+	case 2:
+		return float64(rnd.Intn(7) - 2)
+	case 3:
+		return rnd.Float64() * 4
+	case 4:
+		return []string{"", "a", "ab", "abcd"}[rnd.Intn(4)]
+	case 5:
+		arr := make([]interface{}, rnd.Intn(4))
+		for i := range arr {
+			arr[i] = randomInstance(rnd, depth-1)
+		}
+		return arr
+	default:
+		obj := map[string]interface{}{}
+		for _, key := range []string{"a", "b"} {
+			if rnd.Intn(2) == 0 {
+				obj[key] = randomInstance(rnd, depth-1)
+			}
+		}
+		return obj
+	}
+}
@@ -0,0 +1,52 @@
+package jsonschema
+
+import (
+	"strings"
+)
+
+// OutputUnit is the "basic" output format of draft 2019-09, a flat list
+// of the errors of a validation. It's the format tools like ajv emit
+// https://json-schema.org/draft/2019-09/json-schema-core.html#rfc.section.10.4.2
+type OutputUnit struct {
+	Valid  bool          `json:"valid"`
+	Errors []OutputError `json:"errors,omitempty"`
+}
+
+// OutputError is a single error of an OutputUnit
+type OutputError struct {
+	// KeywordLocation is the JSON pointer to the keyword that failed,
+	// following the path validation took, including through "$ref"
+	KeywordLocation string `json:"keywordLocation"`
+	// AbsoluteKeywordLocation is the URI of the failing keyword, resolved
+	// against the nearest "$id" and through references. It's omitted when
+	// the schema has no absolute "$id"
+	AbsoluteKeywordLocation string `json:"absoluteKeywordLocation,omitempty"`
+	// InstanceLocation is the JSON pointer to the value that failed, the
+	// document itself is ""
+	InstanceLocation string `json:"instanceLocation"`
+	// Error is the message of the error
+	Error string `json:"error"`
+}
+
+// ToOutputUnit converts the errors of a validation to the "basic" output
+// format. Only errors of SeverityError are included, warnings don't
+// affect validity, and have no place in the format
+func ToOutputUnit(errs []ValError) OutputUnit {
+	out := OutputUnit{Valid: IsValid(errs)}
+	for _, e := range errs {
+		if e.Severity != SeverityError {
+			continue
+		}
+		instance := e.PropertyPath
+		if instance == "/" {
+			instance = ""
+		}
+		out.Errors = append(out.Errors, OutputError{
+			KeywordLocation:         strings.TrimPrefix(e.SchemaPath, "#"),
+			AbsoluteKeywordLocation: e.absSchemaPath,
+			InstanceLocation:        instance,
+			Error:                   e.Message,
+		})
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestToOutputUnit(t *testing.T) {
+	// the example of the draft 2019-09 output format section
+	rs := Must(`{
+		"$id": "https://example.com/polygon",
+		"$schema": "https://json-schema.org/draft/2019-09/schema",
+		"$defs": {
+			"point": {
+				"type": "object",
+				"properties": {
+					"x": { "type": "number" },
+					"y": { "type": "number" }
+				},
+				"additionalProperties": false,
+				"required": [ "x", "y" ]
+			}
+		},
+		"type": "array",
+		"items": { "$ref": "#/$defs/point" },
+		"minItems": 3
+	}`)
+
+	errs, err := rs.ValidateBytes([]byte(`[{ "x": 2.5, "y": 1.3 }, { "x": 1, "z": 6.7 }]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := ToOutputUnit(errs)
+	if out.Valid {
+		t.Error("expected output to be invalid")
+	}
+
+	expect := []OutputError{
+		{KeywordLocation: "/items/$ref/additionalProperties", AbsoluteKeywordLocation: "https://example.com/polygon#/$defs/point/additionalProperties", InstanceLocation: "/1/z"},
+		{KeywordLocation: "/items/$ref/required", AbsoluteKeywordLocation: "https://example.com/polygon#/$defs/point/required", InstanceLocation: "/1"},
+		{KeywordLocation: "/minItems", AbsoluteKeywordLocation: "https://example.com/polygon#/minItems", InstanceLocation: ""},
+	}
+	got := make([]OutputError, len(out.Errors))
+	for i, e := range out.Errors {
+		if e.Error == "" {
+			t.Errorf("error %d has no message", i)
+		}
+		e.Error = ""
+		got[i] = e
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].KeywordLocation < got[j].KeywordLocation })
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("output mismatch.\nexpected: %v\ngot:      %v", expect, got)
+	}
+
+	// the encoded form has the shape of the spec's output
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var shape struct {
+		Valid  *bool                    `json:"valid"`
+		Errors []map[string]interface{} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &shape); err != nil {
+		t.Fatal(err)
+	}
+	if shape.Valid == nil || *shape.Valid || len(shape.Errors) != 3 {
+		t.Fatalf("unexpected output shape: %s", data)
+	}
+	for _, e := range shape.Errors {
+		for _, key := range []string{"keywordLocation", "absoluteKeywordLocation", "instanceLocation", "error"} {
+			if _, ok := e[key]; !ok {
+				t.Errorf("expected output error to have %q: %s", key, data)
+			}
+		}
+	}
+
+	if out := ToOutputUnit(nil); !out.Valid || len(out.Errors) != 0 {
+		t.Errorf("expected valid output for no errors, got: %v", out)
+	}
+}
+
+func TestToOutputUnitKeywordLocations(t *testing.T) {
+	rs := Must(`{
+		"properties": { "a/b": { "anyOf": [{ "type": "string" }] } },
+		"items": [{}, { "if": { "type": "integer" }, "then": { "minimum": 10 } }]
+	}`)
+	cases := []struct {
+		doc    string
+		expect string
+	}{
+		{`{"a/b": 1}`, "/properties/a~1b/anyOf"},
+		{`[0, 1]`, "/items/1/then/minimum"},
+	}
+	for i, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := ToOutputUnit(errs)
+		if len(out.Errors) != 1 {
+			t.Errorf("case %d: expected 1 error, got: %v", i, out.Errors)
+			continue
+		}
+		if got := out.Errors[0].KeywordLocation; got != c.expect {
+			t.Errorf("case %d: mismatch. expected: %v, got: %v", i, c.expect, got)
+		}
+	}
+}
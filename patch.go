@@ -0,0 +1,276 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/jsonpointer"
+)
+
+// PatchError is an error applying a JSON Patch, as opposed to a validation
+// error of the patched document
+type PatchError struct {
+	// Index is the position of the failing operation within the patch
+	Index int
+	// Op & Path are the "op" and "path" of the failing operation
+	Op   string
+	Path string
+	// Message describes why the operation failed
+	Message string
+}
+
+// Error implements the error interface for PatchError
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("patch operation %d (%s %q): %s", e.Index, e.Op, e.Path, e.Message)
+}
+
+// ValidatePatched applies an RFC 6902 JSON Patch to the json document
+// original, then validates the patched document. Patches that can't be
+// applied give a *PatchError, including "test" operations that fail
+func (rs *RootSchema) ValidatePatched(original, patch []byte) ([]ValError, error) {
+	var doc interface{}
+	errs := []ValError{}
+	if err := DefaultDecoder.Unmarshal(original, &doc); err != nil {
+		return errs, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
+	}
+	var ops []map[string]interface{}
+	if err := DefaultDecoder.Unmarshal(patch, &ops); err != nil {
+		return errs, fmt.Errorf("error parsing JSON patch: %s", err.Error())
+	}
+
+	doc, err := applyPatch(doc, ops)
+	if err != nil {
+		return errs, err
+	}
+	rs.Validate("/", doc, &errs)
+	return errs, nil
+}
+
+// applyPatch applies the operations of a decoded JSON Patch to doc in
+// order, giving the patched document
+func applyPatch(doc interface{}, ops []map[string]interface{}) (interface{}, error) {
+	for i, op := range ops {
+		name, _ := op["op"].(string)
+		path, ok := op["path"].(string)
+		if !ok {
+			return nil, &PatchError{Index: i, Op: name, Message: `missing "path"`}
+		}
+		var err error
+		if doc, err = applyPatchOperation(doc, name, path, op); err != nil {
+			return nil, &PatchError{Index: i, Op: name, Path: path, Message: err.Error()}
+		}
+	}
+	return doc, nil
+}
+
+// applyPatchOperation applies a single patch operation to doc
+func applyPatchOperation(doc interface{}, name, path string, op map[string]interface{}) (interface{}, error) {
+	ptr, err := parsePatchPointer(path)
+	if err != nil {
+		return nil, err
+	}
+	value, hasValue := op["value"]
+	if !hasValue && (name == "add" || name == "replace" || name == "test") {
+		return nil, fmt.Errorf(`missing "value"`)
+	}
+
+	var from jsonpointer.Pointer
+	if name == "move" || name == "copy" {
+		str, ok := op["from"].(string)
+		if !ok {
+			return nil, fmt.Errorf(`missing "from"`)
+		}
+		if from, err = parsePatchPointer(str); err != nil {
+			return nil, err
+		}
+	}
+
+	switch name {
+	case "add":
+		return patchAdd(doc, ptr, value)
+	case "remove":
+		return patchRemove(doc, ptr)
+	case "replace":
+		if len(ptr) == 0 {
+			return value, nil
+		}
+		if doc, err = patchRemove(doc, ptr); err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, ptr, value)
+	case "move":
+		if len(ptr) > len(from) && from.String() == ptr[:len(from)].String() {
+			return nil, fmt.Errorf("can't move a value into one of its children")
+		}
+		if value, err = patchGet(doc, from); err != nil {
+			return nil, err
+		}
+		if doc, err = patchRemove(doc, from); err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, ptr, value)
+	case "copy":
+		if value, err = patchGet(doc, from); err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, ptr, copyJSON(value))
+	case "test":
+		got, err := patchGet(doc, ptr)
+		if err != nil {
+			return nil, err
+		}
+		if canonicalJSON(got) != canonicalJSON(value) {
+			return nil, fmt.Errorf("test failed, value is %s", canonicalJSON(got))
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// parsePatchPointer parses a JSON pointer, which unlike a reference can't
+// be a URI fragment
+func parsePatchPointer(path string) (jsonpointer.Pointer, error) {
+	if path != "" && path[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q", path)
+	}
+	return jsonpointer.Parse(path)
+}
+
+// patchGet gives the value at ptr, which must exist
+func patchGet(doc interface{}, ptr jsonpointer.Pointer) (interface{}, error) {
+	for _, tok := range ptr {
+		switch v := doc.(type) {
+		case map[string]interface{}:
+			val, ok := v[tok]
+			if !ok {
+				return nil, fmt.Errorf("property %q doesn't exist", tok)
+			}
+			doc = val
+		case []interface{}:
+			i, err := patchIndex(tok, len(v))
+			if err != nil {
+				return nil, err
+			}
+			doc = v[i]
+		default:
+			return nil, fmt.Errorf("can't traverse into a %s", DataType(doc))
+		}
+	}
+	return doc, nil
+}
+
+// patchAdd adds value at ptr, inserting it into arrays
+func patchAdd(doc interface{}, ptr jsonpointer.Pointer, value interface{}) (interface{}, error) {
+	if len(ptr) == 0 {
+		return value, nil
+	}
+	return patchAt(doc, ptr, func(parent interface{}, tok string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[tok] = value
+			return p, nil
+		case []interface{}:
+			i := len(p)
+			if tok != "-" {
+				var err error
+				if i, err = patchIndex(tok, len(p)+1); err != nil {
+					return nil, err
+				}
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = value
+			return p, nil
+		}
+		return nil, fmt.Errorf("can't add to a %s", DataType(parent))
+	})
+}
+
+// patchRemove removes the value at ptr, which must exist
+func patchRemove(doc interface{}, ptr jsonpointer.Pointer) (interface{}, error) {
+	if len(ptr) == 0 {
+		return nil, fmt.Errorf("can't remove the whole document")
+	}
+	return patchAt(doc, ptr, func(parent interface{}, tok string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := p[tok]; !ok {
+				return nil, fmt.Errorf("property %q doesn't exist", tok)
+			}
+			delete(p, tok)
+			return p, nil
+		case []interface{}:
+			i, err := patchIndex(tok, len(p))
+			if err != nil {
+				return nil, err
+			}
+			return append(p[:i], p[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("can't remove from a %s", DataType(parent))
+	})
+}
+
+// patchAt calls fn with the container holding the value ptr points to and
+// the last token of ptr, replacing the container with the one fn returns
+func patchAt(node interface{}, ptr jsonpointer.Pointer, fn func(parent interface{}, tok string) (interface{}, error)) (interface{}, error) {
+	if len(ptr) == 1 {
+		return fn(node, ptr[0])
+	}
+	switch v := node.(type) {
+	case map[string]interface{}:
+		child, ok := v[ptr[0]]
+		if !ok {
+			return nil, fmt.Errorf("property %q doesn't exist", ptr[0])
+		}
+		updated, err := patchAt(child, ptr[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		v[ptr[0]] = updated
+		return v, nil
+	case []interface{}:
+		i, err := patchIndex(ptr[0], len(v))
+		if err != nil {
+			return nil, err
+		}
+		updated, err := patchAt(v[i], ptr[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = updated
+		return v, nil
+	}
+	return nil, fmt.Errorf("can't traverse into a %s", DataType(node))
+}
+
+// patchIndex parses an array index token, which must be less than n
+func patchIndex(tok string, n int) (int, error) {
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || (len(tok) > 1 && tok[0] == '0') || strings.HasPrefix(tok, "+") {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	if i >= n {
+		return 0, fmt.Errorf("array index %d out of bounds", i)
+	}
+	return i, nil
+}
+
+// copyJSON deep-copies a decoded json value
+func copyJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			obj[key] = copyJSON(val)
+		}
+		return obj
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, val := range v {
+			arr[i] = copyJSON(val)
+		}
+		return arr
+	}
+	return value
+}
@@ -0,0 +1,73 @@
+package jsonschema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidatePatched(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"name": { "type": "string" },
+			"tags": { "type": "array", "items": { "type": "string" }, "maxItems": 3 }
+		},
+		"required": ["name"]
+	}`)
+	original := `{ "name": "a", "tags": ["x", "y"], "meta": { "n": 1 } }`
+
+	cases := []struct {
+		patch    string
+		errors   []string
+		patchErr string
+	}{
+		{`[]`, nil, ""},
+		{`[{ "op": "replace", "path": "/name", "value": "b" }]`, nil, ""},
+		{`[{ "op": "replace", "path": "/name", "value": 1 }]`, []string{`/name: 1 type should be string`}, ""},
+		{`[{ "op": "remove", "path": "/name" }, { "op": "remove", "path": "/meta" }, { "op": "remove", "path": "/tags" }]`, []string{`/: {} "name" value is required`}, ""},
+		{`[{ "op": "add", "path": "/tags/-", "value": "z" }, { "op": "add", "path": "/tags/0", "value": "w" }]`, []string{`/tags: ["w","x","y","z"] array length 4 exceeds 3 max`}, ""},
+		{`[{ "op": "move", "from": "/meta/n", "path": "/tags/1" }]`, []string{`/tags/1: 1 type should be string`}, ""},
+		{`[{ "op": "copy", "from": "/name", "path": "/tags/2" }, { "op": "test", "path": "/tags", "value": ["x", "y", "a"] }]`, nil, ""},
+		{`[{ "op": "test", "path": "/name", "value": "b" }]`, nil, `patch operation 0 (test "/name"): test failed, value is "a"`},
+		{`[{ "op": "remove", "path": "/missing" }]`, nil, `patch operation 0 (remove "/missing"): property "missing" doesn't exist`},
+		{`[{ "op": "add", "path": "/tags/5", "value": "z" }]`, nil, `array index 5 out of bounds`},
+		{`[{ "op": "add", "path": "/tags/01", "value": "z" }]`, nil, `invalid array index "01"`},
+		{`[{ "op": "replace", "path": "/name" }]`, nil, `missing "value"`},
+		{`[{ "op": "move", "from": "/meta", "path": "/meta/n" }]`, nil, `into one of its children`},
+		{`[{ "op": "test", "path": "/meta/missing", "value": null }]`, nil, `property "missing" doesn't exist`},
+		{`[{ "op": "invalid", "path": "/name" }]`, nil, `unknown operation "invalid"`},
+		{`[{ "op": "add", "path": "name", "value": 1 }]`, nil, `invalid JSON pointer "name"`},
+	}
+
+	for i, c := range cases {
+		errs, err := rs.ValidatePatched([]byte(original), []byte(c.patch))
+		if c.patchErr != "" {
+			if _, ok := err.(*PatchError); !ok || !strings.Contains(err.Error(), c.patchErr) {
+				t.Errorf("case %d: expected a patch error containing %q, got: %v", i, c.patchErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %s", i, err)
+			continue
+		}
+		got := []string{}
+		for _, e := range errs {
+			got = append(got, e.Error())
+		}
+		expect := c.errors
+		if expect == nil {
+			expect = []string{}
+		}
+		if !reflect.DeepEqual(expect, got) {
+			t.Errorf("case %d: expected errors: %v, got: %v", i, expect, got)
+		}
+	}
+
+	if _, err := rs.ValidatePatched([]byte(original), []byte(`{`)); err == nil {
+		t.Errorf("expected an error parsing an invalid patch")
+	} else if _, ok := err.(*PatchError); ok {
+		t.Errorf("expected a patch that isn't JSON not to be a *PatchError")
+	}
+}
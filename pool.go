@@ -0,0 +1,107 @@
+package jsonschema
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Add registers a schema in the pool by it's "$id", so schemas of the pool
+// can reference it by URI, eg: DefaultSchemaPool.Add(rs). References
+// between schemas of the pool are resolved as they're added, relative
+// references are resolved against the id of the schema containing them.
+// References to documents that aren't in the pool yet stay unresolved until
+// they're added. Add must not be called concurrently with other uses of
+// the pool, an error resolving a reference leaves rs in the pool
+func (d Definitions) Add(rs *RootSchema) error {
+	if rs.ID == "" {
+		return fmt.Errorf(`schema has no "$id" to register it by`)
+	}
+	d[rs.ID] = &rs.Schema
+
+	seen := map[*Schema]bool{}
+	for id, sch := range d {
+		if err := d.resolveRefs(id, sch, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate performs schema validation of a slice of json byte data against
+// the schema registered in the pool under id. References are resolved by
+// Add, so Validate only reads the pool and is safe for concurrent use
+func (d Definitions) Validate(id string, data []byte) ([]ValError, error) {
+	sch := d.lookup(id)
+	if sch == nil {
+		return nil, fmt.Errorf("no schema with id %q in the pool", id)
+	}
+
+	var doc interface{}
+	errs := []ValError{}
+	if err := DefaultDecoder.Unmarshal(data, &doc); err != nil {
+		return errs, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
+	}
+	vc := newValidationContext(nil)
+	vc.Root = doc
+	sch.ValidateContext(vc, "/", doc, &errs)
+	return errs, nil
+}
+
+// lookup finds the schema registered under a URI, ignoring an empty
+// trailing fragment
+func (d Definitions) lookup(uri string) *Schema {
+	uri = strings.TrimSuffix(uri, "#")
+	if sch := d[uri]; sch != nil {
+		return sch
+	}
+	return d[uri+"#"]
+}
+
+// resolveRefs resolves references within sch, a schema of the pool
+// registered under base, to other schemas of the pool, following the
+// references to resolve the schemas they point to in turn
+func (d Definitions) resolveRefs(base string, sch *Schema, seen map[*Schema]bool) error {
+	if seen[sch] {
+		return nil
+	}
+	seen[sch] = true
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return fmt.Errorf("invalid schema id %q: %s", base, err.Error())
+	}
+
+	return walkJSON(sch, func(elem JSONPather) error {
+		s, ok := elem.(*Schema)
+		if !ok || s.Ref == "" || s.ref != nil {
+			return nil
+		}
+		doc, fragment := splitRef(s.Ref)
+		if doc == "" {
+			return nil
+		}
+		u, err := url.Parse(doc)
+		if err != nil {
+			return nil
+		}
+		docURI := baseURL.ResolveReference(u).String()
+		target := d.lookup(docURI)
+		if target == nil {
+			return nil
+		}
+		if err := d.resolveRefs(docURI, target, seen); err != nil {
+			return err
+		}
+
+		if fragment == "" || fragment == "/" {
+			s.ref = target
+			return nil
+		}
+		val, err := (&RootSchema{Schema: *target}).resolveFragment(fragment)
+		if err != nil {
+			return fmt.Errorf("error resolving %s: %s", s.Ref, err.Error())
+		}
+		s.ref = val
+		return nil
+	})
+}
@@ -0,0 +1,109 @@
+package jsonschema
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDefinitionsValidate(t *testing.T) {
+	pool := Definitions{}
+	for _, str := range []string{
+		`{
+			"$id": "http://example.com/person.json",
+			"type": "object",
+			"properties": {
+				"name": { "type": "string" },
+				"home": { "$ref": "address.json" },
+				"work": { "$ref": "http://example.com/address.json#/definitions/street" }
+			}
+		}`,
+		`{
+			"$id": "http://example.com/address.json#",
+			"type": "object",
+			"properties": {
+				"street": { "$ref": "#/definitions/street" },
+				"country": { "$ref": "http://example.com/country.json" }
+			},
+			"definitions": {
+				"street": { "type": "string", "minLength": 2 }
+			}
+		}`,
+		`{ "$id": "http://example.com/country.json", "enum": ["NL", "BG"] }`,
+	} {
+		if err := pool.Add(Must(str)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		id, doc string
+		expect  []string
+	}{
+		{"http://example.com/person.json", `{ "name": "a", "home": { "street": "ab", "country": "NL" }, "work": "cd" }`, nil},
+		{"http://example.com/person.json#", `{ "home": { "street": "a", "country": "US" }, "work": "c" }`, []string{
+			`/home/country: "US" should be one of ["NL", "BG"]`,
+			`/home/street: "a" min length of 2 characters required: a`,
+			`/work: "c" min length of 2 characters required: c`,
+		}},
+		{"http://example.com/address.json", `{ "country": "BG" }`, nil},
+		{"http://example.com/country.json", `"BG"`, nil},
+	}
+
+	for i, c := range cases {
+		errs, err := pool.Validate(c.id, []byte(c.doc))
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %s", i, err)
+			continue
+		}
+		got := []string{}
+		for _, e := range errs {
+			got = append(got, e.Error())
+		}
+		sort.Strings(got)
+		if strings.Join(got, "\n") != strings.Join(c.expect, "\n") {
+			t.Errorf("case %d: expected errors:\n%s\ngot:\n%s", i, strings.Join(c.expect, "\n"), strings.Join(got, "\n"))
+		}
+	}
+
+	if _, err := pool.Validate("http://example.com/missing.json", []byte(`{}`)); err == nil {
+		t.Errorf("expected an error validating against a schema that isn't in the pool")
+	}
+	if err := pool.Add(Must(`{ "type": "string" }`)); err == nil {
+		t.Errorf("expected an error adding a schema without an $id")
+	}
+}
+
+func TestDefinitionsValidateConcurrent(t *testing.T) {
+	pool := Definitions{}
+	for _, str := range []string{
+		`{ "$id": "http://example.com/list.json", "type": "array", "items": { "$ref": "item.json#/definitions/item" } }`,
+		`{ "$id": "http://example.com/item.json", "definitions": { "item": { "type": "integer" } } }`,
+	} {
+		if err := pool.Add(Must(str)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]string, 64)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err := pool.Validate("http://example.com/list.json", []byte(`[1, "two"]`))
+			if err != nil {
+				errs[i] = err.Error()
+				return
+			}
+			errs[i] = errorLines(res)
+		}(i)
+	}
+	wg.Wait()
+	for i, got := range errs {
+		if got != "/1: type should be integer" {
+			t.Errorf("goroutine %d: expected a type error, got: %s", i, got)
+		}
+	}
+}
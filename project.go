@@ -0,0 +1,235 @@
+package jsonschema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/qri-io/jsonpointer"
+)
+
+// Project gives a copy of the schema that only describes the listed
+// top-level properties, eg: to build the schema of a list view from the
+// schema of the full document. The subtrees of kept properties are copied
+// unchanged, other properties are dropped, along with their entries in
+// "required" and "dependencies". Entries of "definitions" and "$defs"
+// that are no longer referenced, directly or through other definitions,
+// are pruned. Definitions are matched to references by JSON pointer
+// fragment, eg: "#/definitions/address", by their "$id", or by a
+// "$anchor" they hold, eg: "#address". Listing a
+// property the schema doesn't have is an error
+func (rs *RootSchema) Project(properties []string) (*RootSchema, error) {
+	doc, err := toGeneric(rs.Schema)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf(`schema has no "properties" to project`)
+	}
+	props, ok := obj["properties"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf(`schema has no "properties" to project`)
+	}
+
+	keep := map[string]bool{}
+	for _, name := range properties {
+		if _, ok := props[name]; !ok {
+			return nil, fmt.Errorf("schema has no property %q", name)
+		}
+		keep[name] = true
+	}
+	for name := range props {
+		if !keep[name] {
+			delete(props, name)
+		}
+	}
+	if required, ok := obj["required"].([]interface{}); ok {
+		if kept := keptNames(required, keep); len(kept) > 0 {
+			obj["required"] = kept
+		} else {
+			delete(obj, "required")
+		}
+	}
+	if deps, ok := obj["dependencies"].(map[string]interface{}); ok {
+		for name, dep := range deps {
+			if !keep[name] {
+				delete(deps, name)
+			} else if list, ok := dep.([]interface{}); ok {
+				deps[name] = keptNames(list, keep)
+			}
+		}
+	}
+	pruneDefinitions(obj)
+
+	if rs.SchemaURI != "" {
+		obj["$schema"] = rs.SchemaURI
+	}
+	data, err := DefaultEncoder.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	projected := &RootSchema{}
+	if err := DefaultDecoder.Unmarshal(data, projected); err != nil {
+		return nil, fmt.Errorf("error parsing projected schema: %s", err.Error())
+	}
+	return projected, nil
+}
+
+// PruneUnusedDefinitions removes the entries of "definitions" and "$defs"
+// that nothing references, directly or through other definitions, starting
+// from the rest of the schema. It gives the sorted locations of the removed
+// definitions, eg: "#/definitions/address". Definitions are matched to
+// references the same way Project matches them
+func (rs *RootSchema) PruneUnusedDefinitions() ([]string, error) {
+	doc, err := toGeneric(rs.Schema)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return []string{}, nil
+	}
+
+	removed := pruneDefinitions(obj)
+	for _, loc := range removed {
+		ptr, _ := jsonpointer.Parse(strings.TrimPrefix(loc, "#"))
+		if ptr[0] == "definitions" {
+			delete(rs.Definitions, ptr[1])
+		} else {
+			delete(rs.Defs, ptr[1])
+		}
+	}
+	if len(rs.Definitions) == 0 {
+		rs.Definitions = nil
+	}
+	if len(rs.Defs) == 0 {
+		rs.Defs = nil
+	}
+	return removed, nil
+}
+
+// keptNames filters a list of property names down to those in keep
+func keptNames(names []interface{}, keep map[string]bool) []interface{} {
+	kept := []interface{}{}
+	for _, name := range names {
+		if str, ok := name.(string); ok && keep[str] {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+// pruneDefinitions removes the entries of "definitions" and "$defs" from
+// the generic JSON form of a root schema that aren't referenced by the rest
+// of the schema, or by the definitions it references. It gives the sorted
+// locations of the removed entries, eg: "#/definitions/address"
+func pruneDefinitions(obj map[string]interface{}) []string {
+	defs := map[string]map[string]interface{}{}
+	for _, key := range []string{"definitions", "$defs"} {
+		if d, ok := obj[key].(map[string]interface{}); ok {
+			defs[key] = d
+		}
+	}
+	if len(defs) == 0 {
+		return nil
+	}
+
+	refs := []string{}
+	for key, val := range obj {
+		if _, ok := defs[key]; !ok {
+			refs = collectRefs(val, refs)
+		}
+	}
+	used := map[string]map[string]bool{"definitions": {}, "$defs": {}}
+	for len(refs) > 0 {
+		ref := refs[0]
+		refs = refs[1:]
+		for key, d := range defs {
+			for name, def := range d {
+				if !used[key][name] && refersTo(ref, key, name, def) {
+					used[key][name] = true
+					refs = collectRefs(def, refs)
+				}
+			}
+		}
+	}
+
+	removed := []string{}
+	for key, d := range defs {
+		for name := range d {
+			if !used[key][name] {
+				delete(d, name)
+				removed = append(removed, "#"+jsonpointer.Pointer{key, name}.String())
+			}
+		}
+		if len(d) == 0 {
+			delete(obj, key)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// collectRefs appends the "$ref" values found in a generic JSON value to
+// refs
+func collectRefs(node interface{}, refs []string) []string {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			refs = append(refs, ref)
+		}
+		for _, val := range v {
+			refs = collectRefs(val, refs)
+		}
+	case []interface{}:
+		for _, elem := range v {
+			refs = collectRefs(elem, refs)
+		}
+	}
+	return refs
+}
+
+// refersTo reports whether ref points to or into the definition name of
+// the root "definitions" or "$defs", given by key
+func refersTo(ref, key, name string, def interface{}) bool {
+	doc, fragment := splitRef(ref)
+	if doc == "" && strings.HasPrefix(fragment, "/") {
+		ptr, err := jsonpointer.Parse(fragment)
+		return err == nil && len(ptr) >= 2 && ptr[0] == key && ptr[1] == name
+	}
+	obj, _ := def.(map[string]interface{})
+	id, _ := obj["$id"].(string)
+	if id == "" {
+		id, _ = obj["id"].(string)
+	}
+	if doc == "" {
+		// plain name fragments, eg: "#address"
+		return fragment != "" && (id == "#"+fragment || hasAnchor(def, fragment))
+	}
+	id = strings.TrimSuffix(id, "#")
+	return id != "" && (doc == id || strings.HasSuffix(doc, "/"+strings.TrimPrefix(id, "/")))
+}
+
+// hasAnchor reports whether a generic JSON value holds a schema with the
+// "$anchor" name
+func hasAnchor(node interface{}, name string) bool {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if anchor, ok := v["$anchor"].(string); ok && anchor == name {
+			return true
+		}
+		for _, val := range v {
+			if hasAnchor(val, name) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, elem := range v {
+			if hasAnchor(elem, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
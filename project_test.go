@@ -0,0 +1,129 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"required": ["id", "name", "address"],
+		"properties": {
+			"id": { "type": "integer" },
+			"name": { "$ref": "#/definitions/name" },
+			"address": { "$ref": "#/definitions/address" },
+			"tags": { "type": "array", "items": { "$ref": "#tag" } }
+		},
+		"dependencies": {
+			"address": ["name"],
+			"id": ["tags", "name"]
+		},
+		"definitions": {
+			"name": { "type": "string", "maxLength": 3 },
+			"address": {
+				"type": "object",
+				"properties": { "country": { "$ref": "#/definitions/country" } }
+			},
+			"country": { "enum": ["NL", "BG"] },
+			"tag": { "$id": "#tag", "type": "string" },
+			"unused": { "type": "null" }
+		}
+	}`)
+
+	projected, err := rs.Project([]string{"id", "name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := toGeneric(projected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"definitions":{"name":{"maxLength":3,"type":"string"}},"dependencies":{"id":["name"]},"properties":{"id":{"type":"integer"},"name":{"$ref":"#/definitions/name"}},"required":["id","name"],"type":"object"}`
+	if canonicalJSON(got) != expect {
+		t.Errorf("expected:\n%s\ngot:\n%s", expect, canonicalJSON(got))
+	}
+
+	errs, err := projected.ValidateBytes([]byte(`{"id": 1, "name": "abcd"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := errorLines(errs); got != "/name: max length of 3 characters exceeded: abcd" {
+		t.Errorf("expected the projected schema to resolve references, got errors:\n%s", got)
+	}
+
+	projected, err = rs.Project([]string{"address", "tags"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var defs []string
+	for name := range projected.Definitions {
+		defs = append(defs, name)
+	}
+	if len(defs) != 3 || projected.Definitions["address"] == nil || projected.Definitions["country"] == nil || projected.Definitions["tag"] == nil {
+		t.Errorf("expected the address, country, and tag definitions to be kept, got: %v", defs)
+	}
+
+	projected, err = Must(`{
+		"properties": { "a": { "$ref": "#tag" }, "b": { "type": "null" } },
+		"$defs": { "tag": { "$anchor": "tag", "type": "string" } }
+	}`).Project([]string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projected.Defs["tag"] == nil {
+		t.Errorf("expected the definition named by an anchor to be kept")
+	}
+
+	if _, err := rs.Project([]string{"email"}); err == nil || err.Error() != `schema has no property "email"` {
+		t.Errorf("expected an error for a missing property, got: %v", err)
+	}
+}
+
+func TestPruneUnusedDefinitions(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"address": { "$ref": "#/definitions/address" },
+			"tag": { "$ref": "#tag" }
+		},
+		"definitions": {
+			"address": {
+				"properties": { "country": { "$ref": "#/$defs/country" } }
+			},
+			"orphan": { "$ref": "#/$defs/orphanChild" }
+		},
+		"$defs": {
+			"country": { "enum": ["NL", "BG"] },
+			"orphanChild": { "type": "string" },
+			"tag": { "$anchor": "tag", "type": "string" }
+		}
+	}`)
+
+	removed, err := rs.PruneUnusedDefinitions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"#/$defs/orphanChild", "#/definitions/orphan"}
+	if len(removed) != len(expect) || removed[0] != expect[0] || removed[1] != expect[1] {
+		t.Errorf("expected removed definitions %v, got: %v", expect, removed)
+	}
+	if len(rs.Definitions) != 1 || rs.Definitions["address"] == nil || len(rs.Defs) != 2 || rs.Defs["country"] == nil || rs.Defs["tag"] == nil {
+		t.Errorf("expected the address, country, and tag definitions to be kept, got: %v %v", rs.Definitions, rs.Defs)
+	}
+
+	errs, err := rs.ValidateBytes([]byte(`{"address": {"country": "FR"}, "tag": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := errorLines(errs); got != "/address/country: should be one of [\"NL\", \"BG\"]\n/tag: type should be string" {
+		t.Errorf("expected references to still resolve, got errors:\n%s", got)
+	}
+
+	removed, err = rs.PruneUnusedDefinitions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 0 {
+		t.Errorf("expected nothing more to prune, got: %v", removed)
+	}
+}
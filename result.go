@@ -0,0 +1,46 @@
+package jsonschema
+
+import (
+	"strings"
+)
+
+// Result is the outcome of validating a json document with Check. A
+// document fails either because it isn't valid json, when ParseError is
+// set, or because it breaks the schema, when Errors holds errors of
+// SeverityError
+type Result struct {
+	// Valid is true when the document parsed and has no errors of
+	// SeverityError
+	Valid bool
+	// Errors lists the validation errors of the document
+	Errors []ValError
+	// ParseError is the error parsing the document, which isn't
+	// validated when it's set
+	ParseError error
+}
+
+// Check performs schema validation against a slice of json byte data like
+// ValidateBytes, returning the outcome as a Result
+func (rs *RootSchema) Check(data []byte) Result {
+	errs, err := rs.ValidateBytes(data)
+	return Result{
+		Valid:      err == nil && IsValid(errs),
+		Errors:     errs,
+		ParseError: err,
+	}
+}
+
+// Error describes why the document failed: the parse error, or it's
+// validation errors one per line. It's "" for valid documents
+func (r Result) Error() string {
+	if r.ParseError != nil {
+		return r.ParseError.Error()
+	}
+	lines := make([]string, 0, len(r.Errors))
+	for _, e := range r.Errors {
+		if e.Severity == SeverityError {
+			lines = append(lines, e.Error())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
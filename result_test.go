@@ -0,0 +1,30 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	rs := Must(`{ "type": "object", "required": ["name"] }`)
+
+	res := rs.Check([]byte(`{ "name": "a" }`))
+	if !res.Valid || len(res.Errors) != 0 || res.ParseError != nil || res.Error() != "" {
+		t.Errorf("expected a valid result, got: %#v", res)
+	}
+
+	res = rs.Check([]byte(`{}`))
+	if res.Valid || res.ParseError != nil || len(res.Errors) != 1 {
+		t.Errorf("expected an invalid result, got: %#v", res)
+	}
+	if expect := `/: {} "name" value is required`; res.Error() != expect {
+		t.Errorf("expected error %q, got: %q", expect, res.Error())
+	}
+
+	res = rs.Check([]byte(`{`))
+	if res.Valid || res.ParseError == nil || len(res.Errors) != 0 {
+		t.Errorf("expected a parse error, got: %#v", res)
+	}
+	if res.Error() != res.ParseError.Error() {
+		t.Errorf("expected the parse error, got: %q", res.Error())
+	}
+}
@@ -0,0 +1,312 @@
+package jsonschema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// IsSatisfiable reports whether any instance could validate against the
+// schema, with reasons describing each contradiction found. Only common
+// contradictions between the keywords of the root schema, the target of
+// it's "$ref", and it's "allOf" branches are detected: conflicting types,
+// bounds that exclude every value of a type, and "const" or "enum" values
+// that can't pass the other constraints. A true result doesn't guarantee
+// an instance exists.
+//
+// Keywords only constrain instances of their own type, so bounds that
+// exclude all numbers only make a schema unsatisfiable when it's also
+// restricted to numeric types
+func (rs *RootSchema) IsSatisfiable() (bool, []string) {
+	return isSatisfiable(&rs.Schema)
+}
+
+// instanceKinds are the kinds of instance a schema can accept, as
+// reported by DataType. "number" stands for numbers that aren't integers
+var instanceKinds = []string{"null", "boolean", "object", "array", "string", "integer", "number"}
+
+func isSatisfiable(sch *Schema) (bool, []string) {
+	reasons := []string{}
+	kinds := map[string]bool{}
+	for _, k := range instanceKinds {
+		kinds[k] = true
+	}
+	var (
+		consts             []interface{}
+		enums              [][]interface{}
+		min, max           *float64
+		exclMin, exclMax   bool
+		minLen, maxLen     = 0, -1
+		minItems, maxItems = 0, -1
+		minProps, maxProps = 0, -1
+	)
+
+	for _, s := range expandSchema(sch, nil, map[*Schema]bool{}) {
+		if s.schemaType == schemaTypeFalse {
+			reasons = append(reasons, "schema is false")
+			return false, reasons
+		}
+		// keywords alongside a reference are ignored during validation,
+		// before draft 2019-09
+		if s.Ref != "" && s.ref != nil && !s.refSiblings {
+			continue
+		}
+
+		for _, v := range s.Validators {
+			switch val := v.(type) {
+			case *Type:
+				allowed := map[string]bool{}
+				for _, t := range val.vals {
+					allowed[t] = true
+					if t == "number" {
+						allowed["integer"] = true
+					}
+				}
+				for k := range kinds {
+					if !allowed[k] {
+						delete(kinds, k)
+					}
+				}
+			case *Const:
+				var c interface{}
+				if err := DefaultDecoder.Unmarshal(*val, &c); err == nil {
+					consts = append(consts, c)
+				}
+			case *Enum:
+				vals := []interface{}{}
+				for _, c := range *val {
+					var e interface{}
+					if err := DefaultDecoder.Unmarshal(c, &e); err == nil {
+						vals = append(vals, e)
+					}
+				}
+				enums = append(enums, vals)
+			case *Minimum:
+				if min == nil || float64(*val) > *min || (float64(*val) == *min && exclMin) {
+					f := float64(*val)
+					min, exclMin = &f, false
+				}
+			case *ExclusiveMinimum:
+				if min == nil || float64(*val) >= *min {
+					f := float64(*val)
+					min, exclMin = &f, true
+				}
+			case *Maximum:
+				if max == nil || float64(*val) < *max || (float64(*val) == *max && exclMax) {
+					f := float64(*val)
+					max, exclMax = &f, false
+				}
+			case *ExclusiveMaximum:
+				if max == nil || float64(*val) <= *max {
+					f := float64(*val)
+					max, exclMax = &f, true
+				}
+			case *MinLength:
+				if int(*val) > minLen {
+					minLen = int(*val)
+				}
+			case *MaxLength:
+				if maxLen == -1 || int(*val) < maxLen {
+					maxLen = int(*val)
+				}
+			case *MinItems:
+				if int(*val) > minItems {
+					minItems = int(*val)
+				}
+			case *MaxItems:
+				if maxItems == -1 || int(*val) < maxItems {
+					maxItems = int(*val)
+				}
+			case *minProperties:
+				if int(*val) > minProps {
+					minProps = int(*val)
+				}
+			case *MaxProperties:
+				if maxProps == -1 || int(*val) < maxProps {
+					maxProps = int(*val)
+				}
+			}
+		}
+	}
+
+	if len(kinds) == 0 {
+		reasons = append(reasons, "no type satisfies every type constraint")
+		return false, reasons
+	}
+
+	// contradictory bounds rule out each kind they apply to
+	eliminate := func(reason string, ks ...string) {
+		for _, k := range ks {
+			delete(kinds, k)
+		}
+		reasons = append(reasons, reason)
+	}
+	if min != nil && max != nil && (*min > *max || (*min == *max && (exclMin || exclMax))) {
+		eliminate(fmt.Sprintf("%s %v and %s %v leave no numbers in range", boundName("minimum", exclMin), *min, boundName("maximum", exclMax), *max), "integer", "number")
+	}
+	if maxLen != -1 && minLen > maxLen {
+		eliminate(fmt.Sprintf("minLength %d is greater than maxLength %d", minLen, maxLen), "string")
+	}
+	if maxItems != -1 && minItems > maxItems {
+		eliminate(fmt.Sprintf("minItems %d is greater than maxItems %d", minItems, maxItems), "array")
+	}
+	if maxProps != -1 && minProps > maxProps {
+		eliminate(fmt.Sprintf("minProperties %d is greater than maxProperties %d", minProps, maxProps), "object")
+	}
+
+	if len(kinds) == 0 {
+		return false, reasons
+	}
+
+	// candidate values from const & enum must pass the other constraints
+	if len(consts) > 0 || len(enums) > 0 {
+		var candidates []interface{}
+		if len(consts) > 0 {
+			candidates = consts[:1]
+			for _, c := range consts[1:] {
+				if !reflect.DeepEqual(c, consts[0]) {
+					reasons = append(reasons, fmt.Sprintf("const values %s and %s conflict", InvalidValueString(consts[0]), InvalidValueString(c)))
+					return false, reasons
+				}
+			}
+		} else {
+			candidates = enums[0]
+			enums = enums[1:]
+		}
+
+		valid := []interface{}{}
+	CANDIDATES:
+		for _, c := range candidates {
+			if !kinds[DataType(c)] {
+				continue
+			}
+			for _, enum := range enums {
+				found := false
+				for _, e := range enum {
+					if reflect.DeepEqual(c, e) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					continue CANDIDATES
+				}
+			}
+			valid = append(valid, c)
+		}
+
+		if len(valid) == 0 {
+			if len(consts) > 0 {
+				reasons = append(reasons, fmt.Sprintf("const value %s doesn't satisfy the other constraints", InvalidValueString(consts[0])))
+			} else {
+				reasons = append(reasons, "no enum value satisfies the other constraints")
+			}
+			return false, reasons
+		}
+	}
+
+	return true, reasons
+}
+
+// boundName gives the keyword name of a numeric bound
+func boundName(name string, exclusive bool) string {
+	if !exclusive {
+		return name
+	}
+	return "exclusive" + strings.Title(name)
+}
+
+// contradictoryAllOf describes the first branch of an "allOf" whose types
+// share none with the branches before it, eg: "branch 0 requires object,
+// branch 1 requires string". Branches without a "type" don't conflict
+func contradictoryAllOf(all AllOf) (string, bool) {
+	var (
+		allowed  map[string]bool
+		branches []map[string]bool
+	)
+	for i, branch := range all {
+		kinds, ok := declaredKinds(branch)
+		branches = append(branches, kinds)
+		if !ok {
+			continue
+		}
+		if allowed == nil {
+			allowed = kinds
+			continue
+		}
+		common := map[string]bool{}
+		for k := range allowed {
+			if kinds[k] {
+				common[k] = true
+			}
+		}
+		if len(common) > 0 {
+			allowed = common
+			continue
+		}
+
+		for j, prev := range branches[:i] {
+			if prev != nil && !sharesKind(prev, kinds) {
+				return fmt.Sprintf("branch %d requires %s, branch %d requires %s", j, kindList(prev), i, kindList(kinds)), true
+			}
+		}
+		return fmt.Sprintf("branches 0 through %d allow no common type", i), true
+	}
+	return "", false
+}
+
+// declaredKinds gives the kinds of instance the "type" keywords of a
+// schema and it's "allOf" branches allow, ok is false when none are
+// declared
+func declaredKinds(sch *Schema) (kinds map[string]bool, ok bool) {
+	for _, s := range expandSchema(sch, nil, map[*Schema]bool{}) {
+		t, isType := s.Validators["type"].(*Type)
+		if !isType || len(t.vals) == 0 {
+			continue
+		}
+		allowed := map[string]bool{}
+		for _, v := range t.vals {
+			allowed[v] = true
+			if v == "number" {
+				allowed["integer"] = true
+			}
+		}
+		if !ok {
+			kinds, ok = allowed, true
+			continue
+		}
+		for k := range kinds {
+			if !allowed[k] {
+				delete(kinds, k)
+			}
+		}
+	}
+	return kinds, ok
+}
+
+func sharesKind(a, b map[string]bool) bool {
+	for k := range a {
+		if b[k] {
+			return true
+		}
+	}
+	return false
+}
+
+// kindList describes a set of kinds, eg: "object or string". integer is
+// left out when number is present, as it's implied
+func kindList(kinds map[string]bool) string {
+	list := []string{}
+	for k := range kinds {
+		if k == "integer" && kinds["number"] {
+			continue
+		}
+		list = append(list, k)
+	}
+	if len(list) == 0 {
+		return "nothing"
+	}
+	sort.Strings(list)
+	return strings.Join(list, " or ")
+}
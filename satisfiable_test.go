@@ -0,0 +1,43 @@
+package jsonschema
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIsSatisfiable(t *testing.T) {
+	cases := []struct {
+		schema      string
+		satisfiable bool
+		reasons     []string
+	}{
+		{`{ "type": "string", "minLength": 2 }`, true, nil},
+		{`false`, false, []string{"schema is false"}},
+		{`{ "allOf": [{ "type": "string" }, { "type": ["integer", "null"] }] }`, false, []string{"no type satisfies every type constraint"}},
+		{`{ "allOf": [{ "type": "number" }, { "type": "integer" }] }`, true, nil},
+		{`{ "type": "integer", "minimum": 5, "maximum": 3 }`, false, []string{"minimum 5 and maximum 3 leave no numbers in range"}},
+		{`{ "type": "number", "exclusiveMinimum": 3, "maximum": 3 }`, false, []string{"exclusiveMinimum 3 and maximum 3 leave no numbers in range"}},
+		// strings are unaffected by numeric bounds
+		{`{ "minimum": 5, "maximum": 3 }`, true, []string{"minimum 5 and maximum 3 leave no numbers in range"}},
+		{`{ "type": "string", "minLength": 5, "maxLength": 2 }`, false, []string{"minLength 5 is greater than maxLength 2"}},
+		{`{ "type": "array", "minItems": 3, "maxItems": 1 }`, false, []string{"minItems 3 is greater than maxItems 1"}},
+		{`{ "type": "object", "minProperties": 3, "maxProperties": 1 }`, false, []string{"minProperties 3 is greater than maxProperties 1"}},
+		{`{ "const": "a", "enum": ["b", "c"] }`, false, []string{"const value \"a\" doesn't satisfy the other constraints"}},
+		{`{ "const": "b", "enum": ["b", "c"] }`, true, nil},
+		{`{ "type": "integer", "enum": ["b", "c"] }`, false, []string{"no enum value satisfies the other constraints"}},
+		{`{ "allOf": [{ "const": 1 }, { "const": 2 }] }`, false, []string{"const values 1 and 2 conflict"}},
+		{`{ "definitions": { "s": { "type": "string" } }, "allOf": [{ "$ref": "#/definitions/s" }, { "type": "boolean" }] }`, false, []string{"no type satisfies every type constraint"}},
+		{`{ "definitions": { "s": { "type": "string" } }, "$ref": "#/definitions/s", "type": "boolean" }`, true, nil},
+		{`{ "$schema": "https://json-schema.org/draft/2019-09/schema", "definitions": { "s": { "type": "string" } }, "$ref": "#/definitions/s", "type": "boolean" }`, false, []string{"no type satisfies every type constraint"}},
+	}
+
+	for i, c := range cases {
+		ok, reasons := Must(c.schema).IsSatisfiable()
+		if ok != c.satisfiable {
+			t.Errorf("case %d: expected satisfiable to be %t, got %t. reasons: %v", i, c.satisfiable, ok, reasons)
+		}
+		if fmt.Sprint(reasons) != fmt.Sprint(c.reasons) && !(len(reasons) == 0 && len(c.reasons) == 0) {
+			t.Errorf("case %d: reasons mismatch. expected: %v, got: %v", i, c.reasons, reasons)
+		}
+	}
+}
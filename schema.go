@@ -8,10 +8,13 @@
 package jsonschema
 
 import (
-	"github.com/json-iterator/go"
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
+	"strings"
 
 	"github.com/qri-io/jsonpointer"
 )
@@ -26,6 +29,61 @@ func Must(jsonString string) *RootSchema {
 	return rs
 }
 
+// ParseOptions configures parsing with ParseWithOptions
+type ParseOptions struct {
+	// SkipAnnotations drops the annotation-only keywords "title",
+	// "description", "$comment", "examples" and "example" from every schema after
+	// parsing, reducing the memory each schema retains. Validation results
+	// are unaffected, though messages that would quote a schema's title
+	// fall back to describing it
+	SkipAnnotations bool
+	// MaxNodes limits the number of schemas in the document, 0 means no
+	// limit
+	MaxNodes int
+	// MaxDepth limits how deeply subschemas may nest, 0 means no limit
+	MaxDepth int
+	// AllowTrailingCommas accepts a comma after the last element of an
+	// array or object, eg: [1, 2,], which strict JSON doesn't allow
+	AllowTrailingCommas bool
+}
+
+// ParseWithOptions parses json byte data into a *RootSchema, configured
+// by opts
+func ParseWithOptions(data []byte, opts ParseOptions) (*RootSchema, error) {
+	if opts.AllowTrailingCommas {
+		data = stripTrailingCommas(data)
+	}
+	// limits are checked on the raw JSON, before any of the schema is built.
+	// Invalid JSON is left for parsing to report
+	if opts.MaxNodes > 0 || opts.MaxDepth > 0 {
+		if st, ok := rawStats(data); ok {
+			if opts.MaxNodes > 0 && st.Nodes > opts.MaxNodes {
+				return nil, fmt.Errorf("schema has %d subschemas, exceeding the limit of %d", st.Nodes, opts.MaxNodes)
+			}
+			if opts.MaxDepth > 0 && st.MaxDepth > opts.MaxDepth {
+				return nil, fmt.Errorf("schema nests %d levels deep, exceeding the limit of %d", st.MaxDepth, opts.MaxDepth)
+			}
+		}
+	}
+	rs := &RootSchema{}
+	if err := rs.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	if opts.SkipAnnotations {
+		walkJSON(&rs.Schema, func(elem JSONPather) error {
+			if sch := nodeSchema(elem); sch != nil {
+				sch.Title = ""
+				sch.Description = ""
+				sch.Comment = ""
+				sch.Examples = nil
+				sch.Example = nil
+			}
+			return nil
+		})
+	}
+	return rs, nil
+}
+
 // DefaultSchemaPool is a package level map of schemas by identifier
 // remote references are cached here.
 var DefaultSchemaPool = Definitions{}
@@ -47,13 +105,80 @@ type RootSchema struct {
 	// for current and previous published drafts of JSON Schema
 	// vocabularies as deemed reasonable.
 	SchemaURI string `json:"$schema"`
+
+	// baseDir is the directory the schema was loaded from, if any.
+	// relative file references are resolved against it
+	baseDir string
+	// lazyRefs caches the references resolved with
+	// ValidateOptions.LazyRemoteRefs while validating against the schema
+	lazyRefs *lazyRefCaches
 }
 
 // TopLevelType returns a string representing the schema's top-level type.
+// A top-level "$ref" is followed to its target. A root without a "type"
+// of its own gets the type every "allOf" branch that declares a type
+// agrees on, or the type all "anyOf" or "oneOf" branches agree on.
+// "unknown" is returned when the type can't be determined
 func (rs *RootSchema) TopLevelType() string {
-	if t, ok := rs.Schema.Validators["type"].(*Type); ok {
+	return topLevelType(&rs.Schema, map[*Schema]bool{})
+}
+
+// topLevelType finds the type of sch, seen guards against reference cycles
+func topLevelType(sch *Schema, seen map[*Schema]bool) string {
+	if sch == nil || seen[sch] {
+		return "unknown"
+	}
+	seen[sch] = true
+	defer delete(seen, sch)
+
+	if sch.Ref != "" {
+		switch ref := sch.ref.(type) {
+		case *Schema:
+			return topLevelType(ref, seen)
+		case *RootSchema:
+			return topLevelType(&ref.Schema, seen)
+		}
+		return "unknown"
+	}
+	if t, ok := sch.Validators["type"].(*Type); ok {
 		return t.String()
 	}
+
+	if all, ok := sch.Validators["allOf"].(*AllOf); ok {
+		common := ""
+		for _, branch := range *all {
+			t := topLevelType(branch, seen)
+			if t == "unknown" {
+				// untyped branches don't constrain the type
+				continue
+			}
+			if common != "" && t != common {
+				return "unknown"
+			}
+			common = t
+		}
+		if common != "" {
+			return common
+		}
+	}
+
+	var branches []*Schema
+	if anyOf, ok := sch.Validators["anyOf"].(*AnyOf); ok {
+		branches = *anyOf
+	} else if oneOf, ok := sch.Validators["oneOf"].(*OneOf); ok {
+		branches = *oneOf
+	}
+	common := ""
+	for _, branch := range branches {
+		t := topLevelType(branch, seen)
+		if t == "unknown" || (common != "" && t != common) {
+			return "unknown"
+		}
+		common = t
+	}
+	if common != "" {
+		return common
+	}
 	return "unknown"
 }
 
@@ -61,7 +186,7 @@ func (rs *RootSchema) TopLevelType() string {
 // RootSchema
 func (rs *RootSchema) UnmarshalJSON(data []byte) error {
 	sch := &Schema{}
-	if err := jsoniter.Unmarshal(data, sch); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, sch); err != nil {
 		return err
 	}
 
@@ -73,38 +198,31 @@ func (rs *RootSchema) UnmarshalJSON(data []byte) error {
 	suri := struct {
 		SchemaURI string `json:"$schema"`
 	}{}
-	if err := jsoniter.Unmarshal(data, &suri); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, &suri); err != nil {
 		return err
 	}
 
+	if refSiblingsDraft(suri.SchemaURI) {
+		walkJSON(sch, func(elem JSONPather) error {
+			if s := nodeSchema(elem); s != nil {
+				s.refSiblings = true
+			}
+			return nil
+		})
+	}
+	if draft4Schema(suri.SchemaURI) || suri.SchemaURI == "" && sch.draft4ID {
+		useDraft4(sch)
+	} else if suri.SchemaURI != "" {
+		ignoreDraft4IDs(sch)
+	}
+
 	root := &RootSchema{
 		Schema:    *sch,
 		SchemaURI: suri.SchemaURI,
 	}
 
 	// collect IDs for internal referencing:
-	ids := map[string]*Schema{}
-	if err := walkJSON(sch, func(elem JSONPather) error {
-		if sch, ok := elem.(*Schema); ok {
-			if sch.ID != "" {
-				ids[sch.ID] = sch
-				// For the record, I think this is ridiculous.
-				if u, err := url.Parse(sch.ID); err == nil {
-					if len(u.Path) >= 1 {
-						ids[u.Path[1:]] = sch
-					} else if len(u.Fragment) >= 1 {
-						// This handles if the identifier is defined as only a fragment (with #)
-						// i.e. #/properties/firstName
-						// in this case, u.Fragment will have /properties/firstName
-						ids[u.Fragment[1:]] = sch
-					}
-				}
-			}
-		}
-		return nil
-	}); err != nil {
-		return err
-	}
+	ids := collectIDs(sch)
 
 	// pass a pointer to the schema component in here (instead of the
 	// RootSchema struct) to ensure root is evaluated for references
@@ -116,6 +234,12 @@ func (rs *RootSchema) UnmarshalJSON(data []byte) error {
 					return nil
 				}
 
+				// references to other documents are resolved by ParseFile
+				// or FetchRemoteReferences
+				if doc, _ := splitRef(sch.Ref); doc != "" {
+					return nil
+				}
+
 				ptr, err := jsonpointer.Parse(sch.Ref)
 				if err != nil {
 					return fmt.Errorf("error evaluating json pointer: %s: %s", err.Error(), sch.Ref)
@@ -143,25 +267,145 @@ func (rs *RootSchema) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// refSiblingsDraft reports whether a "$schema" URI names draft 2019-09 or
+// later, which validate the keywords alongside a "$ref" as well as the
+// reference. Earlier drafts ignore them
+func refSiblingsDraft(schemaURI string) bool {
+	return strings.Contains(schemaURI, "/draft/2019-09/") || strings.Contains(schemaURI, "/draft/2020-12/")
+}
+
+// draft4Schema reports whether a "$schema" URI names draft-04
+func draft4Schema(schemaURI string) bool {
+	return strings.Contains(schemaURI, "/draft-04/")
+}
+
+// useDraft4 gives the keywords within sch their draft-04 meaning: boolean
+// "exclusiveMinimum" and "exclusiveMaximum" make the sibling bound
+// exclusive, and "integer" only matches json.Numbers written without a
+// fraction or exponent
+func useDraft4(sch *Schema) {
+	walkJSON(sch, func(elem JSONPather) error {
+		if s := nodeSchema(elem); s != nil {
+			for _, key := range []string{"exclusiveMinimum", "exclusiveMaximum"} {
+				if b, ok := s.Validators[key].(*exclusiveBound); ok {
+					b.draft4 = true
+				}
+			}
+			if t, ok := s.Validators["type"].(*Type); ok {
+				t.draft4 = true
+			}
+		}
+		return nil
+	})
+}
+
+// ignoreDraft4IDs takes the identity away from the schemas within sch that
+// were identified by the draft-04 "id" keyword, for use with the later
+// drafts that only know "$id"
+func ignoreDraft4IDs(sch *Schema) {
+	walkJSON(sch, func(elem JSONPather) error {
+		if s := nodeSchema(elem); s != nil && s.draft4ID {
+			s.unknownID, s.ID, s.draft4ID = s.ID, "", false
+		}
+		return nil
+	})
+}
+
+// collectIDs maps every "$id" declared within sch to the schema that
+// declares it. "$anchor" names are mapped in their "#name" reference form
+func collectIDs(sch *Schema) map[string]*Schema {
+	ids := map[string]*Schema{}
+	walkJSON(sch, func(elem JSONPather) error {
+		if sch, ok := elem.(*Schema); ok {
+			if sch.Anchor != "" {
+				ids["#"+sch.Anchor] = sch
+			}
+			if sch.ID != "" {
+				ids[sch.ID] = sch
+				// For the record, I think this is ridiculous.
+				if u, err := url.Parse(sch.ID); err == nil {
+					if len(u.Path) >= 1 {
+						ids[u.Path[1:]] = sch
+					} else if len(u.Fragment) >= 1 {
+						// This handles if the identifier is defined as only a fragment (with #)
+						// i.e. #/properties/firstName
+						// in this case, u.Fragment will have /properties/firstName
+						ids[u.Fragment[1:]] = sch
+					}
+				}
+			}
+		}
+		return nil
+	})
+	return ids
+}
+
+// SchemaPoolConfig configures how FetchRemoteReferences fetches the
+// documents it adds to DefaultSchemaPool
+type SchemaPoolConfig struct {
+	// Client makes requests, defaults to http.DefaultClient
+	Client *http.Client
+	// RetryPolicy configures retrying failed fetches
+	RetryPolicy RetryPolicy
+	// Loader loads the documents of remote references, defaults to an
+	// HTTPLoader using Client and RetryPolicy, eg: a FileLoader resolves
+	// references against a local directory
+	Loader Loader
+	// FetchMetaSchemas fetches the standard meta-schemas like any other
+	// document. By default references to them resolve to the copies that
+	// ship with the package, see LoadStandardMetaSchemas
+	FetchMetaSchemas bool
+}
+
+// DefaultSchemaPoolConfig is the configuration used by
+// FetchRemoteReferences
+var DefaultSchemaPoolConfig = SchemaPoolConfig{}
+
 // FetchRemoteReferences grabs any url-based schema references that
-// cannot be locally resolved via network requests
+// cannot be locally resolved via network requests. Fragments of references
+// are resolved within the fetched documents, eg:
+// "http://example.com/other.json#/definitions/Thing"
 func (rs *RootSchema) FetchRemoteReferences() error {
+	return rs.FetchRemoteReferencesContext(context.Background())
+}
+
+// FetchRemoteReferencesContext works like FetchRemoteReferences, giving up
+// when ctx is done, including while waiting to retry a failed fetch.
+// References that can't be reached stay unresolved, responses with an
+// error status are an error once DefaultSchemaPoolConfig.RetryPolicy is
+// exhausted
+func (rs *RootSchema) FetchRemoteReferencesContext(ctx context.Context) error {
+	return rs.fetchRemoteReferences(ctx, DefaultSchemaPool)
+}
+
+// fetchRemoteReferences resolves url-based references against refs,
+// fetching and adding the documents refs doesn't have yet. Documents in
+// DefaultSchemaPool and the standard meta-schemas aren't fetched
+func (rs *RootSchema) fetchRemoteReferences(ctx context.Context, refs Definitions) error {
 	sch := &rs.Schema
 
-	refs := DefaultSchemaPool
+	load := func(ref string) ([]byte, error) {
+		loader := HTTPLoader{Client: DefaultSchemaPoolConfig.Client, Retry: DefaultSchemaPoolConfig.RetryPolicy}
+		return loader.LoadContext(ctx, ref)
+	}
+	if l := DefaultSchemaPoolConfig.Loader; l != nil {
+		load = l.Load
+	}
 
 	if err := walkJSON(sch, func(elem JSONPather) error {
 		if sch, ok := elem.(*Schema); ok {
 			ref := sch.Ref
 			if ref != "" {
 				if refs[ref] == nil && ref[0] != '#' {
-					if u, err := url.Parse(ref); err == nil {
-						if res, err := http.Get(u.String()); err == nil {
-							s := &RootSchema{}
-							if err := jsoniter.NewDecoder(res.Body).Decode(s); err != nil {
-								return err
-							}
-							refs[ref] = &s.Schema
+					if known := knownRef(ref); known != nil {
+						refs[ref] = known
+					} else {
+						target, err := fetchRef(ctx, ref, refs, load)
+						if err != nil {
+							return err
+						}
+						if target != nil {
+							refs[ref] = target
 						}
 					}
 				}
@@ -180,18 +424,267 @@ func (rs *RootSchema) FetchRemoteReferences() error {
 	return nil
 }
 
+// fetchRef resolves a url-based reference, loading its document with load
+// and adding it to refs when refs doesn't have it yet. The fragment of the
+// reference is resolved within the document. It gives nil for documents
+// that can't be reached
+func fetchRef(ctx context.Context, ref string, refs Definitions, load func(string) ([]byte, error)) (*Schema, error) {
+	doc, fragment := splitRef(ref)
+	document := refs.lookup(doc)
+	if document == nil {
+		u, err := url.Parse(doc)
+		if err != nil {
+			return nil, nil
+		}
+		data, err := load(u.String())
+		if _, ok := err.(statusError); ok || (err != nil && ctx.Err() != nil) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, nil
+		}
+		s := &RootSchema{}
+		if err := DefaultDecoder.Unmarshal(data, s); err != nil {
+			return nil, err
+		}
+		document = &s.Schema
+		refs[doc] = document
+	}
+
+	if fragment == "" || fragment == "/" {
+		return document, nil
+	}
+	val, err := (&RootSchema{Schema: *document}).resolveFragment(fragment)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving %s: %s", ref, err.Error())
+	}
+	target, ok := val.(*Schema)
+	if !ok {
+		return nil, fmt.Errorf("error resolving %s: %s is not a json pointer to a json schema", ref, fragment)
+	}
+	return target, nil
+}
+
 // ValidateBytes performs schema validation against a slice of json
 // byte data
 func (rs *RootSchema) ValidateBytes(data []byte) ([]ValError, error) {
 	var doc interface{}
 	errs := []ValError{}
-	if err := jsoniter.Unmarshal(data, &doc); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, &doc); err != nil {
 		return errs, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
 	}
 	rs.Validate("/", doc, &errs)
 	return errs, nil
 }
 
+// ValidateRaw performs schema validation against a raw json message, as
+// obtained from a larger decode. It's identical to ValidateBytes
+func (rs *RootSchema) ValidateRaw(raw json.RawMessage) ([]ValError, error) {
+	return rs.ValidateBytes(raw)
+}
+
+// ValidateValue performs schema validation against a go value. The value is
+// normalized to its JSON form before validating, as if it were marshaled
+// to JSON and decoded into an interface{}, so structs are validated by
+// their json field names, and values implementing json.Marshaler, like
+// time.Time, by the JSON they marshal to: a time.Time validates as a
+// "date-time" string. Values that are already json-decoded, including
+// documents decoded with UseNumber, are validated as they are, so
+// json.Numbers keep their exact value
+func (rs *RootSchema) ValidateValue(value interface{}) ([]ValError, error) {
+	var doc interface{}
+	errs := []ValError{}
+	if isDecoded(value) {
+		doc = value
+	} else if err := normalizeValue(value, &doc); err != nil {
+		return errs, fmt.Errorf("error converting value to JSON: %s", err.Error())
+	}
+	rs.Validate("/", doc, &errs)
+	return errs, nil
+}
+
+// ValidateBytesWithOptions performs schema validation against a slice of
+// json byte data, configured by opts. When validation exceeds
+// opts.Timeout or opts.Deadline it's stopped, and ErrTimeout is returned
+// with the errors found so far
+func (rs *RootSchema) ValidateBytesWithOptions(data []byte, opts ValidateOptions) ([]ValError, error) {
+	var doc interface{}
+	errs := []ValError{}
+	if err := DefaultDecoder.Unmarshal(data, &doc); err != nil {
+		return errs, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
+	}
+	if opts.PreTransform != nil {
+		doc = preTransform("/", doc, opts.PreTransform)
+	}
+	if opts.Coerce {
+		doc = coerce(&rs.Schema, doc)
+	}
+	vc := newValidationContext(&opts)
+	vc.Root = doc
+	if opts.LazyRemoteRefs {
+		vc.lazyRefs = rs.lazyRefsFor(opts.RefLoader)
+	}
+	rs.ValidateContext(vc, "/", doc, &errs)
+	vc.finishProgress(doc)
+	if opts.RejectDuplicateKeys {
+		errs = append(errs, duplicateKeys(data)...)
+	}
+	opts.classify(errs)
+	return errs, vc.err()
+}
+
+// ValidatePartial performs schema validation against a slice of json
+// byte data holding only some properties of a document, eg: the body of a
+// PATCH request. Present properties are validated against their schemas,
+// but objects aren't checked for "required" properties or
+// "minProperties", at any level
+func (rs *RootSchema) ValidatePartial(data []byte) ([]ValError, error) {
+	return rs.ValidateBytesWithOptions(data, ValidateOptions{Partial: true})
+}
+
+// ValidateAndNormalize coerces string scalars to their declared types,
+// applies schema defaults, and rewrites strings of formats registered with
+// RegisterFormatWithNormalizer to their normalized form in a json
+// document, then validates the result.
+// The normalized document is returned even when it has validation errors
+func (rs *RootSchema) ValidateAndNormalize(data []byte) (normalized []byte, errs []ValError, err error) {
+	return rs.ValidateAndNormalizeWithOptions(data, ValidateOptions{})
+}
+
+// ValidateAndNormalizeWithOptions works like ValidateAndNormalize,
+// validating with opts. Values returned by opts.PreTransform are part of
+// the normalized document. Strings are coerced whether or not opts.Coerce
+// is set
+func (rs *RootSchema) ValidateAndNormalizeWithOptions(data []byte, opts ValidateOptions) (normalized []byte, errs []ValError, err error) {
+	var doc interface{}
+	if err = DefaultDecoder.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
+	}
+	if opts.PreTransform != nil {
+		doc = preTransform("/", doc, opts.PreTransform)
+	}
+	doc = coerce(&rs.Schema, doc)
+	doc = applyDefaults(&rs.Schema, doc)
+	doc = normalizeFormats(&rs.Schema, doc)
+
+	errs = []ValError{}
+	vc := newValidationContext(&opts)
+	vc.Root = doc
+	if opts.LazyRemoteRefs {
+		vc.lazyRefs = rs.lazyRefsFor(opts.RefLoader)
+	}
+	rs.ValidateContext(vc, "/", doc, &errs)
+	vc.finishProgress(doc)
+	if opts.RejectDuplicateKeys {
+		errs = append(errs, duplicateKeys(data)...)
+	}
+	opts.classify(errs)
+	if err = vc.err(); err != nil {
+		return nil, errs, err
+	}
+	normalized, err = DefaultEncoder.Marshal(doc)
+	return normalized, errs, err
+}
+
+// classify sets the severity and printed value length of each error
+// according to the options
+func (opts *ValidateOptions) classify(errs []ValError) {
+	for i := range errs {
+		errs[i].valueLen = opts.ValueTruncateLen
+	}
+	if len(opts.WarningKeywords) == 0 {
+		return
+	}
+	for i, e := range errs {
+		for _, kw := range opts.WarningKeywords {
+			if e.Keyword == kw {
+				errs[i].Severity = SeverityWarning
+				break
+			}
+		}
+	}
+}
+
+// ValidateDefinition performs schema validation of json byte data against
+// a single named subschema, looked up in "definitions" first and "$defs"
+// second. References within the definition resolve against the full root.
+func (rs *RootSchema) ValidateDefinition(name string, data []byte) ([]ValError, error) {
+	sch := rs.Definitions[name]
+	if sch == nil {
+		sch = rs.Defs[name]
+	}
+	if sch == nil {
+		return nil, fmt.Errorf("definition not found: %s", name)
+	}
+
+	var doc interface{}
+	errs := []ValError{}
+	if err := DefaultDecoder.Unmarshal(data, &doc); err != nil {
+		return errs, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
+	}
+	sch.Validate("/", doc, &errs)
+	return errs, nil
+}
+
+// SubschemaJSON gives the JSON encoding of the subschema at a JSON pointer
+// into the schema, eg: "/definitions/address". Keywords of the subschema,
+// including "$ref", are encoded as they were parsed, references aren't
+// replaced by their targets
+func (rs *RootSchema) SubschemaJSON(pointer string) ([]byte, error) {
+	sch, err := rs.subschema(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return DefaultEncoder.Marshal(sch)
+}
+
+// subschema gives the subschema at a JSON pointer into rs, the root for
+// "" and "/"
+func (rs *RootSchema) subschema(pointer string) (*Schema, error) {
+	if pointer == "" || pointer == "/" {
+		return &rs.Schema, nil
+	}
+	ptr, err := jsonpointer.Parse(pointer)
+	if err != nil {
+		return nil, fmt.Errorf("error evaluating json pointer: %s: %s", err.Error(), pointer)
+	}
+	res, err := rs.evalJSONValidatorPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	elem, _ := res.(JSONPather)
+	sch := nodeSchema(elem)
+	if sch == nil {
+		return nil, fmt.Errorf("%s is not a json pointer to a json schema", pointer)
+	}
+	return sch, nil
+}
+
+// EnumValues gives the values allowed by the "enum" or "const" keyword of
+// the subschema at a JSON pointer into the schema, eg:
+// "/properties/role". "$ref" and "allOf" are followed to find the
+// keyword. nil is returned if the subschema doesn't restrict its values
+func (rs *RootSchema) EnumValues(pointer string) ([]interface{}, error) {
+	sch, err := rs.subschema(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range expandSchema(sch, nil, map[*Schema]bool{}) {
+		if vals := enumValues(s); vals != nil {
+			return vals, nil
+		}
+		if c, ok := s.Validators["const"].(*Const); ok {
+			var v interface{}
+			if err := DefaultDecoder.Unmarshal(*c, &v); err != nil {
+				return nil, err
+			}
+			return []interface{}{v}, nil
+		}
+	}
+	return nil, nil
+}
+
 func (rs *RootSchema) evalJSONValidatorPointer(ptr jsonpointer.Pointer) (res interface{}, err error) {
 	res = rs
 	for _, token := range ptr {
@@ -253,7 +746,17 @@ type Schema struct {
 	// its parent schema. If no parent sets an explicit base with
 	// "$id", the base URI is that of the entire document, as
 	// determined per RFC 3986 section 5 [RFC3986].
+	// Draft-04 schemas spell the keyword "id", which also sets ID unless
+	// the root "$schema" names a later draft
 	ID string `json:"$id,omitempty"`
+	// draft4ID is set when ID was declared with the draft-04 "id" spelling
+	draft4ID bool
+	// unknownID holds the "id" of a schema of a later draft, where it's an
+	// unknown keyword that doesn't identify the schema
+	unknownID string
+	// Anchor gives the schema a plain name fragment, so it can be
+	// referenced as "#name" from anywhere in the document
+	Anchor string `json:"$anchor,omitempty"`
 	// Title and description can be used to decorate a user interface
 	// with information about the data produced by this user interface.
 	// A title will preferably be short.
@@ -280,6 +783,9 @@ type Schema struct {
 	// present, as an additional example. If "examples" is absent,
 	// "default" MAY still be used in this manner.
 	Examples []interface{} `json:"examples,omitempty"`
+	// Example is the single sample value of OpenAPI and some draft-04
+	// schemas, which predate "examples"
+	Example interface{} `json:"example,omitempty"`
 	// If "readOnly" has a value of boolean true, it indicates that the
 	// value of the instance is managed exclusively by the owning
 	// authority, and attempts by an application to modify the value of
@@ -319,6 +825,10 @@ type Schema struct {
 	// NOT specify any effect of "$comment" beyond what is described in
 	// this specification.
 	Comment string `json:"$comment,omitempty"`
+	// Vocabulary is the draft 2020-12 "$vocabulary" of a meta-schema,
+	// mapping the URIs of the vocabularies it uses to whether they're
+	// required. It's preserved, but vocabularies aren't enforced
+	Vocabulary map[string]bool `json:"$vocabulary,omitempty"`
 	// Ref is used to reference a schema, and provides the ability to
 	// validate recursive structures through self-reference. An object
 	// schema with a "$ref" property MUST be interpreted as a "$ref"
@@ -345,11 +855,17 @@ type Schema struct {
 	Format string `json:"format,omitempty"`
 
 	ref Validator
+	// refSiblings is set for schemas written for draft 2019-09 or later,
+	// where keywords alongside "$ref" apply too
+	refSiblings bool
 
 	// Definitions provides a standardized location for schema authors
 	// to inline re-usable JSON Schemas into a more general schema. The
 	// keyword does not directly affect the validation result.
 	Definitions Definitions `json:"definitions,omitempty"`
+	// Defs is the draft 2019-09 spelling of Definitions. Both locations
+	// are valid $ref targets
+	Defs Definitions `json:"$defs,omitempty"`
 
 	// TODO - currently a bit of a hack to handle arbitrary JSON data
 	// outside the spec
@@ -363,14 +879,79 @@ func (s *Schema) Path() string {
 	return ""
 }
 
+// Types gives the types declared by the schema's "type" keyword, in the
+// order they're declared. both the string and array forms of "type" give
+// a list, which is empty for untyped schemas. references aren't followed
+func (s *Schema) Types() []string {
+	t, ok := s.Validators["type"].(*Type)
+	if !ok {
+		return []string{}
+	}
+	return append([]string{}, t.vals...)
+}
+
+// Keywords lists the validation keywords the schema uses, in sorted order
+func (s *Schema) Keywords() []string {
+	keys := make([]string, 0, len(s.Validators))
+	for key := range s.Validators {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// OrderedValidators gives the validators of the schema in the order of
+// their keywords, as listed by Keywords. Each can be run on its own, the
+// schema's result is the combined errors of all of them
+func (s *Schema) OrderedValidators() []Validator {
+	keys := s.Keywords()
+	vals := make([]Validator, len(keys))
+	for i, key := range keys {
+		vals[i] = s.Validators[key]
+	}
+	return vals
+}
+
 // Validate uses the schema to check an instance, collecting validation
 // errors in a slice
 func (s *Schema) Validate(propPath string, data interface{}, errs *[]ValError) {
-	if s.Ref != "" && s.ref != nil {
-		s.ref.Validate(propPath, data, errs)
+	vc := newValidationContext(nil)
+	vc.Root = data
+	s.ValidateContext(vc, propPath, data, errs)
+}
+
+// ValidateContext implements the ContextValidator interface for Schema
+func (s *Schema) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
+	if vc.expired() {
 		return
-	} else if s.Ref != "" && s.ref == nil {
-		AddError(errs, propPath, data, fmt.Sprintf("%s reference is nil for data: %v", s.Ref, data))
+	}
+	if s.ID != "" {
+		defer vc.leaveSchema(vc.enterID(s.ID))
+	}
+	if s.Ref != "" {
+		start := len(*errs)
+		prev := vc.enterRef(s.Ref)
+		if s.ref != nil {
+			validateWith(vc, s.ref, propPath, data, errs)
+		} else if ref, err := vc.lazyRef(s.Ref); err != nil {
+			AddError(errs, propPath, data, fmt.Sprintf("%s reference could not be loaded: %s", s.Ref, err.Error()))
+			(*errs)[len(*errs)-1].Keyword = "$ref"
+		} else if ref != nil {
+			validateWith(vc, ref, propPath, data, errs)
+		} else {
+			AddError(errs, propPath, data, fmt.Sprintf("%s reference is nil for data: %v", s.Ref, data))
+			(*errs)[len(*errs)-1].Keyword = "$ref"
+		}
+		vc.locateErrors(*errs, start)
+		vc.leaveSchema(prev)
+		// before draft 2019-09 keywords alongside a reference are ignored
+		if !s.refSiblings {
+			return
+		}
+	}
+
+	if s.schemaType == schemaTypeFalse {
+		falseSchemaError(vc, propPath, data, errs, "no value is allowed here")
 		return
 	}
 
@@ -378,16 +959,67 @@ func (s *Schema) Validate(propPath string, data interface{}, errs *[]ValError) {
 	// "default" is made.
 	// Is this correct?
 
-	for _, v := range s.Validators {
-		v.Validate(propPath, data, errs)
+	// an instance of the wrong type skips keywords that constrain some other
+	// type of instance, they would only add noise to the "type" error
+	wrongType := false
+	if t, ok := s.Validators["type"].(*Type); ok && len(t.vals) > 0 && !t.allows(data) {
+		wrongType = true
 	}
+
+	for key, v := range s.Validators {
+		if typ, ok := typedKeywords[key]; wrongType && ok && !(Type{vals: []string{typ}}).allows(data) {
+			continue
+		}
+		start := len(*errs)
+		prev := vc.enterSchema(key)
+		validateWith(vc, v, propPath, data, errs)
+		// errors from nested schemas have already been attributed to the keyword
+		// that produced them
+		for i := start; i < len(*errs); i++ {
+			if (*errs)[i].Keyword == "" {
+				(*errs)[i].Keyword = key
+			}
+		}
+		vc.locateErrors(*errs, start)
+		vc.leaveSchema(prev)
+	}
+}
+
+// falseSchemaError adds the error of a value failing the false schema,
+// which is parsed as { "not": {} }, with a message that doesn't describe
+// an empty schema the value must not match
+func falseSchemaError(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError, message string) {
+	prev := vc.enterSchema("not")
+	AddError(errs, propPath, data, message)
+	(*errs)[len(*errs)-1].Keyword = "not"
+	vc.locateErrors(*errs, len(*errs)-1)
+	vc.leaveSchema(prev)
+}
+
+// typedKeywords maps the standard keywords that only constrain instances
+// of a single type to that type
+var typedKeywords = map[string]string{
+	"multipleOf": "number", "maximum": "number", "exclusiveMaximum": "number", "minimum": "number", "exclusiveMinimum": "number",
+	"maxLength": "string", "minLength": "string", "pattern": "string", "format": "string",
+	"contentEncoding": "string", "contentMediaType": "string", "contentSchema": "string",
+	"items": "array", "additionalItems": "array", "maxItems": "array", "minItems": "array", "uniqueItems": "array", "contains": "array",
+	"maxProperties": "object", "minProperties": "object", "required": "object", "properties": "object", "patternProperties": "object",
+	"additionalProperties": "object", "dependencies": "object", "propertyNames": "object",
 }
 
 // JSONProp implements the JSONPather for Schema
 func (s Schema) JSONProp(name string) interface{} {
+	if name == "id" && s.draft4ID {
+		return s.ID
+	} else if name == "id" && s.unknownID != "" {
+		return s.unknownID
+	}
+
 	switch name {
 	case "$id":
 		return s.ID
+	case "$anchor":
+		return s.Anchor
 	case "title":
 		return s.Title
 	case "description":
@@ -396,16 +1028,22 @@ func (s Schema) JSONProp(name string) interface{} {
 		return s.Default
 	case "examples":
 		return s.Examples
+	case "example":
+		return s.Example
 	case "readOnly":
 		return s.ReadOnly
 	case "writeOnly":
 		return s.WriteOnly
 	case "$comment":
 		return s.Comment
+	case "$vocabulary":
+		return s.Vocabulary
 	case "$ref":
 		return s.Ref
 	case "definitions":
 		return s.Definitions
+	case "$defs":
+		return s.Defs
 	case "format":
 		return s.Format
 	default:
@@ -431,6 +1069,10 @@ func (s Schema) JSONChildren() (ch map[string]JSONPather) {
 		ch["definitions"] = s.Definitions
 	}
 
+	if s.Defs != nil {
+		ch["$defs"] = s.Defs
+	}
+
 	if s.Validators != nil {
 		for key, val := range s.Validators {
 			if jp, ok := val.(JSONPather); ok {
@@ -445,15 +1087,19 @@ func (s Schema) JSONChildren() (ch map[string]JSONPather) {
 // _schema is an internal struct for encoding & decoding purposes
 type _schema struct {
 	ID          string             `json:"$id,omitempty"`
+	Anchor      string             `json:"$anchor,omitempty"`
 	Title       string             `json:"title,omitempty"`
 	Description string             `json:"description,omitempty"`
 	Default     interface{}        `json:"default,omitempty"`
 	Examples    []interface{}      `json:"examples,omitempty"`
+	Example     interface{}        `json:"example,omitempty"`
 	ReadOnly    *bool              `json:"readOnly,omitempty"`
 	WriteOnly   *bool              `json:"writeOnly,omitempty"`
 	Comment     string             `json:"$comment,omitempty"`
+	Vocabulary  map[string]bool    `json:"$vocabulary,omitempty"`
 	Ref         string             `json:"$ref,omitempty"`
 	Definitions map[string]*Schema `json:"definitions,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
 	Format      string             `json:"format,omitempty"`
 }
 
@@ -461,7 +1107,7 @@ type _schema struct {
 func (s *Schema) UnmarshalJSON(data []byte) error {
 	// support simple true false schemas that always pass or fail
 	var b bool
-	if err := jsoniter.Unmarshal(data, &b); err == nil {
+	if err := DefaultDecoder.Unmarshal(data, &b); err == nil {
 		if b {
 			// boolean true Always passes validation, as if the empty schema {}
 			*s = Schema{schemaType: schemaTypeTrue}
@@ -473,21 +1119,25 @@ func (s *Schema) UnmarshalJSON(data []byte) error {
 	}
 
 	_s := _schema{}
-	if err := jsoniter.Unmarshal(data, &_s); err != nil {
+	if err := DefaultDecoder.Unmarshal(data, &_s); err != nil {
 		return err
 	}
 
 	sch := &Schema{
 		ID:          _s.ID,
+		Anchor:      _s.Anchor,
 		Title:       _s.Title,
 		Description: _s.Description,
 		Default:     _s.Default,
 		Examples:    _s.Examples,
+		Example:     _s.Example,
 		ReadOnly:    _s.ReadOnly,
 		WriteOnly:   _s.WriteOnly,
 		Comment:     _s.Comment,
+		Vocabulary:  _s.Vocabulary,
 		Ref:         _s.Ref,
 		Definitions: _s.Definitions,
+		Defs:        _s.Defs,
 		Format:      _s.Format,
 		Validators:  map[string]Validator{},
 	}
@@ -503,19 +1153,47 @@ func (s *Schema) UnmarshalJSON(data []byte) error {
 	// testdata/draft7/ref.json#/4/schema
 	// mean we should return the full object
 
-	valprops := map[string]jsoniter.RawMessage{}
-	if err := jsoniter.Unmarshal(data, &valprops); err != nil {
+	valprops := map[string]json.RawMessage{}
+	if err := DefaultDecoder.Unmarshal(data, &valprops); err != nil {
 		return err
 	}
 
 	for prop, rawmsg := range valprops {
+		if prop == "exclusiveMinimum" || prop == "exclusiveMaximum" {
+			// the draft-04 boolean form
+			var b bool
+			if DefaultDecoder.Unmarshal(rawmsg, &b) == nil {
+				sch.Validators[prop] = &exclusiveBound{exclusive: b, max: prop == "exclusiveMaximum"}
+				continue
+			}
+		}
+		if factory, ok := keywordFactories[prop]; ok {
+			val, err := factory(rawmsg)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling %s from json: %s", prop, err.Error())
+			}
+			sch.Validators[prop] = val
+			continue
+		}
+
 		var val Validator
 		if mk, ok := DefaultValidators[prop]; ok {
 			val = mk()
 		} else {
+			switch prop {
+			case "id":
+				// the draft-04 spelling of "$id"
+				var id string
+				if sch.ID == "" && DefaultDecoder.Unmarshal(rawmsg, &id) == nil {
+					sch.ID = id
+					sch.draft4ID = true
+					continue
+				}
+			}
+
 			switch prop {
 			// skip any already-parsed props
-			case "$schema", "$id", "title", "description", "default", "examples", "readOnly", "writeOnly", "$comment", "$ref", "definitions", "format":
+			case "$schema", "$id", "$anchor", "title", "description", "default", "examples", "example", "readOnly", "writeOnly", "$comment", "$vocabulary", "$ref", "definitions", "$defs", "format":
 				continue
 			default:
 				// assume non-specified props are "extra definitions"
@@ -523,14 +1201,14 @@ func (s *Schema) UnmarshalJSON(data []byte) error {
 					sch.extraDefinitions = Definitions{}
 				}
 				s := new(Schema)
-				if err := jsoniter.Unmarshal(rawmsg, s); err != nil {
+				if err := DefaultDecoder.Unmarshal(rawmsg, s); err != nil {
 					return fmt.Errorf("error unmarshaling %s from json: %s", prop, err.Error())
 				}
 				sch.extraDefinitions[prop] = s
 				continue
 			}
 		}
-		if err := jsoniter.Unmarshal(rawmsg, val); err != nil {
+		if err := DefaultDecoder.Unmarshal(rawmsg, val); err != nil {
 			return fmt.Errorf("error unmarshaling %s from json: %s", prop, err.Error())
 		}
 		sch.Validators[prop] = val
@@ -547,6 +1225,33 @@ func (s *Schema) UnmarshalJSON(data []byte) error {
 		}
 	}
 
+	if enc, ok := sch.Validators["contentEncoding"].(*ContentEncoding); ok {
+		if mt, ok := sch.Validators["contentMediaType"].(*ContentMediaType); ok {
+			mt.encoding = *enc
+		}
+		if cs, ok := sch.Validators["contentSchema"].(*ContentSchema); ok {
+			cs.encoding = *enc
+		}
+	}
+	if mt, ok := sch.Validators["contentMediaType"].(*ContentMediaType); ok {
+		if cs, ok := sch.Validators["contentSchema"].(*ContentSchema); ok {
+			cs.mediaType = mt.MediaType
+		}
+	}
+
+	if b, ok := sch.Validators["exclusiveMinimum"].(*exclusiveBound); ok {
+		if m, ok := sch.Validators["minimum"].(*Minimum); ok {
+			limit := float64(*m)
+			b.limit = &limit
+		}
+	}
+	if b, ok := sch.Validators["exclusiveMaximum"].(*exclusiveBound); ok {
+		if m, ok := sch.Validators["maximum"].(*Maximum); ok {
+			limit := float64(*m)
+			b.limit = &limit
+		}
+	}
+
 	// TODO - replace all these assertions with methods on Schema that return proper types
 	if sch.Validators["items"] != nil && sch.Validators["additionalItems"] != nil && !sch.Validators["items"].(*Items).single {
 		sch.Validators["additionalItems"].(*AdditionalItems).startIndex = len(sch.Validators["items"].(*Items).Schemas)
@@ -572,9 +1277,17 @@ func (s Schema) MarshalJSON() ([]byte, error) {
 	default:
 		obj := map[string]interface{}{}
 
-		if s.ID != "" {
+		if s.ID != "" && s.draft4ID {
+			obj["id"] = s.ID
+		} else if s.ID != "" {
 			obj["$id"] = s.ID
 		}
+		if s.unknownID != "" {
+			obj["id"] = s.unknownID
+		}
+		if s.Anchor != "" {
+			obj["$anchor"] = s.Anchor
+		}
 		if s.Title != "" {
 			obj["title"] = s.Title
 		}
@@ -587,6 +1300,9 @@ func (s Schema) MarshalJSON() ([]byte, error) {
 		if s.Examples != nil {
 			obj["examples"] = s.Examples
 		}
+		if s.Example != nil {
+			obj["example"] = s.Example
+		}
 		if s.ReadOnly != nil {
 			obj["readOnly"] = s.ReadOnly
 		}
@@ -596,12 +1312,18 @@ func (s Schema) MarshalJSON() ([]byte, error) {
 		if s.Comment != "" {
 			obj["$comment"] = s.Comment
 		}
+		if s.Vocabulary != nil {
+			obj["$vocabulary"] = s.Vocabulary
+		}
 		if s.Ref != "" {
 			obj["$ref"] = s.Ref
 		}
 		if s.Definitions != nil {
 			obj["definitions"] = s.Definitions
 		}
+		if s.Defs != nil {
+			obj["$defs"] = s.Defs
+		}
 		if s.Format != "" {
 			obj["format"] = s.Format
 		}
@@ -615,7 +1337,7 @@ func (s Schema) MarshalJSON() ([]byte, error) {
 		for k, v := range s.extraDefinitions {
 			obj[k] = v
 		}
-		return jsoniter.Marshal(obj)
+		return DefaultEncoder.Marshal(obj)
 	}
 }
 
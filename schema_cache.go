@@ -0,0 +1,93 @@
+package jsonschema
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// SchemaCache holds parsed schemas by key, evicting the least recently
+// used schema once it holds MaxSize of them. Keys are typically a
+// schema's "$id", or the content hash used by Parse. A SchemaCache is safe
+// for concurrent use
+type SchemaCache struct {
+	// MaxSize is the most schemas held at once, zero means no limit
+	MaxSize int
+
+	lock    sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// schemaCacheEntry is an element of a SchemaCache's recency list
+type schemaCacheEntry struct {
+	key string
+	rs  *RootSchema
+}
+
+// NewSchemaCache creates a cache holding up to maxSize schemas
+func NewSchemaCache(maxSize int) *SchemaCache {
+	return &SchemaCache{MaxSize: maxSize}
+}
+
+// Get gives the schema stored under key, marking it recently used
+func (c *SchemaCache) Get(key string) (*RootSchema, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*schemaCacheEntry).rs, true
+}
+
+// Put stores a schema under key, replacing any schema already stored
+// under it and evicting the least recently used schema if the cache is
+// full
+func (c *SchemaCache) Put(key string, rs *RootSchema) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]*list.Element{}
+		c.order = list.New()
+	}
+
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*schemaCacheEntry).rs = rs
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&schemaCacheEntry{key: key, rs: rs})
+	for c.MaxSize > 0 && c.order.Len() > c.MaxSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*schemaCacheEntry).key)
+	}
+}
+
+// Len gives the number of schemas in the cache
+func (c *SchemaCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.entries)
+}
+
+// Parse gives the schema for json byte data, keyed by the SHA-256 hash of
+// the data. Data that's been parsed before returns the schema already in
+// the cache without parsing it again
+func (c *SchemaCache) Parse(data []byte) (*RootSchema, error) {
+	sum := sha256.Sum256(data)
+	key := hex.EncodeToString(sum[:])
+	if rs, ok := c.Get(key); ok {
+		return rs, nil
+	}
+
+	rs := &RootSchema{}
+	if err := rs.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	c.Put(key, rs)
+	return rs, nil
+}
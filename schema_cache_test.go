@@ -0,0 +1,59 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestSchemaCache(t *testing.T) {
+	c := NewSchemaCache(2)
+
+	data := []byte(`{ "type": "string" }`)
+	a, err := c.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.Parse([]byte(`{ "type": "string" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("expected a cache hit to return the same schema")
+	}
+	if _, err := c.Parse([]byte(`{ "type": 5 }`)); err == nil {
+		t.Errorf("expected an error parsing an invalid schema")
+	}
+	if c.Len() != 1 {
+		t.Errorf("expected invalid schemas not to be cached, got %d schemas", c.Len())
+	}
+
+	x, y := Must(`{ "$id": "x" }`), Must(`{ "$id": "y" }`)
+	c.Put("x", x)
+	// the parsed schema is now the least recently used
+	c.Put("y", y)
+	if c.Len() != 2 {
+		t.Errorf("expected 2 schemas, got: %d", c.Len())
+	}
+	if rs, err := c.Parse(data); err != nil || rs == a {
+		t.Errorf("expected the parsed schema to have been evicted")
+	}
+	// parsing again evicted x, the least recently used
+	if _, ok := c.Get("x"); ok {
+		t.Errorf("expected x to have been evicted")
+	}
+	if rs, ok := c.Get("y"); !ok || rs != y {
+		t.Errorf("expected y to be cached")
+	}
+
+	c.Put("y", x)
+	if rs, _ := c.Get("y"); rs != x || c.Len() != 2 {
+		t.Errorf("expected put to replace the schema under an existing key")
+	}
+
+	unbounded := &SchemaCache{}
+	for _, key := range []string{"a", "b", "c"} {
+		unbounded.Put(key, x)
+	}
+	if unbounded.Len() != 3 {
+		t.Errorf("expected a zero MaxSize not to evict, got %d schemas", unbounded.Len())
+	}
+}
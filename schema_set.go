@@ -0,0 +1,50 @@
+package jsonschema
+
+import (
+	"fmt"
+)
+
+// SchemaSet is a named collection of schemas that instances are matched
+// against, eg: to classify documents. The zero value is an empty set
+// ready to use
+type SchemaSet struct {
+	names   []string
+	schemas map[string]*RootSchema
+}
+
+// Add puts a schema in the set under name, replacing any schema
+// previously added with the same name
+func (ss *SchemaSet) Add(name string, rs *RootSchema) {
+	if ss.schemas == nil {
+		ss.schemas = map[string]*RootSchema{}
+	}
+	if _, ok := ss.schemas[name]; !ok {
+		ss.names = append(ss.names, name)
+	}
+	ss.schemas[name] = rs
+}
+
+// Names lists the names of schemas in the set, in the order they were
+// added
+func (ss *SchemaSet) Names() []string {
+	return append([]string(nil), ss.names...)
+}
+
+// Match returns the names of every schema in the set that a slice of json
+// byte data validates against, in the order they were added
+func (ss *SchemaSet) Match(data []byte) ([]string, error) {
+	var doc interface{}
+	if err := DefaultDecoder.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing JSON bytes: %s", err.Error())
+	}
+
+	matches := []string{}
+	for _, name := range ss.names {
+		errs := []ValError{}
+		ss.schemas[name].Validate("/", doc, &errs)
+		if len(errs) == 0 {
+			matches = append(matches, name)
+		}
+	}
+	return matches, nil
+}
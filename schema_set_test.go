@@ -0,0 +1,42 @@
+package jsonschema
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSchemaSet(t *testing.T) {
+	ss := &SchemaSet{}
+	ss.Add("person", Must(`{ "type": "object", "required": ["name"] }`))
+	ss.Add("named", Must(`{ "properties": { "name": { "type": "string" } } }`))
+	ss.Add("list", Must(`{ "type": "array" }`))
+
+	cases := []struct {
+		input   string
+		matches []string
+	}{
+		{`{"name": "Alice"}`, []string{"person", "named"}},
+		{`{"name": 5}`, []string{"person"}},
+		{`[1, 2]`, []string{"named", "list"}},
+		{`{}`, []string{"named"}},
+	}
+
+	for i, c := range cases {
+		got, err := ss.Match([]byte(c.input))
+		if err != nil {
+			t.Errorf("case %d error matching: %s", i, err.Error())
+			continue
+		}
+		if fmt.Sprint(got) != fmt.Sprint(c.matches) {
+			t.Errorf("case %d mismatch. expected: %v, got: %v", i, c.matches, got)
+		}
+	}
+
+	ss.Add("list", Must(`{ "type": "object" }`))
+	if names := ss.Names(); fmt.Sprint(names) != "[person named list]" {
+		t.Errorf("expected replacing a schema to keep its position, got: %v", names)
+	}
+	if _, err := ss.Match([]byte(`{`)); err == nil {
+		t.Errorf("expected error matching invalid JSON")
+	}
+}
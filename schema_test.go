@@ -2,14 +2,16 @@ package jsonschema
 
 import (
 	"bytes"
+	"encoding/json"
 	"github.com/json-iterator/go"
 	"fmt"
 	"github.com/sergi/go-diff/diffmatchpatch"
 	"io/ioutil"
 	// "net/http"
 	// "net/http/httptest"
-	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func ExampleBasic() {
@@ -123,6 +125,42 @@ func TestTopLevelType(t *testing.T) {
 	if rs.TopLevelType() != "unknown" {
 		t.Errorf("error: schemaUnknown should have unknown type")
 	}
+
+	cases := []struct {
+		schema, expect string
+	}{
+		{`{ "definitions": { "X": { "type": "object" } }, "$ref": "#/definitions/X" }`, "object"},
+		{`{ "definitions": { "X": { "$ref": "#/definitions/Y" }, "Y": { "type": "array" } }, "$ref": "#/definitions/X" }`, "array"},
+		{`{ "allOf": [{ "type": "object" }, { "required": ["a"] }, { "type": "object" }] }`, "object"},
+		{`{ "allOf": [{ "type": "object" }, { "type": "array" }] }`, "unknown"},
+		{`{ "anyOf": [{ "type": "string" }, { "type": "string", "minLength": 2 }] }`, "string"},
+		{`{ "oneOf": [{ "type": "string" }, { "minimum": 2 }] }`, "unknown"},
+		{`{ "definitions": { "X": { "$ref": "#/definitions/X" } }, "$ref": "#/definitions/X" }`, "unknown"},
+	}
+	for i, c := range cases {
+		if got := Must(c.schema).TopLevelType(); got != c.expect {
+			t.Errorf("case %d: expected top level type %s, got: %s", i, c.expect, got)
+		}
+	}
+}
+
+func TestSchemaTypes(t *testing.T) {
+	cases := []struct {
+		schema string
+		types  []string
+	}{
+		{`{ "type": "string" }`, []string{"string"}},
+		{`{ "type": ["string", "null"] }`, []string{"string", "null"}},
+		{`{ "minimum": 2 }`, []string{}},
+		{`true`, []string{}},
+	}
+
+	for i, c := range cases {
+		got := Must(c.schema).Types()
+		if !reflect.DeepEqual(got, c.types) {
+			t.Errorf("case %d: types mismatch. expected: %v, got: %v", i, c.types, got)
+		}
+	}
 }
 
 func TestParseUrl(t *testing.T) {
@@ -239,14 +277,12 @@ func TestDraft4(t *testing.T) {
 		"testdata/draft4/additionalProperties.json",
 		"testdata/draft4/dependencies.json",
 		"testdata/draft4/maxProperties.json",
-		// TODO - currently doesn't parse:
-		// "testdata/draft4/minimum.json",
+		"testdata/draft4/minimum.json",
 		"testdata/draft4/pattern.json",
 		"testdata/draft4/required.json",
 		"testdata/draft4/allOf.json",
 		"testdata/draft4/enum.json",
-		// TODO - currently doesn't parse:
-		// "testdata/draft4/maximum.json",
+		"testdata/draft4/maximum.json",
 		"testdata/draft4/multipleOf.json",
 		"testdata/draft4/patternProperties.json",
 		"testdata/draft4/type.json",
@@ -260,13 +296,12 @@ func TestDraft4(t *testing.T) {
 		"testdata/draft4/maxItems.json",
 		"testdata/draft4/minLength.json",
 		"testdata/draft4/oneOf.json",
-		// TODO - currently doesn't parse:
-		// "testdata/draft4/ref.json",
+		"testdata/draft4/ref.json",
 
 		// "testdata/draft4/optional/bignum.json",
 		// "testdata/draft4/optional/ecmascript-regex.json",
 		// "testdata/draft4/optional/format.json",
-		// "testdata/draft4/optional/zeroTerminatedFloats.json",
+		"testdata/draft4/optional/zeroTerminatedFloats.json",
 	})
 }
 
@@ -310,7 +345,7 @@ func TestDraft6(t *testing.T) {
 		// "testdata/draft6/optional/bignum.json",
 		// "testdata/draft6/optional/ecmascript-regex.json",
 		// "testdata/draft6/optional/format.json",
-		// "testdata/draft6/optional/zeroTerminatedFloats.json",
+		"testdata/draft6/optional/zeroTerminatedFloats.json",
 	})
 }
 
@@ -318,21 +353,10 @@ func TestDraft7(t *testing.T) {
 	prev := DefaultSchemaPool
 	defer func() { DefaultSchemaPool = prev }()
 
-	path := "testdata/draft-07_schema.json"
-	data, err := ioutil.ReadFile(path)
-	if err != nil {
-		t.Errorf("error reading %s: %s", path, err.Error())
-		return
-	}
-
-	rsch := &RootSchema{}
-	if err := jsoniter.Unmarshal(data, rsch); err != nil {
-		t.Errorf("error unmarshaling schema: %s", err.Error())
-		return
+	if err := LoadStandardMetaSchemas(DefaultSchemaPool); err != nil {
+		t.Fatalf("error loading meta-schemas: %s", err.Error())
 	}
 
-	DefaultSchemaPool["http://json-schema.org/draft-07/schema#"] = &rsch.Schema
-
 	runJSONTests(t, []string{
 		"testdata/draft7/additionalItems.json",
 		"testdata/draft7/contains.json",
@@ -373,7 +397,7 @@ func TestDraft7(t *testing.T) {
 		// "testdata/draft7/optional/bignum.json",
 		// "testdata/draft7/optional/content.json",
 		// "testdata/draft7/optional/ecmascript-regex.json",
-		// "testdata/draft7/optional/zeroTerminatedFloats.json",
+		"testdata/draft7/optional/zeroTerminatedFloats.json",
 		"testdata/draft7/optional/format/date-time.json",
 		"testdata/draft7/optional/format/hostname.json",
 		"testdata/draft7/optional/format/ipv4.json",
@@ -394,56 +418,10 @@ func TestDraft7(t *testing.T) {
 	})
 }
 
-// TestSet is a json-based set of tests
-// JSON-Schema comes with a lovely JSON-based test suite:
-// https://github.com/json-schema-org/JSON-Schema-Test-Suite
-type TestSet struct {
-	Description string      `json:"description"`
-	Schema      *RootSchema `json:"schema"`
-	Tests       []TestCase  `json:"tests"`
-}
-
-type TestCase struct {
-	Description string      `json:"description"`
-	Data        interface{} `json:"data"`
-	Valid       bool        `json:"valid"`
-}
-
 func runJSONTests(t *testing.T, testFilepaths []string) {
-	tests := 0
-	passed := 0
-	for _, path := range testFilepaths {
-		base := filepath.Base(path)
-		testSets := []*TestSet{}
-		data, err := ioutil.ReadFile(path)
-		if err != nil {
-			t.Errorf("error loading test file: %s", err.Error())
-			return
-		}
-
-		if err := jsoniter.Unmarshal(data, &testSets); err != nil {
-			t.Errorf("error unmarshaling test set %s from JSON: %s", base, err.Error())
-			return
-		}
-
-		for _, ts := range testSets {
-			sc := ts.Schema
-			if err := sc.FetchRemoteReferences(); err != nil {
-				t.Errorf("%s: %s error fetching remote references: %s", base, ts.Description, err.Error())
-				continue
-			}
-			for i, c := range ts.Tests {
-				tests++
-				got := []ValError{}
-				sc.Validate("/", c.Data, &got)
-				valid := len(got) == 0
-				if valid != c.Valid {
-					t.Errorf("%s: %s test case %d: %s. error: %s", base, ts.Description, i, c.Description, got)
-				} else {
-					passed++
-				}
-			}
-		}
+	passed, tests, failures := RunTestSuite(DefaultSchemaPool, testFilepaths)
+	for _, f := range failures {
+		t.Error(f.Error())
 	}
 	t.Logf("%d/%d tests passed", passed, tests)
 }
@@ -562,6 +540,557 @@ func TestValidateBytes(t *testing.T) {
 	}
 }
 
+func TestValidateTopLevelScalars(t *testing.T) {
+	rs := Must(`{ "type": "integer", "minimum": 2 }`)
+
+	cases := []struct {
+		input   string
+		value   interface{}
+		keyword string
+		message string
+	}{
+		{`"hello"`, "hello", "type", "type should be integer"},
+		{`null`, nil, "type", "type should be integer"},
+		{`true`, true, "type", "type should be integer"},
+		{`1`, float64(1), "minimum", "must be >= 2"},
+	}
+
+	for i, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != 1 {
+			t.Errorf("case %d: expected 1 error, got: %v", i, errs)
+			continue
+		}
+		e := errs[0]
+		if e.PropertyPath != "/" {
+			t.Errorf("case %d: expected path /, got: %s", i, e.PropertyPath)
+		}
+		if e.Keyword != c.keyword {
+			t.Errorf("case %d: expected keyword %s, got: %s", i, c.keyword, e.Keyword)
+		}
+		if !reflect.DeepEqual(e.InvalidValue, c.value) {
+			t.Errorf("case %d: expected value %v, got: %v", i, c.value, e.InvalidValue)
+		}
+		if e.Message != c.message {
+			t.Errorf("case %d: expected message %s, got: %s", i, c.message, e.Message)
+		}
+	}
+
+	if errs, _ := rs.ValidateBytes([]byte(`3`)); len(errs) != 0 {
+		t.Errorf("expected top-level integer to be valid, got: %v", errs)
+	}
+}
+
+func TestParseSkipAnnotations(t *testing.T) {
+	data := []byte(`{
+		"title": "Person",
+		"description": "a person",
+		"$comment": "root comment",
+		"examples": [{ "name": "a" }],
+		"type": "object",
+		"properties": {
+			"name": { "type": "string", "title": "Name", "$comment": "name comment" },
+			"tags": { "type": "array", "items": { "description": "tag", "type": "string" } }
+		},
+		"not": { "title": "Forbidden", "required": ["forbidden"] },
+		"definitions": {
+			"age": { "type": "integer", "description": "age", "examples": [1] }
+		}
+	}`)
+
+	rs, err := ParseWithOptions(data, ParseOptions{SkipAnnotations: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walkJSON(&rs.Schema, func(elem JSONPather) error {
+		if sch := nodeSchema(elem); sch != nil {
+			if sch.Title != "" || sch.Description != "" || sch.Comment != "" || sch.Examples != nil {
+				t.Errorf("expected annotations to be skipped, got: %#v", sch)
+			}
+		}
+		return nil
+	})
+	if not := rs.Validators["not"].(*Not); not.Title != "" {
+		t.Errorf("expected not title to be skipped, got: %s", not.Title)
+	}
+
+	full, err := ParseWithOptions(data, ParseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if full.Title != "Person" || (*full.Validators["properties"].(*Properties))["name"].Comment != "name comment" {
+		t.Errorf("expected annotations to be kept without SkipAnnotations")
+	}
+
+	for _, doc := range []string{`{ "name": "a", "tags": ["b"] }`, `{ "name": 1, "tags": [2] }`, `{ "forbidden": true }`} {
+		expect, _ := full.ValidateBytes([]byte(doc))
+		got, _ := rs.ValidateBytes([]byte(doc))
+		if len(expect) != len(got) {
+			t.Errorf("%s: expected %d errors, got: %v", doc, len(expect), got)
+		}
+	}
+}
+
+func TestParseComplexityLimits(t *testing.T) {
+	data := []byte(`{ "allOf": [{ "anyOf": [{ "type": "string" }, { "not": { "type": "null" } }] }, { "minLength": 1 }] }`)
+
+	cases := []struct {
+		opts ParseOptions
+		err  string
+	}{
+		{ParseOptions{}, ""},
+		{ParseOptions{MaxNodes: 6, MaxDepth: 3}, ""},
+		{ParseOptions{MaxNodes: 5}, "schema has 6 subschemas, exceeding the limit of 5"},
+		{ParseOptions{MaxDepth: 2}, "schema nests 3 levels deep, exceeding the limit of 2"},
+	}
+
+	for i, c := range cases {
+		_, err := ParseWithOptions(data, c.opts)
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("case %d: unexpected error: %s", i, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != c.err {
+			t.Errorf("case %d: expected error %q, got: %v", i, c.err, err)
+		}
+	}
+
+	// limits are checked before any keyword is parsed
+	_, err := ParseWithOptions([]byte(`{ "allOf": [{ "pattern": "(" }, {}] }`), ParseOptions{MaxNodes: 2})
+	if err == nil || err.Error() != "schema has 3 subschemas, exceeding the limit of 2" {
+		t.Errorf("expected the limit to be checked first, got: %v", err)
+	}
+}
+
+func TestOrderedValidators(t *testing.T) {
+	rs := Must(`{ "type": "integer", "minimum": 3, "multipleOf": 2, "title": "even" }`)
+
+	expectKeys := []string{"minimum", "multipleOf", "type"}
+	if keys := rs.Keywords(); !reflect.DeepEqual(expectKeys, keys) {
+		t.Errorf("expected keywords %v, got: %v", expectKeys, keys)
+	}
+
+	vals := rs.OrderedValidators()
+	if len(vals) != len(expectKeys) {
+		t.Fatalf("expected %d validators, got: %d", len(expectKeys), len(vals))
+	}
+	for i, key := range expectKeys {
+		if vals[i] != rs.Validators[key] {
+			t.Errorf("expected validator %d to be %s", i, key)
+		}
+	}
+
+	// each keyword validates on its own
+	expect := []string{"must be >= 3", "must be a multiple of 2.000000", ""}
+	for i, v := range vals {
+		errs := []ValError{}
+		v.Validate("/", float64(1), &errs)
+		got := ""
+		if len(errs) > 0 {
+			got = errs[0].Message
+		}
+		if got != expect[i] {
+			t.Errorf("%s: expected %q, got: %q", expectKeys[i], expect[i], got)
+		}
+	}
+}
+
+func TestValidateValue(t *testing.T) {
+	type event struct {
+		Name    string    `json:"name"`
+		At      time.Time `json:"at"`
+		Seats   int       `json:"seats"`
+		private string
+	}
+
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"name": { "type": "string", "minLength": 1 },
+			"at": { "type": "string", "format": "date-time" },
+			"seats": { "type": "integer", "minimum": 1 }
+		},
+		"required": ["name", "at", "seats"]
+	}`)
+
+	at := time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)
+	errs, err := rs.ValidateValue(event{Name: "launch", At: at, Seats: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+
+	errs, err = rs.ValidateValue(&event{At: at})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := sortedErrorStrings(errs)
+	expect := []string{"/name: min length of 1 characters required: ", "/seats: must be >= 1"}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("expected: %v, got: %v", expect, got)
+	}
+
+	if dt := DataType(at); dt != "string" {
+		t.Errorf("expected time.Time to have data type string, got: %s", dt)
+	}
+	if _, err := rs.ValidateValue(func() {}); err == nil {
+		t.Errorf("expected an error for a value that can't be converted to JSON")
+	}
+}
+
+func TestEnumValues(t *testing.T) {
+	rs := Must(`{
+		"properties": {
+			"role": { "$ref": "#/definitions/role" },
+			"kind": { "const": "user" },
+			"size": { "allOf": [{ "type": "string" }, { "enum": ["s", "m", "l"] }] },
+			"name": { "type": "string" }
+		},
+		"definitions": {
+			"role": { "enum": ["admin", "read-only", null] }
+		},
+		"enum": [{}]
+	}`)
+
+	cases := []struct {
+		pointer string
+		expect  []interface{}
+		err     string
+	}{
+		{"/properties/role", []interface{}{"admin", "read-only", nil}, ""},
+		{"/properties/kind", []interface{}{"user"}, ""},
+		{"/properties/size", []interface{}{"s", "m", "l"}, ""},
+		{"/properties/name", nil, ""},
+		{"/", []interface{}{map[string]interface{}{}}, ""},
+		{"/properties/missing", nil, "/properties/missing is not a json pointer to a json schema"},
+		{"/properties", nil, "/properties is not a json pointer to a json schema"},
+	}
+
+	for _, c := range cases {
+		got, err := rs.EnumValues(c.pointer)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("%s: expected error %q, got: %v", c.pointer, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.pointer, err)
+			continue
+		}
+		if !reflect.DeepEqual(c.expect, got) {
+			t.Errorf("%s: expected %v, got: %v", c.pointer, c.expect, got)
+		}
+	}
+}
+
+func TestRefSiblings(t *testing.T) {
+	schema := `{
+		%s
+		"definitions": { "name": { "type": "string" } },
+		"properties": {
+			"name": { "$ref": "#/definitions/name", "description": "a short name", "maxLength": 3 }
+		}
+	}`
+
+	cases := []struct {
+		schemaURI string
+		doc       string
+		expect    []string
+	}{
+		{`"$schema": "http://json-schema.org/draft-07/schema#",`, `{ "name": "abcd" }`, []string{}},
+		{``, `{ "name": "abcd" }`, []string{}},
+		{`"$schema": "http://json-schema.org/draft-07/schema#",`, `{ "name": 1 }`, []string{"/name: 1 type should be string"}},
+		{`"$schema": "https://json-schema.org/draft/2019-09/schema",`, `{ "name": "abcd" }`, []string{`/name: "abcd" max length of 3 characters exceeded: abcd`}},
+		{`"$schema": "https://json-schema.org/draft/2020-12/schema",`, `{ "name": "abcd" }`, []string{`/name: "abcd" max length of 3 characters exceeded: abcd`}},
+		{`"$schema": "https://json-schema.org/draft/2019-09/schema",`, `{ "name": 1 }`, []string{"/name: 1 type should be string"}},
+		{`"$schema": "https://json-schema.org/draft/2019-09/schema",`, `{ "name": "abc" }`, []string{}},
+	}
+
+	for i, c := range cases {
+		rs := Must(fmt.Sprintf(schema, c.schemaURI))
+		errs, err := rs.ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, len(errs))
+		for j, e := range errs {
+			got[j] = e.Error()
+		}
+		if !reflect.DeepEqual(c.expect, got) {
+			t.Errorf("case %d: expected %v, got: %v", i, c.expect, got)
+		}
+	}
+}
+
+func TestDraft4ID(t *testing.T) {
+	data := []byte(`{
+		"id": "http://localhost:1234/tree",
+		"type": "object",
+		"properties": {
+			"nodes": { "type": "array", "items": { "$ref": "node" } }
+		},
+		"definitions": {
+			"node": {
+				"id": "http://localhost:1234/node",
+				"type": "object",
+				"properties": {
+					"value": { "type": "number" },
+					"subtree": { "$ref": "tree" }
+				},
+				"required": ["value"]
+			}
+		}
+	}`)
+
+	rs := &RootSchema{}
+	if err := json.Unmarshal(data, rs); err != nil {
+		t.Fatal(err)
+	}
+	if rs.ID != "http://localhost:1234/tree" {
+		t.Errorf("expected id to set ID, got: %q", rs.ID)
+	}
+
+	errs, err := rs.ValidateBytes([]byte(`{ "nodes": [{ "value": 1, "subtree": { "nodes": [{ "value": "a" }] } }] }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].PropertyPath != "/nodes/0/subtree/nodes/0/value" {
+		t.Errorf("expected one error for the nested value, got: %v", errs)
+	}
+
+	// round trips keep the spelling of the source
+	for _, src := range []string{`{"id":"http://example.com/a"}`, `{"$id":"http://example.com/a"}`} {
+		sch := Must(src)
+		out, err := json.Marshal(sch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != src {
+			t.Errorf("expected %s to round trip, got: %s", src, string(out))
+		}
+	}
+
+	// "id" that isn't a string isn't an identifier
+	if rs := Must(`{ "id": { "type": "string" } }`); rs.ID != "" {
+		t.Errorf("expected a schema valued id to be ignored, got: %q", rs.ID)
+	}
+
+	// later drafts only know "$id"
+	rs = Must(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "http://localhost:1234/root.json",
+		"properties": {
+			"a": { "id": "http://localhost:1234/nested/", "type": "string" }
+		}
+	}`)
+	errs, err = rs.ValidateBytes([]byte(`{ "a": 1 }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := ToOutputUnit(errs)
+	if len(out.Errors) != 1 || out.Errors[0].AbsoluteKeywordLocation != "http://localhost:1234/root.json#/properties/a/type" {
+		t.Errorf("expected id not to change the base URI, got: %v", out.Errors)
+	}
+	props, err := toGeneric(rs.JSONProp("properties"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := canonicalJSON(props); got != `{"a":{"id":"http://localhost:1234/nested/","type":"string"}}` {
+		t.Errorf("expected id to be kept as an unknown keyword, got: %s", got)
+	}
+}
+
+func TestValidateRaw(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"tags": { "type": "array", "items": { "type": "string" } }
+		}
+	}`)
+
+	var envelope struct {
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal([]byte(`{"payload": {"tags": ["a", 2, 3]}}`), &envelope); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := rs.ValidateRaw(envelope.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := rs.ValidateBytes([]byte(envelope.Payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != 2 || len(raw) != len(decoded) {
+		t.Fatalf("error length mismatch. ValidateRaw: %v, ValidateBytes: %v", raw, decoded)
+	}
+	for i := range raw {
+		if raw[i].PropertyPath != decoded[i].PropertyPath {
+			t.Errorf("error %d path mismatch. ValidateRaw: %s, ValidateBytes: %s", i, raw[i].PropertyPath, decoded[i].PropertyPath)
+		}
+	}
+}
+
+func TestAnchorRefs(t *testing.T) {
+	rs := Must(`{
+		"$defs": {
+			"node": {
+				"$anchor": "node",
+				"type": "object",
+				"properties": {
+					"value": { "type": "integer" },
+					"next": { "$ref": "#node" }
+				}
+			}
+		},
+		"properties": {
+			"head": { "$ref": "#node" }
+		}
+	}`)
+
+	cases := []struct {
+		input  string
+		errors []string
+	}{
+		{`{"head": {"value": 1, "next": {"value": 2}}}`, nil},
+		{`{"head": {"value": 1, "next": {"value": "2"}}}`, []string{`/head/next/value: "2" type should be integer`}},
+		{`{"head": 5}`, []string{`/head: 5 type should be object`}},
+	}
+
+	for i, c := range cases {
+		errs, err := rs.ValidateBytes([]byte(c.input))
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err.Error())
+			continue
+		}
+		if len(errs) != len(c.errors) {
+			t.Errorf("case %d: error length mismatch. expected: %d, got: %v", i, len(c.errors), errs)
+			continue
+		}
+		for j, e := range errs {
+			if e.Error() != c.errors[j] {
+				t.Errorf("case %d: validation error %d mismatch. expected: %s, got: %s", i, j, c.errors[j], e.Error())
+			}
+		}
+	}
+
+	if err := jsoniter.Unmarshal([]byte(`{ "$ref": "#missing" }`), &RootSchema{}); err == nil {
+		t.Errorf("expected error referencing an undeclared anchor")
+	}
+}
+
+func TestValidateDefinition(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"definitions": {
+			"id": { "type": "integer", "minimum": 1 },
+			"user": {
+				"type": "object",
+				"properties": { "id": { "$ref": "#/definitions/id" } },
+				"required": ["id"]
+			}
+		},
+		"$defs": {
+			"name": { "type": "string" }
+		}
+	}`)
+
+	cases := []struct {
+		name   string
+		input  string
+		errors []string
+	}{
+		{"user", `{"id": 4}`, nil},
+		{"user", `{"id": 0}`, []string{`/id: 0 must be >= 1`}},
+		{"user", `{}`, []string{`/: {} "id" value is required`}},
+		{"id", `"4"`, []string{`/: "4" type should be integer`}},
+		{"name", `"Alice"`, nil},
+		{"name", `5`, []string{`/: 5 type should be string`}},
+	}
+
+	for i, c := range cases {
+		errors, err := rs.ValidateDefinition(c.name, []byte(c.input))
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err.Error())
+			continue
+		}
+
+		if len(errors) != len(c.errors) {
+			t.Errorf("case %d: error length mismatch. expected: %d, got: %d", i, len(c.errors), len(errors))
+			t.Errorf("%v", errors)
+			continue
+		}
+
+		for j, e := range errors {
+			if e.Error() != c.errors[j] {
+				t.Errorf("case %d: validation error %d mismatch. expected: %s, got: %s", i, j, c.errors[j], e.Error())
+			}
+		}
+	}
+
+	if _, err := rs.ValidateDefinition("missing", []byte(`{}`)); err == nil {
+		t.Errorf("expected error validating against a missing definition")
+	}
+}
+
+func TestValidateAndNormalize(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"port": { "type": "integer", "default": 8080, "maximum": 65535 },
+			"debug": { "type": "boolean", "default": false },
+			"host": { "type": "string" }
+		},
+		"required": ["host"]
+	}`)
+
+	cases := []struct {
+		input, output string
+		errors        int
+	}{
+		{`{"host": "localhost"}`, `{"debug":false,"host":"localhost","port":8080}`, 0},
+		{`{"host": "localhost", "port": "80", "debug": "true"}`, `{"debug":true,"host":"localhost","port":80}`, 0},
+		{`{"port": "70000"}`, `{"debug":false,"port":70000}`, 2},
+	}
+
+	for i, c := range cases {
+		got, errs, err := rs.ValidateAndNormalize([]byte(c.input))
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err.Error())
+			continue
+		}
+		if len(errs) != c.errors {
+			t.Errorf("case %d: error length mismatch. expected: %d, got: %v", i, c.errors, errs)
+		}
+
+		var expect, result interface{}
+		if err := jsoniter.Unmarshal([]byte(c.output), &expect); err != nil {
+			t.Fatal(err)
+		}
+		if err := jsoniter.Unmarshal(got, &result); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expect, result) {
+			t.Errorf("case %d normalized mismatch. expected: %s, got: %s", i, c.output, string(got))
+		}
+	}
+
+	if _, _, err := rs.ValidateAndNormalize([]byte(`{`)); err == nil {
+		t.Errorf("expected error normalizing invalid JSON")
+	}
+}
+
 // TODO - finish remoteRef.json tests by setting up a httptest server on localhost:1234
 // that uses an http.Dir to serve up testdata/remotes directory
 // func testServer() {
@@ -569,3 +1098,317 @@ func TestValidateBytes(t *testing.T) {
 
 // 	}))
 // }
+
+func TestValidatePartial(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"name": { "type": "string", "minLength": 2 },
+			"address": {
+				"type": "object",
+				"properties": { "zip": { "type": "string" } },
+				"required": ["zip", "city"],
+				"minProperties": 2
+			}
+		},
+		"required": ["name", "address"],
+		"minProperties": 2
+	}`)
+
+	cases := []struct {
+		doc    string
+		expect []string
+	}{
+		{`{}`, nil},
+		{`{ "name": "ab" }`, nil},
+		{`{ "address": {} }`, nil},
+		{`{ "address": { "zip": "12345" } }`, nil},
+		{`{ "name": "a" }`, []string{`/name: "a" min length of 2 characters required: a`}},
+		{`{ "address": { "zip": 12345 } }`, []string{`/address/zip: 12345 type should be string`}},
+	}
+
+	for i, c := range cases {
+		errs, err := rs.ValidatePartial([]byte(c.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, e := range errs {
+			got = append(got, e.Error())
+		}
+		expect := c.expect
+		if expect == nil {
+			expect = []string{}
+		}
+		if !reflect.DeepEqual(expect, got) {
+			t.Errorf("case %d: expected errors: %v, got: %v", i, expect, got)
+		}
+	}
+
+	errs, err := rs.ValidateBytes([]byte(`{ "address": {} }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 5 {
+		t.Errorf("expected full validation to check required properties and minProperties, got: %v", errs)
+	}
+}
+
+func TestSubschemaJSON(t *testing.T) {
+	rs := Must(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"properties": {
+			"home": { "$ref": "#/definitions/address" },
+			"a/b": { "type": "integer", "minimum": 2 }
+		},
+		"definitions": {
+			"address": {
+				"type": "object",
+				"properties": { "country": { "$ref": "#/definitions/country" } },
+				"required": ["country"]
+			},
+			"country": { "enum": ["NL", "BG"] }
+		}
+	}`)
+
+	cases := []struct {
+		pointer, expect string
+		err             string
+	}{
+		{"/definitions/address", `{"type":"object","properties":{"country":{"$ref":"#/definitions/country"}},"required":["country"]}`, ""},
+		{"/properties/home", `{"$ref":"#/definitions/address"}`, ""},
+		{"/properties/a~1b", `{"type":"integer","minimum":2}`, ""},
+		{"/definitions/address/properties/country", `{"$ref":"#/definitions/country"}`, ""},
+		{"/definitions/missing", "", "/definitions/missing is not a json pointer to a json schema"},
+		{"/definitions", "", "/definitions is not a json pointer to a json schema"},
+	}
+
+	for _, c := range cases {
+		data, err := rs.SubschemaJSON(c.pointer)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("%s: expected error %q, got: %v", c.pointer, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.pointer, err)
+			continue
+		}
+		var got, expect interface{}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		json.Unmarshal([]byte(c.expect), &expect)
+		if !reflect.DeepEqual(expect, got) {
+			t.Errorf("%s: expected: %s, got: %s", c.pointer, c.expect, data)
+		}
+	}
+
+	data, err := rs.SubschemaJSON("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte(`"definitions"`)) {
+		t.Errorf("expected the root schema, got: %s", data)
+	}
+}
+
+func TestIntegerCoding(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/coding/integers.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs := &RootSchema{}
+	if err := DefaultDecoder.Unmarshal(data, rs); err != nil {
+		t.Fatal(err)
+	}
+	output, err := DefaultEncoder.Marshal(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// decoding numbers as json.Number compares their text, so 0 and 0.0
+	// differ, while key order and whitespace don't matter
+	decode := func(data []byte) interface{} {
+		var v interface{}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	if expect, got := decode(data), decode(output); !reflect.DeepEqual(expect, got) {
+		t.Errorf("integers didn't round trip. expected:\n%s\ngot:\n%s", data, output)
+	}
+}
+
+func TestValidateTimeout(t *testing.T) {
+	rs := Must(`{ "items": { "type": "integer" } }`)
+	data := []byte(`[1, 2, 3, "four"]`)
+
+	errs, err := rs.ValidateBytesWithOptions(data, ValidateOptions{Deadline: time.Now().Add(-time.Second)})
+	if err != ErrTimeout {
+		t.Errorf("expected ErrTimeout for a passed deadline, got: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected validation to stop before any errors, got: %v", errs)
+	}
+
+	if _, _, err := rs.ValidateAndNormalizeWithOptions(data, ValidateOptions{Deadline: time.Now().Add(-time.Second)}); err != ErrTimeout {
+		t.Errorf("expected ErrTimeout normalizing with a passed deadline, got: %v", err)
+	}
+
+	errs, err = rs.ValidateBytesWithOptions(data, ValidateOptions{Timeout: time.Minute})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected 1 error within the timeout, got: %v", errs)
+	}
+}
+
+func TestVocabularyCoding(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/coding/vocabulary.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs := &RootSchema{}
+	if err := DefaultDecoder.Unmarshal(data, rs); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]bool{
+		"https://json-schema.org/draft/2020-12/vocab/core":       true,
+		"https://json-schema.org/draft/2020-12/vocab/applicator": true,
+		"https://json-schema.org/draft/2020-12/vocab/validation": true,
+		"https://example.com/vocab/units":                        false,
+	}
+	if !reflect.DeepEqual(rs.Vocabulary, expect) {
+		t.Errorf("vocabulary mismatch. expected: %v, got: %v", expect, rs.Vocabulary)
+	}
+	if _, ok := rs.JSONProp("$vocabulary").(map[string]bool); !ok {
+		t.Errorf("expected $vocabulary to be a property of the schema")
+	}
+
+	output, err := DefaultEncoder.Marshal(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expectDoc, gotDoc interface{}
+	if err := json.Unmarshal(data, &expectDoc); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(output, &gotDoc); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectDoc, gotDoc) {
+		t.Errorf("vocabulary didn't round trip. expected:\n%s\ngot:\n%s", data, output)
+	}
+}
+
+// decodeRecorder is a jsoniter.Any that records the paths of the values
+// decoded from it
+type decodeRecorder struct {
+	jsoniter.Any
+	path    string
+	decoded map[string]bool
+}
+
+func (r decodeRecorder) Get(path ...interface{}) jsoniter.Any {
+	p := r.path
+	for _, key := range path {
+		p += fmt.Sprintf("/%v", key)
+	}
+	return decodeRecorder{r.Any.Get(path...), p, r.decoded}
+}
+
+func (r decodeRecorder) GetInterface() interface{} {
+	r.decoded[r.path] = true
+	return r.Any.GetInterface()
+}
+
+func TestValidateAny(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"name": { "type": "string", "minLength": 2 },
+			"tags": { "type": "array", "items": { "type": "string" }, "uniqueItems": true },
+			"size": { "type": "integer", "maximum": 10 }
+		},
+		"required": ["name"]
+	}`)
+	docs := []string{
+		`{"name": "ok", "tags": ["a", "b"], "size": 3}`,
+		`{"name": "x", "tags": ["a", "a", 1], "size": 10.5}`,
+		`{"tags": []}`,
+		`[1, 2]`,
+		`null`,
+	}
+	for i, doc := range docs {
+		expect, err := rs.ValidateBytes([]byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := rs.ValidateAny(jsoniter.Get([]byte(doc)))
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %s", i, err)
+			continue
+		}
+		if errorLines(got) != errorLines(expect) {
+			t.Errorf("case %d: expected errors:\n%s\ngot:\n%s", i, errorLines(expect), errorLines(got))
+		}
+	}
+
+	// an Any for part of a document validates just that part
+	got, err := rs.ValidateAny(jsoniter.Get([]byte(`{"outer": {"name": 5}}`), "outer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errorLines(got) != "/name: type should be string" {
+		t.Errorf("unexpected errors for a nested Any: %v", got)
+	}
+
+	// "items" doesn't apply to objects
+	items := Must(`{"items": {"type": "string"}}`)
+	if got, err := items.ValidateAny(jsoniter.Get([]byte(`{"a": 1, "b": 2}`))); err != nil || len(got) != 0 {
+		t.Errorf("expected an object to pass an items schema, got: %v %v", got, err)
+	}
+
+	if _, err := rs.ValidateAny(jsoniter.Get([]byte(`{"a": 1}`), "missing")); err == nil {
+		t.Errorf("expected an error validating a missing value")
+	}
+
+	// properties the schema doesn't describe aren't decoded
+	lazy := Must(`{
+		"type": "object",
+		"properties": {
+			"items": { "type": "array", "items": { "$ref": "#/definitions/item" } }
+		},
+		"required": ["items"],
+		"definitions": {
+			"item": { "properties": { "id": { "type": "integer" } } }
+		}
+	}`)
+	decoded := map[string]bool{}
+	doc := []byte(`{"items": [{"id": 1, "blob": {"a": 1}}, {"id": "2"}], "extra": [1, 2]}`)
+	got, err = lazy.ValidateAny(decodeRecorder{jsoniter.Get(doc), "", decoded})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errorLines(got) != "/items/1/id: type should be integer" {
+		t.Errorf("unexpected errors validating lazily: %v", got)
+	} else if got[0].Keyword != "type" || got[0].SchemaPath != "#/properties/items/items/$ref/properties/id/type" {
+		t.Errorf("unexpected error location: %s %s", got[0].Keyword, got[0].SchemaPath)
+	}
+	if expect := map[string]bool{"/items/0/id": true, "/items/1/id": true}; !reflect.DeepEqual(decoded, expect) {
+		t.Errorf("expected only the ids to be decoded, got: %v", decoded)
+	}
+
+	// a failing "required" decodes the object to report it
+	decoded = map[string]bool{}
+	got, _ = lazy.ValidateAny(decodeRecorder{jsoniter.Get([]byte(`{"extra": 1}`)), "", decoded})
+	if len(got) != 1 || got[0].Error() != `/: {"extra":1} "items" value is required` || !decoded[""] {
+		t.Errorf("unexpected errors for a missing property: %v", got)
+	}
+}
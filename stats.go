@@ -0,0 +1,181 @@
+package jsonschema
+
+import (
+	"github.com/json-iterator/go"
+)
+
+// Stats summarizes the size and shape of a schema
+type Stats struct {
+	// Nodes is the number of schemas in the document, including the root
+	Nodes int
+	// MaxDepth is the deepest nesting of subschemas, the root is at depth 0
+	MaxDepth int
+	// Refs is the number of schemas that use "$ref"
+	Refs int
+	// Keywords counts the number of schemas using each keyword
+	Keywords map[string]int
+}
+
+// Stats walks the schema, counting its subschemas, references, and
+// keyword usage. References aren't followed, so each schema in the document
+// is counted once
+func (rs *RootSchema) Stats() Stats {
+	st := Stats{Keywords: map[string]int{}}
+	st.walk(&rs.Schema, 0)
+	return st
+}
+
+// walk counts elem and its children, depth is the nesting depth of elem
+// if it's a schema
+func (st *Stats) walk(elem JSONPather, depth int) {
+	childDepth := depth
+	if sch := nodeSchema(elem); sch != nil {
+		st.Nodes++
+		if depth > st.MaxDepth {
+			st.MaxDepth = depth
+		}
+		if sch.Ref != "" {
+			st.Refs++
+		}
+		for _, kw := range schemaKeywords(sch) {
+			st.Keywords[kw]++
+		}
+		childDepth = depth + 1
+	}
+
+	if con, ok := elem.(JSONContainer); ok {
+		for key, ch := range con.JSONChildren() {
+			if key == "$ref" && nodeSchema(elem) != nil {
+				// keywords holding a referencing schema list the schema
+				// itself as a child
+				continue
+			}
+			st.walk(ch, childDepth)
+		}
+	}
+}
+
+// schemaKeywords lists the keywords a schema declares
+func schemaKeywords(sch *Schema) (kws []string) {
+	if sch.schemaType != schemaTypeObject {
+		return nil
+	}
+	fields := []struct {
+		kw  string
+		set bool
+	}{
+		{"$id", sch.ID != ""},
+		{"$anchor", sch.Anchor != ""},
+		{"title", sch.Title != ""},
+		{"description", sch.Description != ""},
+		{"default", sch.Default != nil},
+		{"examples", sch.Examples != nil},
+		{"example", sch.Example != nil},
+		{"readOnly", sch.ReadOnly != nil},
+		{"writeOnly", sch.WriteOnly != nil},
+		{"$comment", sch.Comment != ""},
+		{"$ref", sch.Ref != ""},
+		{"definitions", sch.Definitions != nil},
+		{"$defs", sch.Defs != nil},
+		{"format", sch.Format != ""},
+	}
+	for _, f := range fields {
+		if f.set {
+			kws = append(kws, f.kw)
+		}
+	}
+	for kw := range sch.Validators {
+		kws = append(kws, kw)
+	}
+	return kws
+}
+
+// subschemaKeywords are the standard keywords that hold subschemas, mapped
+// to whether they hold them by name, eg: "properties". Others hold one
+// schema or an array of them, eg: "not" or "allOf"
+var subschemaKeywords = map[string]bool{
+	"allOf": false, "anyOf": false, "oneOf": false, "not": false,
+	"items": false, "additionalItems": false, "contains": false,
+	"additionalProperties": false, "propertyNames": false,
+	"if": false, "then": false, "else": false, "contentSchema": false,
+	"properties": true, "patternProperties": true, "definitions": true, "$defs": true,
+}
+
+// rawStats counts the schemas of a JSON schema document and how deeply
+// they nest the way Stats does, reading the raw JSON instead of a parsed
+// schema. It's cheap enough to check the limits of ParseOptions before
+// parsing. ok is false if the data isn't valid JSON
+func rawStats(data []byte) (st Stats, ok bool) {
+	iter := jsoniter.ParseBytes(jsoniter.ConfigDefault, data)
+	st.rawSchema(iter, 0)
+	return st, iter.Error == nil
+}
+
+// rawSchema counts the schema the iterator is at, depth is its nesting
+// depth. Arrays are read as lists of schemas, values that can't be schemas
+// are skipped
+func (st *Stats) rawSchema(iter *jsoniter.Iterator, depth int) {
+	switch iter.WhatIsNext() {
+	case jsoniter.BoolValue:
+		st.rawNode(depth)
+		if !iter.ReadBool() {
+			// false is parsed as { "not": {} }
+			st.rawNode(depth + 1)
+		}
+	case jsoniter.ArrayValue:
+		iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+			st.rawSchema(iter, depth)
+			return true
+		})
+	case jsoniter.ObjectValue:
+		st.rawNode(depth)
+		iter.ReadObjectCB(func(iter *jsoniter.Iterator, key string) bool {
+			byName, ok := subschemaKeywords[key]
+			switch {
+			case ok && byName:
+				iter.ReadObjectCB(func(iter *jsoniter.Iterator, _ string) bool {
+					st.rawSchema(iter, depth+1)
+					return true
+				})
+			case ok:
+				st.rawSchema(iter, depth+1)
+			case rawKeyword(key):
+				iter.Skip()
+			default:
+				// unknown keywords are parsed as schemas
+				if next := iter.WhatIsNext(); next == jsoniter.ObjectValue || next == jsoniter.BoolValue {
+					st.rawSchema(iter, depth+1)
+				} else {
+					iter.Skip()
+				}
+			}
+			return true
+		})
+	default:
+		iter.Skip()
+	}
+}
+
+// rawNode counts a schema at depth
+func (st *Stats) rawNode(depth int) {
+	st.Nodes++
+	if depth > st.MaxDepth {
+		st.MaxDepth = depth
+	}
+}
+
+// rawKeyword reports whether key is a keyword other than those holding
+// subschemas that schemas are parsed with
+func rawKeyword(key string) bool {
+	if _, ok := keywordFactories[key]; ok {
+		return true
+	}
+	if _, ok := DefaultValidators[key]; ok {
+		return true
+	}
+	switch key {
+	case "$schema", "$id", "$anchor", "title", "description", "default", "examples", "example", "readOnly", "writeOnly", "$comment", "$vocabulary", "$ref", "format":
+		return true
+	}
+	return false
+}
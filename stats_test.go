@@ -0,0 +1,54 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	got := Must(codegenPersonSchema).Stats()
+	expect := Stats{
+		Nodes:    14,
+		MaxDepth: 2,
+		Refs:     2,
+		Keywords: map[string]int{
+			"$ref":                 2,
+			"additionalProperties": 1,
+			"definitions":          1,
+			"description":          2,
+			"enum":                 1,
+			"items":                1,
+			"minimum":              1,
+			"properties":           2,
+			"required":             2,
+			"title":                2,
+			"type":                 11,
+		},
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("expected: %#v, got: %#v", expect, got)
+	}
+
+	got = Must(`{ "not": { "$ref": "#/definitions/a" }, "definitions": { "a": true }, "allOf": [{ "items": { "items": {} } }] }`).Stats()
+	if got.Nodes != 6 || got.MaxDepth != 3 || got.Refs != 1 {
+		t.Errorf("expected 6 nodes, depth 3 and 1 ref, got: %#v", got)
+	}
+}
+
+func TestRawStats(t *testing.T) {
+	docs := []string{
+		codegenPersonSchema,
+		`{ "not": { "$ref": "#/definitions/a" }, "definitions": { "a": true }, "allOf": [{ "items": { "items": {} } }] }`,
+		`{ "properties": { "a": false }, "items": [{}, { "contains": { "x-extra": { "enum": [{ "type": "string" }] } } }] }`,
+	}
+	for _, doc := range docs {
+		expect := Must(doc).Stats()
+		got, ok := rawStats([]byte(doc))
+		if !ok || got.Nodes != expect.Nodes || got.MaxDepth != expect.MaxDepth {
+			t.Errorf("%s: expected %d nodes at depth %d, got: %d at depth %d", doc, expect.Nodes, expect.MaxDepth, got.Nodes, got.MaxDepth)
+		}
+	}
+	if _, ok := rawStats([]byte(`{ "not": `)); ok {
+		t.Errorf("expected invalid JSON to be reported")
+	}
+}
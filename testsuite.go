@@ -0,0 +1,240 @@
+package jsonschema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/json-iterator/go"
+)
+
+// TestSet is a json-based set of tests
+// JSON-Schema comes with a lovely JSON-based test suite:
+// https://github.com/json-schema-org/JSON-Schema-Test-Suite
+type TestSet struct {
+	Description string      `json:"description"`
+	Schema      *RootSchema `json:"schema"`
+	Tests       []TestCase  `json:"tests"`
+}
+
+// TestCase is a single instance of a TestSet, and whether it's valid
+// against the set's schema
+type TestCase struct {
+	Description string      `json:"description"`
+	Data        interface{} `json:"data"`
+	Valid       bool        `json:"valid"`
+}
+
+// TestFailure describes a test suite case that didn't give the expected
+// result, or a test file or set that couldn't be run
+type TestFailure struct {
+	// File is the base name of the test file
+	File string
+	// Set is the description of the test set
+	Set string
+	// Index & Case are the position and description of the failing case
+	// within the set, both are empty when Err is set
+	Index int
+	Case  string
+	// Valid is the expected result of the case
+	Valid bool
+	// Errors are the validation errors the case produced
+	Errors []ValError
+	// Err is set when the file couldn't be read or decoded, or the set's
+	// remote references couldn't be fetched
+	Err error
+}
+
+// Error implements the error interface for TestFailure
+func (f TestFailure) Error() string {
+	if f.Err != nil {
+		if f.Set == "" {
+			return fmt.Sprintf("%s: %s", f.File, f.Err.Error())
+		}
+		return fmt.Sprintf("%s: %s %s", f.File, f.Set, f.Err.Error())
+	}
+	return fmt.Sprintf("%s: %s test case %d: %s. error: %s", f.File, f.Set, f.Index, f.Case, f.Errors)
+}
+
+// RunTestSuite runs files in the format of the JSON-Schema-Test-Suite,
+// resolving remote references against pool, which defaults to
+// DefaultSchemaPool. References pool doesn't have are fetched and added to
+// it. Schemas in files of a "draft4" directory that don't declare a
+// "$schema" are read as draft-04 schemas, and the cases of those files are
+// decoded with json.Numbers, as draft-04 tells integers apart by how
+// they're written. It gives the number of cases that passed, the number of
+// cases, and a failure for each case that didn't pass, or file or set that
+// couldn't be run. The cases of a set that couldn't be run count as failed,
+// as does a file that couldn't be read or decoded, as a single case
+func RunTestSuite(pool Definitions, files []string) (passed, total int, failures []TestFailure) {
+	if pool == nil {
+		pool = DefaultSchemaPool
+	}
+	for _, path := range files {
+		base := filepath.Base(path)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			total++
+			failures = append(failures, TestFailure{File: base, Err: fmt.Errorf("error loading test file: %s", err.Error())})
+			continue
+		}
+
+		draft4 := suiteDraft(path) == "draft4"
+		decoder := DefaultDecoder
+		if draft4 {
+			decoder = suiteNumberDecoder
+		}
+		sets := []json.RawMessage{}
+		if err := decoder.Unmarshal(data, &sets); err != nil {
+			total++
+			failures = append(failures, TestFailure{File: base, Err: fmt.Errorf("error unmarshaling test set from JSON: %s", err.Error())})
+			continue
+		}
+
+		for _, set := range sets {
+			ts := &TestSet{}
+			if err := decoder.Unmarshal(set, ts); err != nil {
+				cases := struct {
+					Description string            `json:"description"`
+					Tests       []json.RawMessage `json:"tests"`
+				}{}
+				decoder.Unmarshal(set, &cases)
+				total += unrunCases(len(cases.Tests))
+				failures = append(failures, TestFailure{File: base, Set: cases.Description, Err: fmt.Errorf("error unmarshaling test set from JSON: %s", err.Error())})
+				continue
+			}
+			if draft4 && ts.Schema.SchemaURI == "" {
+				useDraft4(&ts.Schema.Schema)
+			}
+			if err := ts.Schema.fetchRemoteReferences(context.Background(), pool); err != nil {
+				total += unrunCases(len(ts.Tests))
+				failures = append(failures, TestFailure{File: base, Set: ts.Description, Err: fmt.Errorf("error fetching remote references: %s", err.Error())})
+				continue
+			}
+			for i, c := range ts.Tests {
+				total++
+				got := []ValError{}
+				ts.Schema.Validate("/", c.Data, &got)
+				if valid := len(got) == 0; valid != c.Valid {
+					failures = append(failures, TestFailure{File: base, Set: ts.Description, Index: i, Case: c.Description, Valid: c.Valid, Errors: got})
+					continue
+				}
+				passed++
+			}
+		}
+	}
+	return passed, total, failures
+}
+
+// suiteNumberDecoder decodes test files keeping the numbers of cases as
+// json.Numbers
+var suiteNumberDecoder Decoder = jsoniter.Config{EscapeHTML: true, UseNumber: true}.Froze()
+
+// suiteDraft gives the name of the draft directory a test file is in, eg:
+// "draft7", or "" if it isn't in one
+func suiteDraft(path string) string {
+	dir := filepath.Dir(path)
+	if filepath.Base(dir) == "optional" {
+		dir = filepath.Dir(dir)
+	}
+	if name := filepath.Base(dir); strings.HasPrefix(name, "draft") {
+		return name
+	}
+	return ""
+}
+
+// unrunCases gives the number of cases a set of n cases that couldn't be
+// run counts as, at least one so the failure shows in the pass rate
+func unrunCases(n int) int {
+	if n == 0 {
+		return 1
+	}
+	return n
+}
+
+// Conformance is the result of running the test suite of a draft
+type Conformance struct {
+	Passed, Total int
+	// Failures lists each failing case, and each test file or set that
+	// couldn't be run, such as sets using keywords this package doesn't
+	// support
+	Failures []TestFailure
+}
+
+// Rate gives the fraction of cases that passed. Cases that couldn't be run
+// count as failed, as RunTestSuite counts them
+func (c *Conformance) Rate() float64 {
+	if c.Total == 0 {
+		return 0
+	}
+	return float64(c.Passed) / float64(c.Total)
+}
+
+// ConformanceReport runs every test file of a JSON-Schema-Test-Suite
+// checkout at dir, where each "draft" directory holds the tests of a
+// draft, with optional tests in it's "optional" subdirectory. The suites
+// aren't built into the package, so it takes the directory to run, eg: the
+// testdata directory of this repository. Schemas in
+// dir itself, such as meta-schemas, are added to the pool of schemas
+// references are resolved against by their "$id". It gives the conformance
+// of each draft by directory name, eg: "draft7"
+func ConformanceReport(dir string) (map[string]*Conformance, error) {
+	metaSchemas, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	meta := Definitions{}
+	for _, path := range metaSchemas {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		rs := &RootSchema{}
+		if err := DefaultDecoder.Unmarshal(data, rs); err != nil {
+			return nil, fmt.Errorf("error unmarshaling schema %s: %s", filepath.Base(path), err.Error())
+		}
+		if rs.ID != "" {
+			meta[rs.ID] = &rs.Schema
+		}
+	}
+
+	drafts, err := filepath.Glob(filepath.Join(dir, "draft*"))
+	if err != nil {
+		return nil, err
+	}
+	report := map[string]*Conformance{}
+	for _, draftDir := range drafts {
+		if info, err := os.Stat(draftDir); err != nil || !info.IsDir() {
+			continue
+		}
+		files := []string{}
+		if err := filepath.Walk(draftDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && strings.HasSuffix(path, ".json") {
+				files = append(files, path)
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+
+		// each draft gets its own pool, so remote documents fetched for one
+		// draft don't resolve references of another
+		pool := Definitions{}
+		for id, sch := range meta {
+			pool[id] = sch
+		}
+		c := &Conformance{}
+		c.Passed, c.Total, c.Failures = RunTestSuite(pool, files)
+		report[filepath.Base(draftDir)] = c
+	}
+	return report, nil
+}
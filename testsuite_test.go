@@ -0,0 +1,89 @@
+package jsonschema
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunTestSuite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonschema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	suite := `[
+		{
+			"description": "remote integer",
+			"schema": { "$ref": "http://example.com/integer.json" },
+			"tests": [
+				{ "description": "an integer", "data": 1, "valid": true },
+				{ "description": "a string", "data": "a", "valid": false },
+				{ "description": "wrong expectation", "data": 1.5, "valid": true }
+			]
+		},
+		{
+			"description": "unparsable schema",
+			"schema": { "minLength": "two" },
+			"tests": [
+				{ "description": "short", "data": "a", "valid": false },
+				{ "description": "long", "data": "abc", "valid": true }
+			]
+		}
+	]`
+	path := filepath.Join(dir, "suite.json")
+	if err := ioutil.WriteFile(path, []byte(suite), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pool := Definitions{"http://example.com/integer.json": &Must(`{ "type": "integer" }`).Schema}
+	passed, total, failures := RunTestSuite(pool, []string{path, filepath.Join(dir, "missing.json")})
+	// the cases of the unparsable set, and the missing file, count as failed
+	if passed != 2 || total != 6 {
+		t.Errorf("expected 2/6 tests to pass, got: %d/%d", passed, total)
+	}
+	if len(failures) != 3 {
+		t.Fatalf("expected 3 failures, got: %v", failures)
+	}
+
+	f := failures[0]
+	if f.File != "suite.json" || f.Set != "remote integer" || f.Index != 2 || f.Case != "wrong expectation" || !f.Valid || len(f.Errors) != 1 || f.Err != nil {
+		t.Errorf("unexpected case failure: %#v", f)
+	}
+	if f := failures[1]; f.File != "suite.json" || f.Set != "unparsable schema" || f.Err == nil {
+		t.Errorf("expected a failure decoding the unparsable set, got: %#v", f)
+	}
+	if f := failures[2]; f.File != "missing.json" || f.Err == nil || !strings.Contains(f.Error(), "error loading test file") {
+		t.Errorf("expected a failure loading missing.json, got: %#v", f)
+	}
+}
+
+func TestConformanceReport(t *testing.T) {
+	report, err := ConformanceReport("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, draft := range []string{"draft3", "draft4", "draft6", "draft7"} {
+		c, ok := report[draft]
+		if !ok {
+			t.Errorf("expected a report for %s", draft)
+			continue
+		}
+		if c.Total == 0 || c.Passed > c.Total {
+			t.Errorf("%s: unexpected result: %d/%d", draft, c.Passed, c.Total)
+		}
+		t.Logf("%s: %d/%d passed (%.1f%%)", draft, c.Passed, c.Total, c.Rate()*100)
+	}
+
+	// draft3 "disallow" isn't implemented, so it's a known gap
+	found := false
+	for _, f := range report["draft3"].Failures {
+		found = found || f.File == "disallow.json"
+	}
+	if !found {
+		t.Errorf("expected draft3 failures to include disallow.json")
+	}
+}
@@ -1,5 +1,9 @@
 package jsonschema
 
+import (
+	"github.com/qri-io/jsonpointer"
+)
+
 // JSONPather makes validators traversible by JSON-pointers,
 // which is required to support references in JSON schemas.
 type JSONPather interface {
@@ -33,3 +37,26 @@ func walkJSON(elem JSONPather, fn func(elem JSONPather) error) error {
 
 	return nil
 }
+
+// walkJSONPath works like walkJSON, also passing fn the JSON pointer of
+// each element within the schema document
+func walkJSONPath(elem JSONPather, ptr jsonpointer.Pointer, fn func(ptr jsonpointer.Pointer, elem JSONPather) error) error {
+	if err := fn(ptr, elem); err != nil {
+		return err
+	}
+
+	// a single "items" schema is the items value itself, not an array of schemas
+	if it, ok := elem.(*Items); ok && it.single {
+		return walkJSONPath(it.Schemas[0], ptr, fn)
+	}
+
+	if con, ok := elem.(JSONContainer); ok {
+		for key, ch := range con.JSONChildren() {
+			if err := walkJSONPath(ch, append(ptr[:len(ptr):len(ptr)], key), fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -102,3 +102,37 @@ func TestReferenceTraversal(t *testing.T) {
 	}
 
 }
+
+func TestArrayIndexRefs(t *testing.T) {
+	cases := []struct {
+		schema, valid, invalid string
+	}{
+		{`{ "allOf": [{}, { "properties": { "x": { "type": "integer" } } }], "properties": { "y": { "$ref": "#/allOf/1/properties/x" } } }`, `{"y": 1}`, `{"y": "a"}`},
+		{`{ "items": [{}, { "type": "string" }], "properties": { "y": { "$ref": "#/items/1" } } }`, `{"y": "a"}`, `{"y": 1}`},
+		{`{ "definitions": { "u": { "anyOf": [{ "type": "boolean" }] } }, "properties": { "y": { "$ref": "#/definitions/u/anyOf/0" } } }`, `{"y": true}`, `{"y": 1}`},
+		{`{ "definitions": { "u": { "oneOf": [{}, { "maximum": 3 }] } }, "properties": { "y": { "$ref": "#/definitions/u/oneOf/1" } } }`, `{"y": 2}`, `{"y": 4}`},
+	}
+
+	for i, c := range cases {
+		rs := &RootSchema{}
+		if err := rs.UnmarshalJSON([]byte(c.schema)); err != nil {
+			t.Errorf("case %d: unexpected unmarshal error: %s", i, err.Error())
+			continue
+		}
+		if errs, _ := rs.ValidateBytes([]byte(c.valid)); len(errs) != 0 {
+			t.Errorf("case %d: expected %s to be valid, got: %v", i, c.valid, errs)
+		}
+		if errs, _ := rs.ValidateBytes([]byte(c.invalid)); len(errs) != 1 {
+			t.Errorf("case %d: expected %s to be invalid, got: %v", i, c.invalid, errs)
+		}
+	}
+
+	for i, s := range []string{
+		`{ "items": [{}, {}], "properties": { "y": { "$ref": "#/items/2" } } }`,
+		`{ "allOf": [{}], "properties": { "y": { "$ref": "#/allOf/1" } } }`,
+	} {
+		if err := (&RootSchema{}).UnmarshalJSON([]byte(s)); err == nil {
+			t.Errorf("case %d: expected error for out of range reference", i)
+		}
+	}
+}
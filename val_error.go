@@ -2,7 +2,6 @@ package jsonschema
 
 import (
 	"bytes"
-	"github.com/json-iterator/go"
 	"fmt"
 )
 
@@ -16,15 +15,89 @@ type ValError struct {
 	InvalidValue interface{} `json:"invalidValue,omitempty"`
 	// RulePath is the path to the rule that errored
 	RulePath string `json:"rulePath,omitempty"`
+	// SchemaPath is the location of the keyword that produced the error
+	// within the schema as a URI fragment, following the path validation
+	// took, eg: "#/properties/friends/items/required". Locations within
+	// schemas reached by "$ref" continue from the reference, eg:
+	// "#/items/$ref/type"
+	SchemaPath string `json:"schemaPath,omitempty"`
 	// Message is a human-readable description of the error
 	Message string `json:"message"`
+	// Keyword is the schema keyword that produced the error
+	Keyword string `json:"keyword,omitempty"`
+	// Params holds keyword-specific details of the failure, eg: the
+	// "limit" a number exceeded
+	Params map[string]interface{} `json:"params,omitempty"`
+	// Severity indicates whether the error invalidates the instance
+	Severity Severity `json:"severity,omitempty"`
+
+	// valueLen overrides MaxValueErrStringLen when printing InvalidValue
+	valueLen int
+	// absSchemaPath is the absolute form of SchemaPath, see schemaLocation
+	absSchemaPath string
+}
+
+// Severity classifies a ValError
+type Severity int
+
+const (
+	// SeverityError marks a failed assertion, making the instance invalid
+	SeverityError Severity = iota
+	// SeverityWarning marks an advisory diagnostic that doesn't affect
+	// validity
+	SeverityWarning
+)
+
+// String implements the stringer interface for Severity
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// MarshalJSON implements the jsoniter.Marshaler interface for Severity
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return DefaultEncoder.Marshal(s.String())
+}
+
+// UnmarshalJSON implements the jsoniter.Unmarshaler interface for Severity
+func (s *Severity) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := DefaultDecoder.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	switch str {
+	case "error":
+		*s = SeverityError
+	case "warning":
+		*s = SeverityWarning
+	default:
+		return fmt.Errorf("invalid severity: %s", str)
+	}
+	return nil
+}
+
+// IsValid reports whether errs contains no errors of SeverityError.
+// Warnings are ignored
+func IsValid(errs []ValError) bool {
+	for _, e := range errs {
+		if e.Severity == SeverityError {
+			return false
+		}
+	}
+	return true
 }
 
 // Error implements the error interface for ValError
 func (v ValError) Error() string {
 	// [propPath]: [value] [message]
 	if v.PropertyPath != "" && v.InvalidValue != nil {
-		return fmt.Sprintf("%s: %s %s", v.PropertyPath, InvalidValueString(v.InvalidValue), v.Message)
+		maxLen := MaxValueErrStringLen
+		if v.valueLen != 0 {
+			maxLen = v.valueLen
+		}
+		return fmt.Sprintf("%s: %s %s", v.PropertyPath, truncatedValueString(v.InvalidValue, maxLen), v.Message)
 	} else if v.PropertyPath != "" {
 		return fmt.Sprintf("%s: %s", v.PropertyPath, v.Message)
 	}
@@ -33,13 +106,19 @@ func (v ValError) Error() string {
 
 // InvalidValueString returns the errored value as a string
 func InvalidValueString(data interface{}) string {
-	bt, err := jsoniter.Marshal(data)
+	return truncatedValueString(data, MaxValueErrStringLen)
+}
+
+// truncatedValueString returns data as a string, truncated past maxLen bytes.
+// a negative maxLen disables truncation
+func truncatedValueString(data interface{}, maxLen int) string {
+	bt, err := DefaultEncoder.Marshal(data)
 	if err != nil {
 		return ""
 	}
 	bt = bytes.Replace(bt, []byte{'\n', '\r'}, []byte{' '}, -1)
-	if MaxValueErrStringLen != -1 && len(bt) > MaxValueErrStringLen {
-		bt = append(bt[:MaxValueErrStringLen], []byte("...")...)
+	if maxLen >= 0 && len(bt) > maxLen {
+		bt = append(bt[:maxLen], []byte("...")...)
 	}
 	return string(bt)
 }
@@ -52,3 +131,55 @@ func AddError(errs *[]ValError, propPath string, data interface{}, msg string) {
 		Message:      msg,
 	})
 }
+
+// GroupOptions configures GroupByPathWithOptions and
+// MessagesByPathWithOptions
+type GroupOptions struct {
+	// EmptyRootKey keys errors about the document itself, which have the
+	// property path "/", by "" instead, the same key as an empty JSON
+	// pointer
+	EmptyRootKey bool
+}
+
+// GroupByPath indexes errors by their property path, keeping the order of
+// errors that share a path. Errors at the root are keyed by "/"
+func GroupByPath(errs []ValError) map[string][]ValError {
+	return GroupByPathWithOptions(errs, GroupOptions{})
+}
+
+// GroupByPathWithOptions works like GroupByPath, configured by opts
+func GroupByPathWithOptions(errs []ValError, opts GroupOptions) map[string][]ValError {
+	groups := map[string][]ValError{}
+	for _, e := range errs {
+		key := opts.pathKey(e.PropertyPath)
+		groups[key] = append(groups[key], e)
+	}
+	return groups
+}
+
+// MessagesByPath works like GroupByPath, giving only the message of each
+// error
+func MessagesByPath(errs []ValError) map[string][]string {
+	return MessagesByPathWithOptions(errs, GroupOptions{})
+}
+
+// MessagesByPathWithOptions works like MessagesByPath, configured by opts
+func MessagesByPathWithOptions(errs []ValError, opts GroupOptions) map[string][]string {
+	msgs := map[string][]string{}
+	for _, e := range errs {
+		key := opts.pathKey(e.PropertyPath)
+		msgs[key] = append(msgs[key], e.Message)
+	}
+	return msgs
+}
+
+// pathKey gives the key of a property path in grouped errors
+func (opts GroupOptions) pathKey(propPath string) string {
+	if propPath != "/" && propPath != "" {
+		return propPath
+	}
+	if opts.EmptyRootKey {
+		return ""
+	}
+	return "/"
+}
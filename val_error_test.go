@@ -32,3 +32,142 @@ func TestErrorMessage(t *testing.T) {
 		}
 	}
 }
+
+func TestErrorKeyword(t *testing.T) {
+	cases := []struct {
+		schema, doc, keyword string
+	}{
+		{`{ "const" : "a value" }`, `"a different value"`, "const"},
+		{`{ "properties" : { "a" : { "minimum" : 3 } } }`, `{ "a" : 1 }`, "minimum"},
+		{`{ "anyOf" : [ { "type" : "string" } ] }`, `1`, "anyOf"},
+	}
+
+	for i, c := range cases {
+		errs, err := Must(c.schema).ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err)
+			continue
+		}
+		if len(errs) != 1 {
+			t.Errorf("case %d didn't return exactly 1 validation error. got: %d", i, len(errs))
+			continue
+		}
+		if errs[0].Keyword != c.keyword {
+			t.Errorf("case %d keyword mismatch. expected '%s', got: '%s'", i, c.keyword, errs[0].Keyword)
+		}
+	}
+}
+
+func TestErrorSchemaPath(t *testing.T) {
+	cases := []struct {
+		schema, doc, schemaPath string
+	}{
+		{`{ "const" : "a value" }`, `"a different value"`, "#/const"},
+		{`{ "properties" : { "friends" : { "items" : { "required" : ["name"] } } } }`, `{ "friends" : [{}] }`, "#/properties/friends/items/required"},
+		{`{ "items" : [{}, { "maximum" : 3 }] }`, `[0, 4]`, "#/items/1/maximum"},
+		{`{ "allOf" : [{}, { "not" : {} }] }`, `1`, "#/allOf/1/not"},
+		{`{ "patternProperties" : { "^x/" : false } }`, `{ "x/a" : 1 }`, "#/patternProperties/^x~1/not"},
+		{`{ "if" : { "type" : "string" }, "else" : { "type" : "null" } }`, `1`, "#/else/type"},
+		{`{ "definitions" : { "a" : { "minLength" : 2 } }, "additionalProperties" : { "$ref" : "#/definitions/a" } }`, `{ "b" : "c" }`, "#/additionalProperties/$ref/minLength"},
+		{`{ "dependencies" : { "a" : ["b"] } }`, `{ "a" : 1 }`, "#/dependencies/a"},
+	}
+
+	for i, c := range cases {
+		errs, err := Must(c.schema).ValidateBytes([]byte(c.doc))
+		if err != nil {
+			t.Errorf("case %d error validating: %s", i, err)
+			continue
+		}
+		if len(errs) != 1 {
+			t.Errorf("case %d didn't return exactly 1 validation error. got: %v", i, errs)
+			continue
+		}
+		if errs[0].SchemaPath != c.schemaPath {
+			t.Errorf("case %d schema path mismatch. expected '%s', got: '%s'", i, c.schemaPath, errs[0].SchemaPath)
+		}
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	rs := Must(`{ "type" : "string", "format" : "email" }`)
+	opts := ValidateOptions{WarningKeywords: []string{"format"}}
+
+	errs, err := rs.ValidateBytesWithOptions([]byte(`"not an email"`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Severity != SeverityWarning {
+		t.Errorf("expected a single warning, got: %v", errs)
+	}
+	if !IsValid(errs) {
+		t.Errorf("expected warnings not to invalidate the instance")
+	}
+
+	errs, err = rs.ValidateBytesWithOptions([]byte(`5`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Severity != SeverityError {
+		t.Errorf("expected a single error, got: %v", errs)
+	}
+	if IsValid(errs) {
+		t.Errorf("expected errors to invalidate the instance")
+	}
+}
+
+func TestValueTruncateLen(t *testing.T) {
+	rs := Must(`{ "maxLength": 5 }`)
+	data := []byte(`"Prince Rogers Nelson"`)
+
+	cases := []struct {
+		truncateLen int
+		expect      string
+	}{
+		{0, `/: "Prince Rogers Nelso... max length of 5 characters exceeded: Prince Rogers Nelson`},
+		{3, `/: "Pr... max length of 5 characters exceeded: Prince Rogers Nelson`},
+		{-1, `/: "Prince Rogers Nelson" max length of 5 characters exceeded: Prince Rogers Nelson`},
+		{-7, `/: "Prince Rogers Nelson" max length of 5 characters exceeded: Prince Rogers Nelson`},
+	}
+
+	for i, c := range cases {
+		errs, err := rs.ValidateBytesWithOptions(data, ValidateOptions{ValueTruncateLen: c.truncateLen})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != 1 {
+			t.Errorf("case %d: expected 1 error, got: %v", i, errs)
+			continue
+		}
+		if got := errs[0].Error(); got != c.expect {
+			t.Errorf("case %d mismatch. expected: %s, got: %s", i, c.expect, got)
+		}
+	}
+}
+
+func TestGroupByPath(t *testing.T) {
+	errs := []ValError{
+		{PropertyPath: "/", Message: `"name" value is required`},
+		{PropertyPath: "/address/zip", Message: "max length of 5 characters exceeded"},
+		{PropertyPath: "/address/zip", Message: "type should be string"},
+		{PropertyPath: "/age", Message: "must be >= 0"},
+	}
+
+	groups := GroupByPath(errs)
+	if len(groups) != 3 || len(groups["/address/zip"]) != 2 || groups["/address/zip"][1].Message != "type should be string" {
+		t.Errorf("unexpected groups: %v", groups)
+	}
+
+	msgs := MessagesByPath(errs)
+	if len(msgs["/"]) != 1 || msgs["/"][0] != `"name" value is required` {
+		t.Errorf("expected root message keyed by /, got: %v", msgs)
+	}
+
+	opts := GroupOptions{EmptyRootKey: true}
+	msgs = MessagesByPathWithOptions(errs, opts)
+	if len(msgs[""]) != 1 || len(msgs["/"]) != 0 {
+		t.Errorf("expected root message keyed by empty string, got: %v", msgs)
+	}
+	if groups := GroupByPathWithOptions(errs, opts); len(groups[""]) != 1 {
+		t.Errorf("expected root error keyed by empty string, got: %v", groups)
+	}
+}
@@ -1,10 +1,125 @@
 package jsonschema
 
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
 // MaxValueErrStringLen sets how long a value can be before it's length is truncated
 // when printing error strings
-// a special value of -1 disables output trimming
+// negative values disable output trimming
 var MaxValueErrStringLen = 20
 
+// ErrTimeout is returned when validation exceeds the Timeout or Deadline
+// of its ValidateOptions
+var ErrTimeout = errors.New("validation exceeded its time limit")
+
+// ValidateOptions configures a single validation pass
+type ValidateOptions struct {
+	// WarningKeywords lists keywords whose failures are reported with
+	// SeverityWarning instead of SeverityError, eg: "format"
+	WarningKeywords []string
+	// Coerce accepts strings in place of integer, number, and boolean
+	// values when the string cleanly parses as the declared type, as is
+	// common for form-encoded and query-string data. eg: "42" is accepted
+	// as an integer, but "4.5" is not. Where "string" is allowed alongside
+	// a numeric type, numeric strings are validated as numbers
+	Coerce bool
+	// AssertContent validates string content against the "contentEncoding",
+	// "contentMediaType", and "contentSchema" keywords, which are
+	// otherwise annotations
+	AssertContent bool
+	// RequiredChildPath reports a missing required property at the path the
+	// property should have, eg: "/address/zip", with no invalid value.
+	// By default it's reported at the path of the object missing it, eg:
+	// "/address"
+	RequiredChildPath bool
+	// Suggestions adds the closest allowed value to "enum" errors for
+	// strings, eg: `did you mean "active"?`
+	Suggestions bool
+	// WarnDeprecated reports instances of schemas marked "deprecated" with
+	// SeverityWarning errors, eg: "/oldField: property is deprecated".
+	// Otherwise "deprecated" is an annotation
+	WarnDeprecated bool
+	// BestMatchErrors reports the errors of the closest branch when
+	// "anyOf" fails, instead of a single error for the "anyOf" as a whole.
+	// The closest branch is the one with the fewest errors, preferring
+	// branches that accept the instance's type
+	BestMatchErrors bool
+	// StringLengthUTF16 measures strings for "minLength" and "maxLength" in
+	// UTF-16 code units, like JavaScript's String.length, instead of code
+	// points. eg: "😀" has a length of 2
+	StringLengthUTF16 bool
+	// PreTransform is called with the path and value of every string,
+	// number, boolean, and null in a document before it's validated, and
+	// the value it returns is validated in its place, eg: to trim
+	// whitespace. Transforms run before Coerce. It can't change the
+	// structure of the document, objects and arrays aren't passed to it
+	PreTransform func(path string, value interface{}) interface{}
+	// Partial skips the "required" and "minProperties" checks of objects at
+	// every level, validating only the properties present. This is the
+	// semantics of a partial update, eg: an HTTP PATCH request body
+	Partial bool
+	// Evaluated, when set, records the object properties and array items
+	// validation evaluated, see Evaluated. It's cleared when validation
+	// starts
+	Evaluated *Evaluated
+	// LazyRemoteRefs resolves references to other documents that weren't
+	// resolved when parsing the first time validation reaches them,
+	// instead of calling FetchRemoteReferences up front. Documents are
+	// loaded with RefLoader and cached on the root schema for later
+	// validations with the same loader
+	LazyRemoteRefs bool
+	// RefLoader loads the documents of LazyRemoteRefs, defaults to an
+	// HTTPLoader
+	RefLoader Loader
+	// ValueTruncateLen sets how long a value can be before it's truncated
+	// in the Error strings of the resulting errors, overriding
+	// MaxValueErrStringLen. zero keeps MaxValueErrStringLen, negative
+	// values disable output trimming
+	ValueTruncateLen int
+	// ProgressFunc is called periodically while validating a top-level
+	// array with how many of its elements validation has got through and
+	// the length of the array. Keywords that validate elements one at a
+	// time, like "items", "contains", and "uniqueItems", move it along,
+	// including under "allOf", "anyOf", and "oneOf", and each call gives a
+	// higher count than the last. it's called once more with the length of
+	// the array when validation is done
+	ProgressFunc func(done, total int)
+	// ProgressInterval is the number of elements validated between calls to
+	// ProgressFunc. defaults to 1000
+	ProgressInterval int
+	// ContradictoryAllOf reports an "allOf" whose branches require types
+	// no instance can have at once, eg: "object" and "string", with a single
+	// "contradictory allOf" error instead of the errors of each branch
+	ContradictoryAllOf bool
+	// CaseInsensitiveProperties matches the properties of objects to the
+	// names of "properties", "required", and "additionalProperties"
+	// regardless of case, eg: "FirstName" satisfies a required
+	// "firstName". A key that matches a name exactly always wins, otherwise
+	// the first of the keys that match in sorted order does, eg: "FIRSTNAME"
+	// before "FirstName". Keys that lose are additional properties. Names
+	// that only differ in case can match the same key
+	CaseInsensitiveProperties bool
+	// RejectDuplicateKeys reports objects of the document that repeat a
+	// key, eg: {"a":1,"a":2}, with an error at the object's path. Decoding
+	// keeps the last value, so duplicates otherwise go unnoticed. It only
+	// applies when validating json byte data
+	RejectDuplicateKeys bool
+	// Timeout limits how long validation may take, guarding against
+	// schemas and documents that make validation run away. When it's
+	// exceeded validation stops, returning ErrTimeout along with the errors
+	// found so far. 0 means no limit
+	Timeout time.Duration
+	// Deadline stops validation at a point in time the same way Timeout
+	// does, the zero time means no deadline. When both are set the earlier
+	// one applies
+	Deadline time.Time
+}
+
 // Validator is an interface for anything that can validate.
 // JSON-Schema keywords are all examples of validators
 type Validator interface {
@@ -14,6 +129,237 @@ type Validator interface {
 	Validate(propPath string, data interface{}, errs *[]ValError)
 }
 
+// ValidationContext carries the state of a single validation pass down
+// through nested validators
+type ValidationContext struct {
+	// Options configures the validation pass, never nil
+	Options *ValidateOptions
+	// Root is the document being validated. It's nil when a keyword is
+	// validated on its own
+	Root interface{}
+	// Parent is the object or array holding the value being validated, or
+	// nil when validating the document itself. Keywords like
+	// "matchesField" use it to compare the value with its siblings
+	Parent interface{}
+	// ParentPath is the property path of Parent
+	ParentPath string
+
+	// instance is set when validating a PreparedInstance
+	instance *PreparedInstance
+	// location is the location within the schema of the keyword being
+	// validated
+	location schemaLocation
+	// deadline is when validation times out, the zero time if it doesn't.
+	// steps counts the schemas validated since the clock was last checked
+	deadline time.Time
+	steps    int
+	timedOut bool
+	// lazyRefs caches the references resolved with LazyRemoteRefs
+	lazyRefs *lazyRefCache
+	// progress is the count last passed to ProgressFunc
+	progress int
+}
+
+// newValidationContext creates a context for a validation pass
+func newValidationContext(opts *ValidateOptions) *ValidationContext {
+	if opts == nil {
+		opts = &ValidateOptions{}
+	}
+	if opts.Evaluated != nil {
+		opts.Evaluated.entries = nil
+	}
+	vc := &ValidationContext{Options: opts, deadline: opts.Deadline}
+	if opts.Timeout > 0 {
+		if d := time.Now().Add(opts.Timeout); vc.deadline.IsZero() || d.Before(vc.deadline) {
+			vc.deadline = d
+		}
+	}
+	return vc
+}
+
+// expired reports whether validation has passed its deadline. The clock
+// is only checked every 64 calls, as it's called for every schema
+// validated
+func (vc *ValidationContext) expired() bool {
+	if vc.timedOut {
+		return true
+	}
+	if vc.deadline.IsZero() {
+		return false
+	}
+	if vc.steps++; vc.steps%64 != 1 {
+		return false
+	}
+	vc.timedOut = time.Now().After(vc.deadline)
+	return vc.timedOut
+}
+
+// err gives the error of a finished validation pass, if any
+func (vc *ValidationContext) err() error {
+	if vc.timedOut {
+		return ErrTimeout
+	}
+	return nil
+}
+
+// enter sets the container of the values validated next, returning the
+// previous container so leave can restore it afterward
+func (vc *ValidationContext) enter(parent interface{}, parentPath string) (prev interface{}, prevPath string) {
+	prev, prevPath = vc.Parent, vc.ParentPath
+	vc.Parent, vc.ParentPath = parent, parentPath
+	return prev, prevPath
+}
+
+// leave restores the container replaced by enter
+func (vc *ValidationContext) leave(parent interface{}, parentPath string) {
+	vc.Parent, vc.ParentPath = parent, parentPath
+}
+
+// schemaLocation is a location within a schema as a JSON pointer, and it's
+// absolute form as a URI when validation has passed a schema with an
+// absolute "$id", or followed a reference to one
+type schemaLocation struct {
+	path, abs string
+}
+
+// pointerTokenEscaper escapes the tokens of JSON pointers
+var pointerTokenEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// enterSchema appends tokens to the schema location of the keyword being
+// validated, returning the previous location so leaveSchema can restore it
+func (vc *ValidationContext) enterSchema(tokens ...string) schemaLocation {
+	prev := vc.location
+	for _, tok := range tokens {
+		tok = "/" + pointerTokenEscaper.Replace(tok)
+		vc.location.path += tok
+		if vc.location.abs != "" {
+			vc.location.abs += tok
+		}
+	}
+	return prev
+}
+
+// enterSibling replaces the last token of the schema location, for
+// keywords like "if" that validate the subschemas of their siblings
+func (vc *ValidationContext) enterSibling(keyword string) schemaLocation {
+	prev := vc.location
+	if i := strings.LastIndex(vc.location.path, "/"); i >= 0 {
+		vc.location.path = vc.location.path[:i]
+	}
+	if i := strings.LastIndex(vc.location.abs, "/"); i >= 0 {
+		vc.location.abs = vc.location.abs[:i]
+	}
+	vc.enterSchema(keyword)
+	return prev
+}
+
+// enterRef enters the "$ref" keyword of a schema, changing the absolute
+// location to the schema the reference points to
+func (vc *ValidationContext) enterRef(ref string) schemaLocation {
+	base := vc.location.abs
+	prev := vc.enterSchema("$ref")
+	vc.location.abs = resolveLocation(base, ref)
+	return prev
+}
+
+// enterID sets the absolute location to the start of a schema with an
+// "$id"
+func (vc *ValidationContext) enterID(id string) schemaLocation {
+	prev := vc.location
+	if abs := resolveLocation(vc.location.abs, id); abs != "" {
+		vc.location.abs = abs
+	}
+	return prev
+}
+
+// leaveSchema restores the schema location replaced by enterSchema,
+// enterSibling, enterRef, or enterID
+func (vc *ValidationContext) leaveSchema(prev schemaLocation) {
+	vc.location = prev
+}
+
+// locateErrors sets the schema location of errors from start on that
+// don't have one yet
+func (vc *ValidationContext) locateErrors(errs []ValError, start int) {
+	for i := start; i < len(errs); i++ {
+		if errs[i].SchemaPath == "" {
+			errs[i].SchemaPath = "#" + vc.location.path
+			errs[i].absSchemaPath = vc.location.abs
+		}
+	}
+}
+
+// resolveLocation resolves a reference against the absolute location base,
+// giving an absolute URI with a fragment, or "" if the reference isn't
+// absolute once resolved
+func resolveLocation(base, ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != "" {
+		b, err := url.Parse(base)
+		if err != nil {
+			return ""
+		}
+		u = b.ResolveReference(u)
+	}
+	if !u.IsAbs() {
+		return ""
+	}
+	abs := u.String()
+	if u.Fragment == "" {
+		abs = strings.TrimSuffix(abs, "#") + "#"
+	}
+	return abs
+}
+
+// reportProgress calls the ProgressFunc for the elements of a top-level
+// array, if one is set. Counts that aren't past the last one reported are
+// skipped, as is the last element, which finishProgress reports
+func (vc *ValidationContext) reportProgress(propPath string, done, total int) {
+	if vc.Options.ProgressFunc == nil || propPath != "/" || done <= vc.progress || done == total {
+		return
+	}
+	interval := vc.Options.ProgressInterval
+	if interval <= 0 {
+		interval = 1000
+	}
+	if done%interval == 0 {
+		vc.progress = done
+		vc.Options.ProgressFunc(done, total)
+	}
+}
+
+// finishProgress makes the last ProgressFunc call once a top-level array
+// has been validated
+func (vc *ValidationContext) finishProgress(doc interface{}) {
+	if arr, ok := doc.([]interface{}); ok && vc.Options.ProgressFunc != nil && !vc.timedOut {
+		vc.Options.ProgressFunc(len(arr), len(arr))
+	}
+}
+
+// ContextValidator is an optional interface for validators that validate
+// subschemas, or otherwise need the state of the validation pass they're
+// a part of. Validators that aren't ContextValidators are called with
+// Validate
+type ContextValidator interface {
+	Validator
+	// ValidateContext works like Validate, but is passed the context of the
+	// validation pass
+	ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError)
+}
+
+// validateWith runs a validator, passing along the validation context if
+// the validator accepts one
+func validateWith(vc *ValidationContext, v Validator, propPath string, data interface{}, errs *[]ValError) {
+	if cv, ok := v.(ContextValidator); ok {
+		cv.ValidateContext(vc, propPath, data, errs)
+		return
+	}
+	v.Validate(propPath, data, errs)
+}
+
 // BaseValidator is a foundation for building a validator
 type BaseValidator struct {
 	path string
@@ -53,6 +399,22 @@ func RegisterValidator(propName string, maker ValMaker) {
 	DefaultValidators[propName] = maker
 }
 
+// KeywordFactory creates a validator from the raw JSON value of a
+// keyword, returning an error if the value is invalid
+type KeywordFactory func(raw json.RawMessage) (Validator, error)
+
+// keywordFactories holds registered custom keywords, by keyword name
+var keywordFactories = map[string]KeywordFactory{}
+
+// RegisterKeyword adds a custom keyword. Schemas parsed afterward that use
+// the keyword get the validator returned by factory, which runs during
+// validation alongside the standard keywords and is passed the validation
+// context if it's a ContextValidator. Registering a standard keyword
+// replaces it
+func RegisterKeyword(name string, factory KeywordFactory) {
+	keywordFactories[name] = factory
+}
+
 // DefaultValidators is a map of JSON keywords to Validators
 // to draw from when decoding schemas
 var DefaultValidators = map[string]ValMaker{
@@ -102,6 +464,14 @@ var DefaultValidators = map[string]ValMaker{
 	"then": NewThen,
 	"else": NewElse,
 
+	// meta-data keywords
+	"deprecated": NewDeprecated,
+
+	// content keywords
+	"contentEncoding":  NewContentEncoding,
+	"contentMediaType": NewContentMediaType,
+	"contentSchema":    NewContentSchema,
+
 	//optional formats
 	"format": NewFormat,
 }
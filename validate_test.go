@@ -1,8 +1,11 @@
 package jsonschema
 
 import (
+	"encoding/json"
 	"github.com/json-iterator/go"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -57,3 +60,171 @@ func TestRegisterValidator(t *testing.T) {
 		t.Errorf("expected %s to be added as a default validator", "foo")
 	}
 }
+
+func TestProgressFunc(t *testing.T) {
+	rs := Must(`{ "items": { "type": "integer" } }`)
+
+	arr := make([]interface{}, 2500)
+	for i := range arr {
+		arr[i] = i
+	}
+	data, err := jsoniter.Marshal(arr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := [][2]int{}
+	opts := ValidateOptions{
+		ProgressFunc: func(done, total int) {
+			calls = append(calls, [2]int{done, total})
+		},
+	}
+	errs, err := rs.ValidateBytesWithOptions(data, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+
+	expect := [][2]int{{1000, 2500}, {2000, 2500}, {2500, 2500}}
+	if fmt.Sprint(calls) != fmt.Sprint(expect) {
+		t.Errorf("progress calls mismatch. expected: %v, got: %v", expect, calls)
+	}
+
+	calls = calls[:0]
+	opts.ProgressInterval = 2
+	if _, err := rs.ValidateBytesWithOptions([]byte(`[[1, 2, 3]]`), opts); err != nil {
+		t.Fatal(err)
+	}
+	if expect := [][2]int{{1, 1}}; fmt.Sprint(calls) != fmt.Sprint(expect) {
+		t.Errorf("nested arrays shouldn't report progress. expected: %v, got: %v", expect, calls)
+	}
+
+	// every keyword walking the elements moves progress along, and
+	// branches of anyOf carry on from the count already reported
+	cases := []struct {
+		schema string
+		expect [][2]int
+	}{
+		{`{ "items": [{}, {}, {}, {}, {}] }`, [][2]int{{2, 5}, {4, 5}, {5, 5}}},
+		{`{ "items": [], "additionalItems": { "type": "integer" } }`, [][2]int{{2, 5}, {4, 5}, {5, 5}}},
+		{`{ "uniqueItems": true }`, [][2]int{{2, 5}, {4, 5}, {5, 5}}},
+		{`{ "contains": { "const": 4 } }`, [][2]int{{2, 5}, {4, 5}, {5, 5}}},
+		{`{ "anyOf": [{ "items": { "type": "string" } }, { "items": { "type": "integer" } }] }`, [][2]int{{2, 5}, {4, 5}, {5, 5}}},
+		{`{ "type": "array" }`, [][2]int{{5, 5}}},
+	}
+	for i, c := range cases {
+		calls = calls[:0]
+		if _, err := Must(c.schema).ValidateBytesWithOptions([]byte(`[0, 1, 2, 3, 4]`), opts); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(calls) != fmt.Sprint(c.expect) {
+			t.Errorf("case %d: progress calls mismatch. expected: %v, got: %v", i, c.expect, calls)
+		}
+	}
+}
+
+// currencyCodes is a custom keyword validating ISO currency codes
+type currencyCodes []string
+
+func (c currencyCodes) Validate(propPath string, data interface{}, errs *[]ValError) {
+	if str, ok := data.(string); ok {
+		for _, code := range c {
+			if str == code {
+				return
+			}
+		}
+		AddError(errs, propPath, data, "unsupported currency")
+	}
+}
+
+func TestRegisterKeyword(t *testing.T) {
+	RegisterKeyword("isCurrency", func(raw json.RawMessage) (Validator, error) {
+		var codes []string
+		if err := json.Unmarshal(raw, &codes); err != nil {
+			return nil, err
+		}
+		if len(codes) == 0 {
+			return nil, fmt.Errorf("at least one currency is required")
+		}
+		return currencyCodes(codes), nil
+	})
+	defer delete(keywordFactories, "isCurrency")
+
+	rs := Must(`{ "properties": { "price": { "type": "string", "isCurrency": ["EUR", "USD"] } } }`)
+	if _, ok := (*rs.Validators["properties"].(*Properties))["price"].Validators["isCurrency"].(currencyCodes); !ok {
+		t.Errorf("expected isCurrency to be parsed by its factory")
+	}
+
+	errs, err := rs.ValidateBytes([]byte(`{ "price": "GBP" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Error() != `/price: "GBP" unsupported currency` || errs[0].Keyword != "isCurrency" {
+		t.Errorf("expected one isCurrency error, got: %v", errs)
+	}
+	if errs, _ := rs.ValidateBytes([]byte(`{ "price": "EUR" }`)); len(errs) != 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+
+	expect := "error unmarshaling isCurrency from json: at least one currency is required"
+	if err := new(RootSchema).UnmarshalJSON([]byte(`{ "isCurrency": [] }`)); err == nil || !strings.Contains(err.Error(), expect) {
+		t.Errorf("expected error %q, got: %v", expect, err)
+	}
+}
+
+// contextRecorder records the validation context it's called with
+type contextRecorder struct {
+	seen *[]ValidationContext
+}
+
+func (c contextRecorder) Validate(propPath string, data interface{}, errs *[]ValError) {}
+
+func (c contextRecorder) ValidateContext(vc *ValidationContext, propPath string, data interface{}, errs *[]ValError) {
+	*c.seen = append(*c.seen, *vc)
+}
+
+func TestValidationContextParent(t *testing.T) {
+	seen := []ValidationContext{}
+	RegisterKeyword("recordContext", func(raw json.RawMessage) (Validator, error) {
+		return contextRecorder{seen: &seen}, nil
+	})
+	defer delete(keywordFactories, "recordContext")
+
+	rs := Must(`{
+		"recordContext": true,
+		"properties": {
+			"a": { "items": { "recordContext": true } }
+		}
+	}`)
+	errs, err := rs.ValidateBytes([]byte(`{ "a": [1] }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("expected 2 contexts, got: %d", len(seen))
+	}
+	root := map[string]interface{}{"a": []interface{}{float64(1)}}
+	for _, vc := range seen {
+		if !reflect.DeepEqual(vc.Root, root) {
+			t.Errorf("expected root %v, got: %v", root, vc.Root)
+		}
+	}
+	// order of keywords within a schema isn't fixed, find the element
+	for _, vc := range seen {
+		if vc.Parent == nil {
+			if vc.ParentPath != "" {
+				t.Errorf("expected empty parent path at the root, got: %s", vc.ParentPath)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(vc.Parent, []interface{}{float64(1)}) || vc.ParentPath != "/a" {
+			t.Errorf("expected parent [1] at /a, got: %v at %s", vc.Parent, vc.ParentPath)
+		}
+	}
+}
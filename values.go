@@ -0,0 +1,57 @@
+package jsonschema
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ValidateValues performs schema validation against query or form values.
+// Values are validated as an object with a property for each key: keys
+// with a single value become a scalar unless the property schema declares
+// an array type, keys with several values become an array. Strings are
+// coerced to the types declared by the schema, as ValidateOptions.Coerce
+// does, so "?age=42" satisfies { "type": "integer" }
+func (rs *RootSchema) ValidateValues(v url.Values) ([]ValError, error) {
+	if t := rs.TopLevelType(); t != "object" && t != "unknown" {
+		return nil, fmt.Errorf("values can't be validated against a schema of type %s", t)
+	}
+
+	doc := make(map[string]interface{}, len(v))
+	for key, vals := range v {
+		if len(vals) == 1 && !valuesArrayProperty(&rs.Schema, key) {
+			doc[key] = vals[0]
+			continue
+		}
+		arr := make([]interface{}, len(vals))
+		for i, val := range vals {
+			arr[i] = val
+		}
+		doc[key] = arr
+	}
+
+	coerce(&rs.Schema, doc)
+	errs := []ValError{}
+	vc := newValidationContext(nil)
+	vc.Root = doc
+	rs.ValidateContext(vc, "/", doc, &errs)
+	return errs, nil
+}
+
+// valuesArrayProperty reports whether the schema for property key of an
+// object declares an array type
+func valuesArrayProperty(sch *Schema, key string) bool {
+	for _, s := range expandSchema(sch, nil, map[*Schema]bool{}) {
+		props, ok := s.Validators["properties"].(*Properties)
+		if !ok || (*props)[key] == nil {
+			continue
+		}
+		for _, ps := range expandSchema((*props)[key], nil, map[*Schema]bool{}) {
+			for _, t := range ps.Types() {
+				if t == "array" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package jsonschema
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestValidateValues(t *testing.T) {
+	rs := Must(`{
+		"type": "object",
+		"properties": {
+			"q": { "type": "string", "minLength": 1 },
+			"page": { "type": "integer", "minimum": 1 },
+			"exact": { "type": "boolean" },
+			"tag": { "type": "array", "items": { "type": "string" } },
+			"id": { "type": "array", "items": { "type": "integer" } }
+		},
+		"required": ["q"]
+	}`)
+
+	cases := []struct {
+		query  string
+		expect []string
+	}{
+		{"q=shoes&page=2&exact=true&tag=a&tag=b&id=1", nil},
+		{"q=shoes&tag=a", nil},
+		{"q=shoes&page=0", []string{"/page: 0 must be >= 1"}},
+		{"q=shoes&page=two", []string{`/page: "two" type should be integer`}},
+		{"q=shoes&id=1&id=x", []string{`/id/1: "x" type should be integer`}},
+		{"q=a&q=b", []string{`/q: ["a","b"] type should be string`}},
+		{"page=1", []string{`/: {"page":1} "q" value is required`}},
+	}
+
+	for i, c := range cases {
+		v, err := url.ParseQuery(c.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errs, err := rs.ValidateValues(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != len(c.expect) {
+			t.Errorf("case %d: expected %v, got: %v", i, c.expect, errs)
+			continue
+		}
+		for j, e := range errs {
+			if e.Error() != c.expect[j] {
+				t.Errorf("case %d: expected %q, got: %q", i, c.expect[j], e.Error())
+			}
+		}
+	}
+
+	if _, err := Must(`{ "type": "string" }`).ValidateValues(url.Values{}); err == nil {
+		t.Errorf("expected an error validating values against a string schema")
+	}
+}